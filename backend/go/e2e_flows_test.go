@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+func TestE2ESignUpAndLogin(t *testing.T) {
+	e := newE2E(t)
+
+	e.golden("sign_up", e.signUp("alice"))
+
+	e.golden("login_wrong_password", e.do("", "loginUser", map[string]interface{}{
+		"email":    "alice@example.com",
+		"password": "not my password",
+	}))
+
+	e.golden("login", e.do("", "loginUser", map[string]interface{}{
+		"email":    "alice@example.com",
+		"password": "correct horse battery staple",
+	}))
+
+	e.golden("verify_email", e.verify("alice"))
+}
+
+func TestE2EInvalidTokenIsRejected(t *testing.T) {
+	e := newE2E(t)
+
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/query", strings.NewReader(`{"query":"{ users { id } }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer not-a-token")
+
+	res, err := e.server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestE2EProbes(t *testing.T) {
+	e := newE2E(t)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		res, err := e.server.Client().Get(e.server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body probeResult
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if res.StatusCode != http.StatusOK || body.Status != "ok" {
+			t.Errorf("%s = %d %+v, want 200 ok", path, res.StatusCode, body)
+		}
+	}
+}
+
+func TestE2EMetrics(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	e.createProject(e.login("alice"), "Compiler")
+
+	res, err := e.server.Client().Get(e.server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, series := range []string{
+		`projectivity_http_request_duration_seconds_count{method="POST",route="/query",status="200"}`,
+		`projectivity_graphql_operation_duration_seconds_count{operation="createProject"}`,
+		`projectivity_graphql_resolver_duration_seconds_count{field="createProject",object="Mutation"}`,
+		`projectivity_projects_created_total`,
+		`go_sql_max_open_connections{db_name="projectivity"}`,
+	} {
+		if !strings.Contains(string(body), series) {
+			t.Errorf("/metrics is missing %s", series)
+		}
+	}
+}
+
+func TestE2ECreateProject(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	token := e.login("alice")
+
+	e.golden("create_unauthenticated", e.createProject("", "Compiler"))
+	e.golden("create", e.createProject(token, "Compiler"))
+
+	e.golden("get", e.do("", "project", map[string]interface{}{"id": e.idOf("compiler")}))
+	e.golden("get_missing", e.do("", "project", map[string]interface{}{"id": missingID}))
+}
+
+func TestE2EJoinRequestApproveAndDeny(t *testing.T) {
+	e := newE2E(t)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		e.signUp(name)
+	}
+	alice, bob, carol := e.login("alice"), e.login("bob"), e.login("carol")
+	e.createProject(alice, "Compiler")
+	project := map[string]interface{}{"projectId": e.idOf("compiler")}
+
+	// Only verified users may ask to join
+	e.golden("request_unverified", e.do(carol, "requestToJoinProject", project))
+	e.verify("bob")
+	e.verify("carol")
+
+	resp := e.do(bob, "requestToJoinProject", project)
+	e.alias(resp.str(t, "data.requestToJoinProject.id"), "bob-request")
+	e.golden("request_bob", resp)
+
+	resp = e.do(carol, "requestToJoinProject", project)
+	e.alias(resp.str(t, "data.requestToJoinProject.id"), "carol-request")
+	e.golden("request_carol", resp)
+
+	e.golden("request_duplicate", e.do(carol, "requestToJoinProject", project))
+
+	// Applicants cannot decide on requests themselves
+	e.golden("approve_by_applicant", e.do(bob, "approveJoinRequest", map[string]interface{}{"requestId": e.idOf("bob-request")}))
+
+	e.golden("approve", e.do(alice, "approveJoinRequest", map[string]interface{}{"requestId": e.idOf("bob-request")}))
+	e.golden("deny", e.do(alice, "denyJoinRequest", map[string]interface{}{"requestId": e.idOf("carol-request")}))
+
+	e.golden("list_by_owner", e.do(alice, "joinRequests", project))
+	e.golden("list_by_member", e.do(bob, "joinRequests", project))
+}
+
+func TestE2ETaskCRUD(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	e.signUp("mallory")
+	alice, mallory := e.login("alice"), e.login("mallory")
+	e.createProject(alice, "Compiler")
+
+	input := map[string]interface{}{
+		"input": map[string]interface{}{
+			"title":       "Write the lexer",
+			"description": "Tokenize the source",
+			"priority":    "HIGH",
+			"projectId":   e.idOf("compiler"),
+		},
+	}
+
+	e.golden("create_unauthenticated", e.do("", "createTask", input))
+	e.golden("create_by_outsider", e.do(mallory, "createTask", input))
+
+	resp := e.do(alice, "createTask", input)
+	e.alias(resp.str(t, "data.createTask.id"), "lexer")
+	e.golden("create", resp)
+
+	update := map[string]interface{}{
+		"id": e.idOf("lexer"),
+		"input": map[string]interface{}{
+			"status":     "IN_PROGRESS",
+			"assigneeId": e.idOf("alice"),
+		},
+	}
+	e.golden("update_by_outsider", e.do(mallory, "updateTask", update))
+	e.golden("update", e.do(alice, "updateTask", update))
+
+	task := map[string]interface{}{"id": e.idOf("lexer")}
+	e.golden("get", e.do("", "task", task))
+
+	e.golden("delete_by_outsider", e.do(mallory, "deleteTask", task))
+	e.golden("delete", e.do(alice, "deleteTask", task))
+	e.golden("get_deleted", e.do("", "task", task))
+}
+
+func TestE2EProjectsConnection(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	token := e.login("alice")
+	for _, title := range []string{"Compiler", "Debugger", "Linker"} {
+		e.createProject(token, title)
+	}
+
+	// Page through two at a time, adding a project after the first page;
+	// the keyset cursor must neither skip nor repeat a project
+	seen := map[string]bool{}
+	var after interface{}
+	for page := 0; ; page++ {
+		resp := e.do("", "projectsConnection", map[string]interface{}{"first": 2, "after": after})
+		conn := resp["data"].(map[string]interface{})["projectsConnection"].(map[string]interface{})
+		if page == 0 && conn["totalCount"] != json.Number("3") {
+			t.Errorf("totalCount = %v, want 3", conn["totalCount"])
+		}
+
+		for _, edge := range conn["edges"].([]interface{}) {
+			title := edge.(map[string]interface{})["node"].(map[string]interface{})["title"].(string)
+			if seen[title] {
+				t.Errorf("%s returned twice", title)
+			}
+			seen[title] = true
+		}
+
+		info := conn["pageInfo"].(map[string]interface{})
+		if info["hasPreviousPage"] != (page > 0) {
+			t.Errorf("page %d: hasPreviousPage = %v", page, info["hasPreviousPage"])
+		}
+		if page == 0 {
+			e.createProject(token, "Assembler")
+		}
+		if info["hasNextPage"] != true {
+			break
+		}
+		after = info["endCursor"]
+	}
+
+	for _, title := range []string{"Compiler", "Debugger", "Linker"} {
+		if !seen[title] {
+			t.Errorf("%s was skipped", title)
+		}
+	}
+}
+
+func TestE2EProjectFacets(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	token := e.login("alice")
+	for _, title := range []string{"Compiler", "Debugger", "Linker"} {
+		e.createProject(token, title)
+	}
+
+	// Every project uses go and llvm, so each technology counts all three
+	// once despite the join on the unnested technologies
+	resp := e.do("", "projectFacets", map[string]interface{}{"filter": map[string]interface{}{"categories": []string{"Education"}}})
+	facets := resp["data"].(map[string]interface{})["projectFacets"].(map[string]interface{})
+	if facets["total"] != json.Number("3") {
+		t.Errorf("total = %v, want 3", facets["total"])
+	}
+	for field, want := range map[string]string{
+		"categories":      "Education=3",
+		"technologies":    "go=3,llvm=3",
+		"statuses":        "PLANNING=3",
+		"timeCommitments": "5 hours/week=3",
+	} {
+		var got []string
+		for _, f := range facets[field].([]interface{}) {
+			facet := f.(map[string]interface{})
+			got = append(got, fmt.Sprintf("%s=%s", facet["value"], facet["count"]))
+		}
+		if strings.Join(got, ",") != want {
+			t.Errorf("%s = %v, want %s", field, got, want)
+		}
+	}
+
+	resp = e.do("", "projectFacets", map[string]interface{}{"query": "linker"})
+	if total := resp["data"].(map[string]interface{})["projectFacets"].(map[string]interface{})["total"]; total != json.Number("1") {
+		t.Errorf("total for query linker = %v, want 1", total)
+	}
+}
+
+func TestE2ESearchProjects(t *testing.T) {
+	e := newE2E(t)
+	e.signUp("alice")
+	token := e.login("alice")
+	for _, title := range []string{"Compiler", "Debugger", "Linker"} {
+		e.createProject(token, title)
+	}
+
+	search := func(query string) []interface{} {
+		t.Helper()
+		resp := e.do("", "searchProjects", map[string]interface{}{"query": query})
+		if errs, ok := resp["errors"]; ok {
+			t.Fatalf("search %q failed: %v", query, errs)
+		}
+		return resp["data"].(map[string]interface{})["searchProjects"].([]interface{})
+	}
+	hitTitle := func(hit interface{}) string {
+		return hit.(map[string]interface{})["project"].(map[string]interface{})["title"].(string)
+	}
+
+	// A prefix matches through the stored search document
+	hits := search("debug")
+	if len(hits) != 1 || hitTitle(hits[0]) != "Debugger" {
+		t.Fatalf("search debug = %v, want Debugger", hits)
+	}
+	if got := hits[0].(map[string]interface{})["titleHighlight"]; got != "<mark>Debugger</mark>" {
+		t.Errorf("titleHighlight = %v", got)
+	}
+	if got := hits[0].(map[string]interface{})["snippet"].(string); !strings.Contains(got, "<mark>debugger</mark>") {
+		t.Errorf("snippet = %q", got)
+	}
+
+	// A misspelling falls back to trigram similarity
+	if hits := search("compilr"); len(hits) != 1 || hitTitle(hits[0]) != "Compiler" {
+		t.Errorf("search compilr = %v, want Compiler", hits)
+	}
+
+	// Technologies are searched too
+	if hits := search("llvm"); len(hits) != 3 {
+		t.Errorf("search llvm returned %d hits, want 3", len(hits))
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/config"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/database"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/migrate"
+)
+
+// testDatabaseEnv names the variable holding the DSN of a local Postgres
+// the end-to-end tests may create throwaway schemas in
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/e2e/golden")
+
+var (
+	uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	linkPattern = regexp.MustCompile(`https?://\S+`)
+)
+
+// volatileFields are response fields whose values change from run to run.
+// Golden files only record that they were present.
+var volatileFields = map[string]bool{
+	"token":            true,
+	"refreshToken":     true,
+	"expiresAt":        true,
+	"refreshExpiresAt": true,
+	"challengeToken":   true,
+	"createdAt":        true,
+	"updatedAt":        true,
+	"joinedAt":         true,
+	"lastActive":       true,
+}
+
+// e2e is one end-to-end test: the router from main.go served over HTTP,
+// backed by a freshly migrated Postgres schema
+type e2e struct {
+	t      *testing.T
+	server *httptest.Server
+	mailer *recordingMailer
+	// aliases gives the IDs a test has seen readable names in golden files
+	aliases map[string]string
+}
+
+// newE2E starts a server for t, skipping the test when no test database is
+// configured
+func newE2E(t *testing.T) *e2e {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set; skipping end-to-end test", testDatabaseEnv)
+	}
+
+	db := openTestSchema(t, dsn)
+	if err := auth.InitJWTKeys("", "", true); err != nil {
+		t.Fatalf("failed to initialize JWT keys: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.AppBaseURL = "http://projectivity.test"
+	mailer := &recordingMailer{}
+
+	router, err := newRouter(cfg, db, mailer)
+	if err != nil {
+		t.Fatalf("failed to build router: %v", err)
+	}
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &e2e{t: t, server: server, mailer: mailer, aliases: make(map[string]string)}
+}
+
+// openTestSchema creates an empty schema, migrates it and connects to it.
+// The schema is dropped when the test finishes.
+func openTestSchema(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("e2e_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	// Open it the way main does so the pool is configured the same
+	db, err := database.Initialize(database.Config{
+		URL:          withSearchPath(dsn, schema+",public"),
+		MaxOpenConns: 5,
+		MaxIdleConns: 5,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("failed to migrate schema %s: %v", schema, err)
+	}
+	return db
+}
+
+// withSearchPath adds a search_path run-time parameter to a URL or
+// key=value DSN
+func withSearchPath(dsn, searchPath string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", searchPath)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + searchPath
+}
+
+// alias names an ID in golden files, which then show <name> instead of it
+func (e *e2e) alias(id, name string) {
+	e.t.Helper()
+	if !uuidPattern.MatchString(id) {
+		e.t.Fatalf("cannot alias %q as %s: not a UUID", id, name)
+	}
+	e.aliases[id] = name
+}
+
+// idOf returns the ID that was given an alias
+func (e *e2e) idOf(name string) string {
+	e.t.Helper()
+	for id, alias := range e.aliases {
+		if alias == name {
+			return id
+		}
+	}
+	e.t.Fatalf("no ID is aliased %s", name)
+	return ""
+}
+
+// response is a decoded GraphQL response
+type response map[string]interface{}
+
+// str returns the string at a dotted path such as "data.createUser.id"
+func (r response) str(t *testing.T, path string) string {
+	t.Helper()
+
+	var value interface{} = map[string]interface{}(r)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			t.Fatalf("no %s in response %v", path, r)
+		}
+		value = m[key]
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		t.Fatalf("%s is %v, not a string, in response %v", path, value, r)
+	}
+	return s
+}
+
+// do runs the document testdata/e2e/<document>.graphql, authenticated with
+// token unless it is empty
+func (e *e2e) do(token, document string, variables map[string]interface{}) response {
+	e.t.Helper()
+
+	query, err := os.ReadFile(filepath.Join("testdata", "e2e", document+".graphql"))
+	if err != nil {
+		e.t.Fatalf("failed to read document: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"query": string(query), "variables": variables})
+	if err != nil {
+		e.t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/query", bytes.NewReader(body))
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := e.server.Client().Do(req)
+	if err != nil {
+		e.t.Fatalf("%s: request failed: %v", document, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		e.t.Fatalf("%s: status %d", document, res.StatusCode)
+	}
+
+	var resp response
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&resp); err != nil {
+		e.t.Fatalf("%s: failed to decode response: %v", document, err)
+	}
+	return resp
+}
+
+// golden compares a response with testdata/e2e/golden/<test>/<name>.json,
+// or rewrites the file when the tests run with -update
+func (e *e2e) golden(name string, resp response) {
+	e.t.Helper()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e.normalize("", map[string]interface{}(resp))); err != nil {
+		e.t.Fatal(err)
+	}
+	got := buf.Bytes()
+
+	path := filepath.Join("testdata", "e2e", "golden", e.t.Name(), name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			e.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			e.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		e.t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		e.t.Errorf("%s: response does not match %s\ngot:\n%s\nwant:\n%s", name, path, got, want)
+	}
+}
+
+// normalize replaces the parts of a response that differ between runs:
+// IDs become their alias, volatile fields a placeholder. Error locations
+// are dropped since they only reflect how the document is formatted.
+func (e *e2e) normalize(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		delete(v, "locations")
+		for k, field := range v {
+			v[k] = e.normalize(k, field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = e.normalize(key, item)
+		}
+		return v
+	case string:
+		if volatileFields[key] && v != "" {
+			return "<" + key + ">"
+		}
+		return uuidPattern.ReplaceAllStringFunc(v, func(id string) string {
+			if name, ok := e.aliases[id]; ok {
+				return "<" + name + ">"
+			}
+			return "<uuid>"
+		})
+	}
+	return value
+}
+
+// signUp creates a user named username with the address username@example.com
+func (e *e2e) signUp(username string) response {
+	e.t.Helper()
+
+	resp := e.do("", "createUser", map[string]interface{}{
+		"input": map[string]interface{}{
+			"username":        username,
+			"email":           username + "@example.com",
+			"password":        "correct horse battery staple",
+			"firstName":       strings.ToUpper(username[:1]) + username[1:],
+			"lastName":        "Tester",
+			"skills":          []string{"go", "sql"},
+			"yearsExperience": 3,
+		},
+	})
+	e.alias(resp.str(e.t, "data.createUser.id"), username)
+	return resp
+}
+
+// login signs in a user created by signUp and returns their access token
+func (e *e2e) login(username string) string {
+	e.t.Helper()
+
+	resp := e.do("", "loginUser", map[string]interface{}{
+		"email":    username + "@example.com",
+		"password": "correct horse battery staple",
+	})
+	return resp.str(e.t, "data.loginUser.token")
+}
+
+// verify follows the link in the last verification email sent to a user
+func (e *e2e) verify(username string) response {
+	e.t.Helper()
+	return e.do("", "verifyEmail", map[string]interface{}{
+		"token": e.mailer.verificationToken(e.t, username+"@example.com"),
+	})
+}
+
+// createProject creates a project owned by the holder of token
+func (e *e2e) createProject(token, title string) response {
+	e.t.Helper()
+
+	resp := e.do(token, "createProject", map[string]interface{}{
+		"input": map[string]interface{}{
+			"title":              title,
+			"description":        "A toy " + strings.ToLower(title),
+			"category":           "Education",
+			"technologies":       []string{"go", "llvm"},
+			"openPositions":      2,
+			"timeCommitment":     "5 hours/week",
+			"learningObjectives": []string{"parsing"},
+		},
+	})
+	if _, ok := resp["errors"]; !ok {
+		e.alias(resp.str(e.t, "data.createProject.id"), strings.ToLower(title))
+	}
+	return resp
+}
+
+// recordingMailer keeps sent messages so tests can follow emailed links
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// verificationToken returns the token from the link in the last message
+// sent to an address
+func (m *recordingMailer) verificationToken(t *testing.T, to string) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To != to {
+			continue
+		}
+		link, err := url.Parse(linkPattern.FindString(m.messages[i].Body))
+		if err != nil {
+			t.Fatalf("bad link in email to %s: %v", to, err)
+		}
+		return link.Query().Get("token")
+	}
+	t.Fatalf("no email was sent to %s", to)
+	return ""
+}
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+)
+
+// defaultAuditLogLimit is used when the auditLog query sets no limit
+const defaultAuditLogLimit = 50
+
+// parseAuditTime parses an optional RFC 3339 timestamp from the auditLog query
+func parseAuditTime(name string, value *string) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time: %w", name, err)
+	}
+	return t, nil
+}
+
+// newAuditEvent converts a stored event. The actor only carries its ID and is
+// loaded by the field resolver; the client IP address is left out unless the
+// viewer is an admin.
+func newAuditEvent(event *audit.StoredEvent, admin bool) *model.AuditEvent {
+	result := &model.AuditEvent{
+		ID:         event.ID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		CreatedAt:  event.CreatedAt.Format(time.RFC3339),
+	}
+	if event.ActorID != "" {
+		result.Actor = &model.User{ID: event.ActorID}
+	}
+	if event.ProjectID != "" {
+		result.ProjectID = &event.ProjectID
+	}
+	if admin && event.IPAddress != "" {
+		result.IPAddress = &event.IPAddress
+	}
+	if event.Before != nil {
+		before := string(event.Before)
+		result.Before = &before
+	}
+	if event.After != nil {
+		after := string(event.After)
+		result.After = &after
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+)
+
+func TestNewAuditEventShowsIPAddressToAdminsOnly(t *testing.T) {
+	event := &audit.StoredEvent{
+		ID:         "1",
+		Action:     audit.ActionProjectUpdate,
+		ActorID:    "actor",
+		TargetType: audit.TargetProject,
+		TargetID:   "project",
+		ProjectID:  "project",
+		IPAddress:  "203.0.113.7",
+		CreatedAt:  time.Now(),
+	}
+
+	if got := newAuditEvent(event, false); got.IPAddress != nil {
+		t.Errorf("owner sees IP address %q", *got.IPAddress)
+	}
+	got := newAuditEvent(event, true)
+	if got.IPAddress == nil || *got.IPAddress != event.IPAddress {
+		t.Errorf("admin sees IP address %v, want %s", got.IPAddress, event.IPAddress)
+	}
+	if got.Actor == nil || got.Actor.ID != event.ActorID {
+		t.Errorf("actor = %+v, want ID %s for the loader", got.Actor, event.ActorID)
+	}
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+)
+
+func newAuthPayload(tokens *auth.TokenPair, user *model.User) *model.AuthPayload {
+	return &model.AuthPayload{
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt.Format(time.RFC3339),
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt.Format(time.RFC3339),
+		User:             user,
+	}
+}
+
+func newTwoFactorChallenge(result *services.LoginResult) *model.TwoFactorChallenge {
+	return &model.TwoFactorChallenge{
+		ChallengeToken: result.ChallengeToken,
+		ExpiresAt:      result.ChallengeExpiresAt.Format(time.RFC3339),
+	}
+}
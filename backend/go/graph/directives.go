@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/policy"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+)
+
+// NewDirectiveRoot returns the implementations of the schema directives,
+// which look up roles and users in store
+func NewDirectiveRoot(store repository.Store) DirectiveRoot {
+	d := directives{store: store}
+	return DirectiveRoot{
+		Admin:          d.admin,
+		Auth:           authDirective,
+		HasProjectRole: d.hasProjectRole,
+		IsSelf:         isSelfDirective,
+		Verified:       d.verified,
+	}
+}
+
+// directives holds the store used by the directives that need one
+type directives struct {
+	store repository.Store
+}
+
+func authDirective(ctx context.Context, obj interface{}, next graphql.Resolver, scope *model.TokenScope, allowAnonymous *bool) (interface{}, error) {
+	if _, err := auth.GetUserIDFromContext(ctx); err != nil {
+		if allowAnonymous != nil && *allowAnonymous {
+			return next(ctx)
+		}
+		return nil, policyError(policy.ErrUnauthenticated)
+	}
+
+	required := ""
+	if scope != nil {
+		required = services.TokenScopeValue(*scope)
+	}
+	if err := policy.RequireTokenScope(ctx, required); err != nil {
+		return nil, policyError(err)
+	}
+	return next(ctx)
+}
+
+func (d directives) admin(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if err := policy.RequireAdmin(ctx, d.store, userID); err != nil {
+		return nil, policyError(err)
+	}
+	return next(ctx)
+}
+
+func (d directives) hasProjectRole(ctx context.Context, obj interface{}, next graphql.Resolver, role model.ProjectRole, resource *model.ProjectResource, idArg *string) (interface{}, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+
+	id, err := argumentValue(ctx, *idArg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.RequireProjectRole(ctx, d.store, userID, policy.Resource(*resource), id, policy.Role(role))
+	if err != nil {
+		return nil, policyError(err)
+	}
+	return next(ctx)
+}
+
+func isSelfDirective(ctx context.Context, obj interface{}, next graphql.Resolver, idArg *string) (interface{}, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+
+	id, err := argumentValue(ctx, *idArg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := policy.RequireSelf(ctx, userID, id); err != nil {
+		return nil, policyError(err)
+	}
+	return next(ctx)
+}
+
+func (d directives) verified(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if err := policy.RequireVerifiedEmail(ctx, d.store, userID); err != nil {
+		return nil, policyError(err)
+	}
+	return next(ctx)
+}
+
+// argumentValue reads a string argument of the current field. The path may
+// be dotted to reach into an input object, e.g. "input.projectId".
+func argumentValue(ctx context.Context, path string) (string, error) {
+	fc := graphql.GetFieldContext(ctx)
+	var value interface{} = fc.Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables)
+
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("argument %q not found on field %s", path, fc.Field.Name)
+		}
+		value = m[key]
+	}
+
+	var id string
+	switch v := value.(type) {
+	case string:
+		id = v
+	case json.Number:
+		id = v.String()
+	}
+	if id == "" {
+		return "", fmt.Errorf("argument %q not found on field %s", path, fc.Field.Name)
+	}
+	return id, nil
+}
@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func TestAuthDirectiveChecksReadScopes(t *testing.T) {
+	scope := model.TokenScopeTasksRead
+	anonymous := true
+	next := func(ctx context.Context) (interface{}, error) { return "ok", nil }
+
+	signedIn := auth.SetUserID(context.Background(), "user")
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		allowed bool
+	}{
+		{"anonymous", context.Background(), true},
+		{"login session", signedIn, true},
+		{"token with scope", auth.SetTokenScopes(signedIn, []string{auth.ScopeTasksRead}), true},
+		{"token without scope", auth.SetTokenScopes(signedIn, []string{auth.ScopeProjectsRead}), false},
+	}
+	for _, tt := range tests {
+		_, err := authDirective(tt.ctx, nil, next, &scope, &anonymous)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s: err = %v, want allowed %v", tt.name, err, tt.allowed)
+		}
+	}
+
+	if _, err := authDirective(context.Background(), nil, next, &scope, nil); err == nil {
+		t.Error("anonymous caller passed @auth without allowAnonymous")
+	}
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"errors"
+	"math"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/policy"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// policyError converts a policy failure into a GraphQL error carrying a
+// machine-readable code
+func policyError(err error) error {
+	code := ""
+	switch {
+	case errors.Is(err, policy.ErrUnauthenticated):
+		code = "UNAUTHENTICATED"
+	case errors.Is(err, policy.ErrForbidden):
+		code = "FORBIDDEN"
+	case errors.Is(err, policy.ErrEmailNotVerified):
+		code = "EMAIL_NOT_VERIFIED"
+	default:
+		return err
+	}
+
+	return &gqlerror.Error{
+		Message:    err.Error(),
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
+
+// loginLockedError tells clients how long to wait before retrying a login
+func loginLockedError(err *services.LoginLockedError) error {
+	code := "LOGIN_RATE_LIMITED"
+	if err.Locked {
+		code = "ACCOUNT_LOCKED"
+	}
+
+	return &gqlerror.Error{
+		Message: err.Error(),
+		Extensions: map[string]interface{}{
+			"code":              code,
+			"retryAfterSeconds": int(math.Ceil(err.RetryAfter.Seconds())),
+		},
+	}
+}
+
+// suspendedError tells a user with the correct credentials that their account
+// has been suspended
+func suspendedError(err error) error {
+	return &gqlerror.Error{
+		Message:    err.Error(),
+		Extensions: map[string]interface{}{"code": "ACCOUNT_SUSPENDED"},
+	}
+}
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+// projectFilter converts the filter arguments of a project listing. The
+// deprecated category, status and technology arguments are added to the
+// corresponding lists of the filter input.
+func projectFilter(input *model.ProjectFilterInput, category *string, status *model.ProjectStatus, technology *string) repository.ProjectFilter {
+	var filter repository.ProjectFilter
+	if input != nil {
+		filter = repository.ProjectFilter{
+			Categories:        input.Categories,
+			Statuses:          input.Statuses,
+			Technologies:      input.Technologies,
+			AllTechnologies:   input.TechnologyMatch != nil && *input.TechnologyMatch == model.TechnologyMatchAll,
+			MinOpenPositions:  input.MinOpenPositions,
+			MaxOpenPositions:  input.MaxOpenPositions,
+			TimeCommitment:    input.TimeCommitment,
+			CreatedAfter:      input.CreatedAfter,
+			CreatedBefore:     input.CreatedBefore,
+			OwnerID:           input.OwnerID,
+			LearningObjective: input.LearningObjective,
+		}
+	}
+
+	if category != nil {
+		filter.Categories = append(filter.Categories, *category)
+	}
+	if status != nil {
+		filter.Statuses = append(filter.Statuses, *status)
+	}
+	if technology != nil {
+		filter.Technologies = append(filter.Technologies, *technology)
+	}
+	return filter
+}
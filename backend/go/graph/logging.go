@@ -0,0 +1,36 @@
+package graph
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/logging"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// LogOperation puts the operation name in the context, so everything logged
+// while resolving it can be traced back to the operation. Anonymous
+// operations are named after their first root field.
+func LogOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	ctx = logging.WithOperation(ctx, operationName(graphql.GetOperationContext(ctx)))
+	slog.DebugContext(ctx, "Executing GraphQL operation")
+	return next(ctx)
+}
+
+// operationName returns the client's name for the operation, or the name of
+// its first root field for anonymous operations
+func operationName(oc *graphql.OperationContext) string {
+	if oc.Operation == nil {
+		return oc.OperationName
+	}
+	if oc.Operation.Name != "" {
+		return oc.Operation.Name
+	}
+	for _, selection := range oc.Operation.SelectionSet {
+		if field, ok := selection.(*ast.Field); ok {
+			return field.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Metrics is a gqlgen extension recording the latency and errors of every
+// operation and of every field backed by a resolver. Fields read straight
+// from a model are skipped, they cost nothing worth measuring.
+type Metrics struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Metrics{}
+
+func (Metrics) ExtensionName() string {
+	return "Metrics"
+}
+
+func (Metrics) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (Metrics) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	oc := graphql.GetOperationContext(ctx)
+	name := operationLabel(oc)
+	metrics.OperationDuration.WithLabelValues(name).Observe(time.Since(oc.Stats.OperationStart).Seconds())
+	if resp != nil && len(resp.Errors) > 0 {
+		metrics.OperationErrors.WithLabelValues(name).Inc()
+	}
+	return resp
+}
+
+// operationLabel names an operation for metrics by the root field it
+// selects. Operation names are chosen by clients, so labelling by them would
+// let anyone create new time series; root fields are fixed by the schema.
+// Operations selecting several root fields are labelled "multiple" and ones
+// that could not be parsed "invalid".
+func operationLabel(oc *graphql.OperationContext) string {
+	if oc.Operation == nil {
+		return "invalid"
+	}
+
+	label := ""
+	for _, selection := range oc.Operation.SelectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok || (label != "" && label != field.Name) {
+			return "multiple"
+		}
+		label = field.Name
+	}
+	if label == "" {
+		return "invalid"
+	}
+	return label
+}
+
+func (Metrics) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+	res, err := next(ctx)
+	metrics.ResolverDuration.WithLabelValues(fc.Object, fc.Field.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ResolverErrors.WithLabelValues(fc.Object, fc.Field.Name).Inc()
+	}
+	return res, err
+}
@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+func TestOperationLabelIgnoresClientNames(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`query RandomName12345 { projects { id } }`, "projects"},
+		{`{ a: project(id: "1") { id } b: project(id: "2") { id } }`, "project"},
+		{`mutation Anything { createProject(input: {}) { id } }`, "createProject"},
+		{`query Two { projects { id } users { id } }`, "multiple"},
+		{`query Spread { ...Root } fragment Root on Query { projects { id } }`, "multiple"},
+	}
+
+	for _, tc := range tests {
+		doc, err := parser.ParseQuery(&ast.Source{Input: tc.query})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		oc := &graphql.OperationContext{Operation: doc.Operations[0], OperationName: doc.Operations[0].Name}
+		if got := operationLabel(oc); got != tc.want {
+			t.Errorf("operationLabel(%s) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+
+	if got := operationLabel(&graphql.OperationContext{OperationName: "Unparsed"}); got != "invalid" {
+		t.Errorf("operationLabel without a parsed operation = %q, want invalid", got)
+	}
+}
@@ -8,9 +8,42 @@ import (
 	"strconv"
 )
 
+type LoginResult interface {
+	IsLoginResult()
+}
+
+type AuditEvent struct {
+	ID         string  `json:"id"`
+	Action     string  `json:"action"`
+	Actor      *User   `json:"actor,omitempty"`
+	TargetType string  `json:"targetType"`
+	TargetID   string  `json:"targetId"`
+	ProjectID  *string `json:"projectId,omitempty"`
+	IPAddress  *string `json:"ipAddress,omitempty"`
+	Before     *string `json:"before,omitempty"`
+	After      *string `json:"after,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
 type AuthPayload struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
+	Token            string `json:"token"`
+	ExpiresAt        string `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt string `json:"refreshExpiresAt"`
+	User             *User  `json:"user"`
+}
+
+func (AuthPayload) IsLoginResult() {}
+
+type CreatePersonalAccessTokenInput struct {
+	Name      string       `json:"name"`
+	Scopes    []TokenScope `json:"scopes"`
+	ExpiresAt *string      `json:"expiresAt,omitempty"`
+}
+
+type CreatePersonalAccessTokenPayload struct {
+	Token               string               `json:"token"`
+	PersonalAccessToken *PersonalAccessToken `json:"personalAccessToken"`
 }
 
 type CreateProjectInput struct {
@@ -63,6 +96,11 @@ type CreateUserInput struct {
 	ProjectPreferences []string `json:"projectPreferences,omitempty"`
 }
 
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
 type JoinRequest struct {
 	ID        string            `json:"id"`
 	User      *User             `json:"user"`
@@ -71,9 +109,49 @@ type JoinRequest struct {
 	CreatedAt string            `json:"createdAt"`
 }
 
+type JoinRequestConnection struct {
+	Edges      []*JoinRequestEdge `json:"edges"`
+	PageInfo   *PageInfo          `json:"pageInfo"`
+	TotalCount int                `json:"totalCount"`
+}
+
+type JoinRequestEdge struct {
+	Cursor string       `json:"cursor"`
+	Node   *JoinRequest `json:"node"`
+}
+
+type ModerationReport struct {
+	ID             string                 `json:"id"`
+	TargetType     ModerationTargetType   `json:"targetType"`
+	TargetID       string                 `json:"targetId"`
+	Reason         string                 `json:"reason"`
+	Status         ModerationReportStatus `json:"status"`
+	Reporter       *User                  `json:"reporter"`
+	ResolvedBy     *User                  `json:"resolvedBy,omitempty"`
+	ResolutionNote *string                `json:"resolutionNote,omitempty"`
+	CreatedAt      string                 `json:"createdAt"`
+	ResolvedAt     *string                `json:"resolvedAt,omitempty"`
+}
+
 type Mutation struct {
 }
 
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PersonalAccessToken struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	Scopes     []TokenScope `json:"scopes"`
+	ExpiresAt  *string      `json:"expiresAt,omitempty"`
+	LastUsedAt *string      `json:"lastUsedAt,omitempty"`
+	CreatedAt  string       `json:"createdAt"`
+}
+
 type Project struct {
 	ID                 string        `json:"id"`
 	Title              string        `json:"title"`
@@ -93,18 +171,65 @@ type Project struct {
 	UpdatedAt          string        `json:"updatedAt"`
 }
 
+type ProjectConnection struct {
+	Edges      []*ProjectEdge `json:"edges"`
+	PageInfo   *PageInfo      `json:"pageInfo"`
+	TotalCount int            `json:"totalCount"`
+}
+
+type ProjectEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Project `json:"node"`
+}
+
+type ProjectFacets struct {
+	Total           int           `json:"total"`
+	Categories      []*FacetCount `json:"categories"`
+	Technologies    []*FacetCount `json:"technologies"`
+	Statuses        []*FacetCount `json:"statuses"`
+	TimeCommitments []*FacetCount `json:"timeCommitments"`
+}
+
 type ProjectFilterInput struct {
-	Category         *string        `json:"category,omitempty"`
-	Status           *ProjectStatus `json:"status,omitempty"`
-	Technology       *string        `json:"technology,omitempty"`
-	MinOpenPositions *int           `json:"minOpenPositions,omitempty"`
-	MaxOpenPositions *int           `json:"maxOpenPositions,omitempty"`
-	TimeCommitment   *string        `json:"timeCommitment,omitempty"`
+	Categories        []string         `json:"categories,omitempty"`
+	Statuses          []ProjectStatus  `json:"statuses,omitempty"`
+	Technologies      []string         `json:"technologies,omitempty"`
+	TechnologyMatch   *TechnologyMatch `json:"technologyMatch,omitempty"`
+	MinOpenPositions  *int             `json:"minOpenPositions,omitempty"`
+	MaxOpenPositions  *int             `json:"maxOpenPositions,omitempty"`
+	TimeCommitment    *string          `json:"timeCommitment,omitempty"`
+	CreatedAfter      *string          `json:"createdAfter,omitempty"`
+	CreatedBefore     *string          `json:"createdBefore,omitempty"`
+	OwnerID           *string          `json:"ownerId,omitempty"`
+	LearningObjective *string          `json:"learningObjective,omitempty"`
 }
 
 type Query struct {
 }
 
+type ReportContentInput struct {
+	TargetType ModerationTargetType `json:"targetType"`
+	TargetID   string               `json:"targetId"`
+	Reason     string               `json:"reason"`
+}
+
+type SearchHit struct {
+	Project        *Project `json:"project"`
+	Score          float64  `json:"score"`
+	TitleHighlight string   `json:"titleHighlight"`
+	Snippet        string   `json:"snippet"`
+}
+
+type Session struct {
+	ID         string  `json:"id"`
+	Device     string  `json:"device"`
+	IPAddress  *string `json:"ipAddress,omitempty"`
+	UserAgent  *string `json:"userAgent,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+	LastUsedAt string  `json:"lastUsedAt"`
+	Current    bool    `json:"current"`
+}
+
 type Task struct {
 	ID          string       `json:"id"`
 	Title       string       `json:"title"`
@@ -118,6 +243,17 @@ type Task struct {
 	UpdatedAt   string       `json:"updatedAt"`
 }
 
+type TaskConnection struct {
+	Edges      []*TaskEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type TaskEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Task  `json:"node"`
+}
+
 type Team struct {
 	ID          string        `json:"id"`
 	Name        string        `json:"name"`
@@ -136,6 +272,18 @@ type TeamMember struct {
 	JoinedAt string `json:"joinedAt"`
 }
 
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresAt      string `json:"expiresAt"`
+}
+
+func (TwoFactorChallenge) IsLoginResult() {}
+
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
 type UpdateProjectInput struct {
 	Title              *string        `json:"title,omitempty"`
 	Description        *string        `json:"description,omitempty"`
@@ -200,6 +348,8 @@ type User struct {
 	LinkedInURL        *string    `json:"linkedInUrl,omitempty"`
 	PortfolioURL       *string    `json:"portfolioUrl,omitempty"`
 	EmailVerified      bool       `json:"emailVerified"`
+	Role               UserRole   `json:"role"`
+	SuspendedAt        *string    `json:"suspendedAt,omitempty"`
 	LastActive         string     `json:"lastActive"`
 	TimeZone           *string    `json:"timeZone,omitempty"`
 	AvailableHours     *string    `json:"availableHours,omitempty"`
@@ -213,6 +363,58 @@ type User struct {
 	UpdatedAt          string     `json:"updatedAt"`
 }
 
+type UserConnection struct {
+	Edges      []*UserEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type UserEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *User  `json:"node"`
+}
+
+type ConnectionOrder string
+
+const (
+	ConnectionOrderNewestFirst ConnectionOrder = "NEWEST_FIRST"
+	ConnectionOrderOldestFirst ConnectionOrder = "OLDEST_FIRST"
+)
+
+var AllConnectionOrder = []ConnectionOrder{
+	ConnectionOrderNewestFirst,
+	ConnectionOrderOldestFirst,
+}
+
+func (e ConnectionOrder) IsValid() bool {
+	switch e {
+	case ConnectionOrderNewestFirst, ConnectionOrderOldestFirst:
+		return true
+	}
+	return false
+}
+
+func (e ConnectionOrder) String() string {
+	return string(e)
+}
+
+func (e *ConnectionOrder) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ConnectionOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ConnectionOrder", str)
+	}
+	return nil
+}
+
+func (e ConnectionOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type JoinRequestStatus string
 
 const (
@@ -256,6 +458,219 @@ func (e JoinRequestStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type ModerationReportStatus string
+
+const (
+	ModerationReportStatusOpen      ModerationReportStatus = "OPEN"
+	ModerationReportStatusResolved  ModerationReportStatus = "RESOLVED"
+	ModerationReportStatusDismissed ModerationReportStatus = "DISMISSED"
+)
+
+var AllModerationReportStatus = []ModerationReportStatus{
+	ModerationReportStatusOpen,
+	ModerationReportStatusResolved,
+	ModerationReportStatusDismissed,
+}
+
+func (e ModerationReportStatus) IsValid() bool {
+	switch e {
+	case ModerationReportStatusOpen, ModerationReportStatusResolved, ModerationReportStatusDismissed:
+		return true
+	}
+	return false
+}
+
+func (e ModerationReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ModerationReportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationReportStatus", str)
+	}
+	return nil
+}
+
+func (e ModerationReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ModerationTargetType string
+
+const (
+	ModerationTargetTypeUser    ModerationTargetType = "USER"
+	ModerationTargetTypeProject ModerationTargetType = "PROJECT"
+)
+
+var AllModerationTargetType = []ModerationTargetType{
+	ModerationTargetTypeUser,
+	ModerationTargetTypeProject,
+}
+
+func (e ModerationTargetType) IsValid() bool {
+	switch e {
+	case ModerationTargetTypeUser, ModerationTargetTypeProject:
+		return true
+	}
+	return false
+}
+
+func (e ModerationTargetType) String() string {
+	return string(e)
+}
+
+func (e *ModerationTargetType) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ModerationTargetType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ModerationTargetType", str)
+	}
+	return nil
+}
+
+func (e ModerationTargetType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProjectResource string
+
+const (
+	ProjectResourceProject     ProjectResource = "PROJECT"
+	ProjectResourceTeam        ProjectResource = "TEAM"
+	ProjectResourceTask        ProjectResource = "TASK"
+	ProjectResourceJoinRequest ProjectResource = "JOIN_REQUEST"
+)
+
+var AllProjectResource = []ProjectResource{
+	ProjectResourceProject,
+	ProjectResourceTeam,
+	ProjectResourceTask,
+	ProjectResourceJoinRequest,
+}
+
+func (e ProjectResource) IsValid() bool {
+	switch e {
+	case ProjectResourceProject, ProjectResourceTeam, ProjectResourceTask, ProjectResourceJoinRequest:
+		return true
+	}
+	return false
+}
+
+func (e ProjectResource) String() string {
+	return string(e)
+}
+
+func (e *ProjectResource) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProjectResource(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProjectResource", str)
+	}
+	return nil
+}
+
+func (e ProjectResource) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "OWNER"
+	ProjectRoleMember ProjectRole = "MEMBER"
+)
+
+var AllProjectRole = []ProjectRole{
+	ProjectRoleOwner,
+	ProjectRoleMember,
+}
+
+func (e ProjectRole) IsValid() bool {
+	switch e {
+	case ProjectRoleOwner, ProjectRoleMember:
+		return true
+	}
+	return false
+}
+
+func (e ProjectRole) String() string {
+	return string(e)
+}
+
+func (e *ProjectRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProjectRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProjectRole", str)
+	}
+	return nil
+}
+
+func (e ProjectRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ProjectSort string
+
+const (
+	ProjectSortNewest        ProjectSort = "NEWEST"
+	ProjectSortPopularity    ProjectSort = "POPULARITY"
+	ProjectSortOpenPositions ProjectSort = "OPEN_POSITIONS"
+)
+
+var AllProjectSort = []ProjectSort{
+	ProjectSortNewest,
+	ProjectSortPopularity,
+	ProjectSortOpenPositions,
+}
+
+func (e ProjectSort) IsValid() bool {
+	switch e {
+	case ProjectSortNewest, ProjectSortPopularity, ProjectSortOpenPositions:
+		return true
+	}
+	return false
+}
+
+func (e ProjectSort) String() string {
+	return string(e)
+}
+
+func (e *ProjectSort) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProjectSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProjectSort", str)
+	}
+	return nil
+}
+
+func (e ProjectSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type ProjectStatus string
 
 const (
@@ -390,3 +805,134 @@ func (e *TaskStatus) UnmarshalGQL(v interface{}) error {
 func (e TaskStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type TechnologyMatch string
+
+const (
+	TechnologyMatchAny TechnologyMatch = "ANY"
+	TechnologyMatchAll TechnologyMatch = "ALL"
+)
+
+var AllTechnologyMatch = []TechnologyMatch{
+	TechnologyMatchAny,
+	TechnologyMatchAll,
+}
+
+func (e TechnologyMatch) IsValid() bool {
+	switch e {
+	case TechnologyMatchAny, TechnologyMatchAll:
+		return true
+	}
+	return false
+}
+
+func (e TechnologyMatch) String() string {
+	return string(e)
+}
+
+func (e *TechnologyMatch) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TechnologyMatch(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TechnologyMatch", str)
+	}
+	return nil
+}
+
+func (e TechnologyMatch) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TokenScope string
+
+const (
+	TokenScopeProjectsRead  TokenScope = "PROJECTS_READ"
+	TokenScopeProjectsWrite TokenScope = "PROJECTS_WRITE"
+	TokenScopeTasksRead     TokenScope = "TASKS_READ"
+	TokenScopeTasksWrite    TokenScope = "TASKS_WRITE"
+	TokenScopeTeamsAdmin    TokenScope = "TEAMS_ADMIN"
+	TokenScopeUsersRead     TokenScope = "USERS_READ"
+)
+
+var AllTokenScope = []TokenScope{
+	TokenScopeProjectsRead,
+	TokenScopeProjectsWrite,
+	TokenScopeTasksRead,
+	TokenScopeTasksWrite,
+	TokenScopeTeamsAdmin,
+	TokenScopeUsersRead,
+}
+
+func (e TokenScope) IsValid() bool {
+	switch e {
+	case TokenScopeProjectsRead, TokenScopeProjectsWrite, TokenScopeTasksRead, TokenScopeTasksWrite, TokenScopeTeamsAdmin, TokenScopeUsersRead:
+		return true
+	}
+	return false
+}
+
+func (e TokenScope) String() string {
+	return string(e)
+}
+
+func (e *TokenScope) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TokenScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TokenScope", str)
+	}
+	return nil
+}
+
+func (e TokenScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "USER"
+	UserRoleAdmin UserRole = "ADMIN"
+)
+
+var AllUserRole = []UserRole{
+	UserRoleUser,
+	UserRoleAdmin,
+}
+
+func (e UserRole) IsValid() bool {
+	switch e {
+	case UserRoleUser, UserRoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e UserRole) String() string {
+	return string(e)
+}
+
+func (e *UserRole) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserRole", str)
+	}
+	return nil
+}
+
+func (e UserRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
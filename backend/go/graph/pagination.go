@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+)
+
+// connectionArgs collects the Relay arguments of a connection query
+func connectionArgs(first *int, after *string, last *int, before *string, orderBy *model.ConnectionOrder) pagination.Args {
+	args := pagination.Args{First: first, After: after, Last: last, Before: before, Order: model.ConnectionOrderNewestFirst}
+	if orderBy != nil {
+		args.Order = *orderBy
+	}
+	return args
+}
+
+// wantsTotal reports whether the connection being resolved selects
+// totalCount, so the count query can be skipped when it does not
+func wantsTotal(ctx context.Context) bool {
+	for _, field := range graphql.CollectFieldsCtx(ctx, nil) {
+		if field.Name == "totalCount" {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
 	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
 )
 
@@ -9,11 +10,15 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
+	// Store is used for authorization checks
+	Store              repository.Store
 	UserService        *services.UserService
 	ProjectService     *services.ProjectService
 	TeamService        *services.TeamService
 	TaskService        *services.TaskService
 	JoinRequestService *services.JoinRequestService
+	AccessTokenService *services.AccessTokenService
+	ModerationService  *services.ModerationService
 }
 
 // // Query returns QueryResolver implementation.
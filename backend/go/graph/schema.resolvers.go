@@ -8,12 +8,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 
 	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
 	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/loaders"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/policy"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
 )
 
+// Actor is the resolver for the actor field.
+func (r *auditEventResolver) Actor(ctx context.Context, obj *model.AuditEvent) (*model.User, error) {
+	if obj.Actor == nil {
+		return nil, nil
+	}
+	user, err := loaders.For(ctx).UserByID.Load(ctx, obj.Actor.ID)
+	if errors.Is(err, loaders.ErrNotFound) {
+		// The actor may have deleted their account since
+		return nil, nil
+	}
+	return user, err
+}
+
+// User is the resolver for the user field.
+func (r *joinRequestResolver) User(ctx context.Context, obj *model.JoinRequest) (*model.User, error) {
+	return loaders.For(ctx).UserByID.Load(ctx, obj.User.ID)
+}
+
 // CreateProject is the resolver for the createProject field.
 func (r *mutationResolver) CreateProject(ctx context.Context, input model.CreateProjectInput) (*model.Project, error) {
 	// Assuming you have a way to get the current user's ID from the context
@@ -28,7 +53,7 @@ func (r *mutationResolver) CreateProject(ctx context.Context, input model.Create
 func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input model.UpdateProjectInput) (*model.Project, error) {
 	updatedProject, err := r.ProjectService.UpdateProject(ctx, id, input)
 	if err != nil {
-		log.Printf("Error updating project: %v", err)
+		slog.WarnContext(ctx, "Failed to update project", "project_id", id, "error", err)
 		return nil, fmt.Errorf("failed to update project: %w", err)
 	}
 	return updatedProject, nil
@@ -38,7 +63,7 @@ func (r *mutationResolver) UpdateProject(ctx context.Context, id string, input m
 func (r *mutationResolver) DeleteProject(ctx context.Context, id string) (bool, error) {
 	err := r.ProjectService.DeleteProject(ctx, id)
 	if err != nil {
-		log.Printf("Error deleting project: %v", err)
+		slog.WarnContext(ctx, "Failed to delete project", "project_id", id, "error", err)
 		return false, fmt.Errorf("failed to delete project: %w", err)
 	}
 	return true, nil
@@ -65,16 +90,11 @@ func (r *mutationResolver) LeaveTeam(ctx context.Context, teamID string) (bool,
 
 // UpdateUser is the resolver for the updateUser field.
 func (r *mutationResolver) UpdateUser(ctx context.Context, id string, input model.UpdateUserInput) (*model.User, error) {
-	log.Printf("Attempting to update user with ID: %s", id)
-	log.Printf("Update input: %+v", input)
-
 	user, err := r.UserService.UpdateUser(ctx, id, input)
 	if err != nil {
-		log.Printf("Error updating user: %v", err)
+		slog.WarnContext(ctx, "Failed to update user", "target_user_id", id, "error", err)
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
-
-	log.Printf("User updated successfully: %+v", user)
 	return user, nil
 }
 
@@ -106,6 +126,41 @@ func (r *mutationResolver) ChangePassword(ctx context.Context, id string, oldPas
 	return r.UserService.ChangePassword(ctx, id, oldPassword, newPassword)
 }
 
+// VerifyEmail is the resolver for the verifyEmail field.
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	return r.UserService.VerifyEmail(ctx, token)
+}
+
+// ResendVerification is the resolver for the resendVerification field.
+func (r *mutationResolver) ResendVerification(ctx context.Context) (bool, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("unauthorized: %w", err)
+	}
+
+	err = r.UserService.ResendVerification(ctx, userID)
+	return err == nil, err
+}
+
+// RequestPasswordReset is the resolver for the requestPasswordReset field.
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+	// Report success whether or not the email belongs to an account. Only
+	// the rate limit, which counts every address alike, is shown to callers.
+	err := r.UserService.RequestPasswordReset(ctx, email)
+	if errors.Is(err, services.ErrTooManyResetRequests) {
+		return false, err
+	} else if err != nil {
+		slog.ErrorContext(ctx, "Failed to request password reset", "error", err)
+	}
+	return true, nil
+}
+
+// ResetPassword is the resolver for the resetPassword field.
+func (r *mutationResolver) ResetPassword(ctx context.Context, token string, newPassword string) (bool, error) {
+	err := r.UserService.ResetPassword(ctx, token, newPassword)
+	return err == nil, err
+}
+
 // CreateTask is the resolver for the createTask field.
 func (r *mutationResolver) CreateTask(ctx context.Context, input model.CreateTaskInput) (*model.Task, error) {
 	return r.TaskService.CreateTask(ctx, input)
@@ -166,13 +221,25 @@ func (r *mutationResolver) DeleteTeam(ctx context.Context, id string) (bool, err
 }
 
 // LoginUser is the resolver for the loginUser field.
-func (r *mutationResolver) LoginUser(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
+func (r *mutationResolver) LoginUser(ctx context.Context, email string, password string) (model.LoginResult, error) {
 	// Call the LoginUser function from the UserService
-	token, err := r.UserService.LoginUser(ctx, email, password)
+	result, err := r.UserService.LoginUser(ctx, email, password)
 	if err != nil {
+		var lockedErr *services.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			return nil, loginLockedError(lockedErr)
+		}
+		if errors.Is(err, auth.ErrUserSuspended) {
+			return nil, suspendedError(err)
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Two-factor accounts finish logging in with verifyTwoFactorLogin
+	if result.Tokens == nil {
+		return newTwoFactorChallenge(result), nil
+	}
+
 	// Fetch the user details
 	user, err := r.UserService.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -180,10 +247,179 @@ func (r *mutationResolver) LoginUser(ctx context.Context, email string, password
 	}
 
 	// Create and return the AuthPayload
-	return &model.AuthPayload{
-		Token: token,
-		User:  user,
-	}, nil
+	return newAuthPayload(result.Tokens, user), nil
+}
+
+// VerifyTwoFactorLogin is the resolver for the verifyTwoFactorLogin field.
+func (r *mutationResolver) VerifyTwoFactorLogin(ctx context.Context, challengeToken string, code string) (*model.AuthPayload, error) {
+	tokens, err := r.UserService.VerifyTwoFactorLogin(ctx, challengeToken, code)
+	if err != nil {
+		var lockedErr *services.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			return nil, loginLockedError(lockedErr)
+		}
+		if errors.Is(err, auth.ErrUserSuspended) {
+			return nil, suspendedError(err)
+		}
+		return nil, err
+	}
+
+	user, err := r.UserService.GetUserByID(ctx, tokens.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return newAuthPayload(tokens, user), nil
+}
+
+// UnlockAccount is the resolver for the unlockAccount field.
+func (r *mutationResolver) UnlockAccount(ctx context.Context, email string) (bool, error) {
+	err := r.UserService.UnlockAccount(ctx, email)
+	return err == nil, err
+}
+
+// ReportContent is the resolver for the reportContent field.
+func (r *mutationResolver) ReportContent(ctx context.Context, input model.ReportContentInput) (*model.ModerationReport, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.ReportContent(ctx, userID, input)
+}
+
+// ResolveModerationReport is the resolver for the resolveModerationReport field.
+func (r *mutationResolver) ResolveModerationReport(ctx context.Context, id string, status model.ModerationReportStatus, note *string) (*model.ModerationReport, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.ResolveReport(ctx, userID, id, status, note)
+}
+
+// SuspendUser is the resolver for the suspendUser field.
+func (r *mutationResolver) SuspendUser(ctx context.Context, userID string, reason string) (*model.User, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.SuspendUser(ctx, adminID, userID, reason)
+}
+
+// UnsuspendUser is the resolver for the unsuspendUser field.
+func (r *mutationResolver) UnsuspendUser(ctx context.Context, userID string) (*model.User, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.UnsuspendUser(ctx, adminID, userID)
+}
+
+// SetUserRole is the resolver for the setUserRole field.
+func (r *mutationResolver) SetUserRole(ctx context.Context, userID string, role model.UserRole) (*model.User, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.SetUserRole(ctx, adminID, userID, role)
+}
+
+// HideProject is the resolver for the hideProject field.
+func (r *mutationResolver) HideProject(ctx context.Context, projectID string, reason string) (*model.Project, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.HideProject(ctx, adminID, projectID, reason)
+}
+
+// UnhideProject is the resolver for the unhideProject field.
+func (r *mutationResolver) UnhideProject(ctx context.Context, projectID string) (*model.Project, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.UnhideProject(ctx, adminID, projectID)
+}
+
+// ForceDeleteProject is the resolver for the forceDeleteProject field.
+func (r *mutationResolver) ForceDeleteProject(ctx context.Context, projectID string) (bool, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = r.ModerationService.ForceDeleteProject(ctx, adminID, projectID)
+	return err == nil, err
+}
+
+// TransferProjectOwnership is the resolver for the transferProjectOwnership field.
+func (r *mutationResolver) TransferProjectOwnership(ctx context.Context, projectID string, newOwnerID string) (*model.Project, error) {
+	adminID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.TransferProjectOwnership(ctx, adminID, projectID, newOwnerID)
+}
+
+// EnrollTwoFactor is the resolver for the enrollTwoFactor field.
+func (r *mutationResolver) EnrollTwoFactor(ctx context.Context) (*model.TwoFactorEnrollment, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.UserService.EnrollTwoFactor(ctx, userID)
+}
+
+// ConfirmTwoFactor is the resolver for the confirmTwoFactor field.
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.UserService.ConfirmTwoFactor(ctx, userID, code)
+}
+
+// DisableTwoFactor is the resolver for the disableTwoFactor field.
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = r.UserService.DisableTwoFactor(ctx, userID, code)
+	return err == nil, err
+}
+
+// CreatePersonalAccessToken is the resolver for the createPersonalAccessToken field.
+func (r *mutationResolver) CreatePersonalAccessToken(ctx context.Context, input model.CreatePersonalAccessTokenInput) (*model.CreatePersonalAccessTokenPayload, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.AccessTokenService.CreateToken(ctx, userID, input)
+}
+
+// RevokePersonalAccessToken is the resolver for the revokePersonalAccessToken field.
+func (r *mutationResolver) RevokePersonalAccessToken(ctx context.Context, id string) (bool, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = r.AccessTokenService.RevokeToken(ctx, userID, id)
+	return err == nil, err
+}
+
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	tokens, err := auth.RefreshSession(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.UserService.GetUserByID(ctx, tokens.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return newAuthPayload(tokens, user), nil
 }
 
 // LogoutUser is the resolver for the logoutUser field.
@@ -203,6 +439,26 @@ func (r *mutationResolver) LogoutUser(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// RevokeSession is the resolver for the revokeSession field.
+func (r *mutationResolver) RevokeSession(ctx context.Context, id string) (bool, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = auth.RevokeUserSession(ctx, userID, id)
+	return err == nil, err
+}
+
+// RevokeAllOtherSessions is the resolver for the revokeAllOtherSessions field.
+func (r *mutationResolver) RevokeAllOtherSessions(ctx context.Context) (bool, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	err = auth.RevokeOtherSessions(ctx, userID, auth.GetSessionIDFromContext(ctx))
+	return err == nil, err
+}
+
 // JoinProject is the resolver for the joinProject field.
 func (r *mutationResolver) JoinProject(ctx context.Context, projectID string) (*model.Project, error) {
 	userID, err := auth.GetUserIDFromContext(ctx)
@@ -210,28 +466,20 @@ func (r *mutationResolver) JoinProject(ctx context.Context, projectID string) (*
 		return nil, fmt.Errorf("unauthorized: %w", err)
 	}
 
-	return r.ProjectService.JoinProject(ctx, projectID, userID)
+	// Joining needs the owner's approval like any other join request
+	if _, err := r.JoinRequestService.CreateJoinRequest(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return r.ProjectService.GetProjectByID(ctx, projectID)
 }
 
 // RequestToJoinProject is the resolver for the requestToJoinProject field.
 func (r *mutationResolver) RequestToJoinProject(ctx context.Context, projectID string) (*model.JoinRequest, error) {
 	userID, err := auth.GetUserIDFromContext(ctx)
 	if err != nil {
-		log.Printf("Error getting user ID from context: %v", err)
 		return nil, fmt.Errorf("unauthorized: %w", err)
 	}
-
-	log.Printf("Attempting to create join request for user %s to project %s", userID, projectID)
-
-	joinRequest, err := r.JoinRequestService.CreateJoinRequest(ctx, projectID, userID)
-	if err != nil {
-		log.Printf("Error creating join request: %v", err)
-		return nil, err
-	}
-
-	log.Printf("Successfully created join request: %+v", joinRequest)
-
-	return joinRequest, nil
+	return r.JoinRequestService.CreateJoinRequest(ctx, projectID, userID)
 }
 
 // ApproveJoinRequest is the resolver for the approveJoinRequest field.
@@ -252,35 +500,52 @@ func (r *mutationResolver) DenyJoinRequest(ctx context.Context, requestID string
 	return r.JoinRequestService.DenyJoinRequest(ctx, requestID, userID)
 }
 
+// Owner is the resolver for the owner field.
+func (r *projectResolver) Owner(ctx context.Context, obj *model.Project) (*model.User, error) {
+	if obj.Owner == nil {
+		return nil, fmt.Errorf("project %s has no owner", obj.ID)
+	}
+	return loaders.For(ctx).UserByID.Load(ctx, obj.Owner.ID)
+}
+
+// Team is the resolver for the team field.
+func (r *projectResolver) Team(ctx context.Context, obj *model.Project) (*model.Team, error) {
+	return loaders.For(ctx).TeamByProject.Load(ctx, obj.ID)
+}
+
+// TeamMembers is the resolver for the teamMembers field.
+func (r *projectResolver) TeamMembers(ctx context.Context, obj *model.Project) ([]*model.TeamMember, error) {
+	return loaders.For(ctx).MembersByProject.Load(ctx, obj.ID)
+}
+
 // Project is the resolver for the project field.
 func (r *queryResolver) Project(ctx context.Context, id string) (*model.Project, error) {
+	// Projects hidden by moderators look like they do not exist
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	visible, err := policy.CanViewProject(ctx, r.Store, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, fmt.Errorf("project not found")
+	}
+
 	return r.ProjectService.GetProjectByID(ctx, id)
 }
 
 // Projects is the resolver for the projects field.
-func (r *queryResolver) Projects(ctx context.Context, category *string, status *model.ProjectStatus, technology *string, limit *int, offset *int) ([]*model.Project, error) {
-	filters := make(map[string]interface{})
-	if category != nil {
-		filters["category"] = *category
-	}
-	if status != nil {
-		filters["status"] = *status
-	}
-	if technology != nil {
-		filters["technology"] = *technology
+func (r *queryResolver) Projects(ctx context.Context, category *string, status *model.ProjectStatus, technology *string, filter *model.ProjectFilterInput, sort *model.ProjectSort, limit *int, offset *int) ([]*model.Project, error) {
+	sortVal := model.ProjectSortNewest
+	if sort != nil {
+		sortVal = *sort
 	}
 
-	// Use default values if limit or offset are nil
-	limitVal := 10
-	offsetVal := 0
-	if limit != nil {
-		limitVal = *limit
-	}
-	if offset != nil {
-		offsetVal = *offset
+	limitVal, offsetVal, err := pagination.Offset(limit, offset, 10)
+	if err != nil {
+		return nil, err
 	}
 
-	return r.ProjectService.ListProjects(ctx, filters, limitVal, offsetVal)
+	return r.ProjectService.ListProjects(ctx, projectFilter(filter, category, status, technology), sortVal, limitVal, offsetVal)
 }
 
 // User is the resolver for the user field.
@@ -290,24 +555,31 @@ func (r *queryResolver) User(ctx context.Context, id string) (*model.User, error
 
 // Users is the resolver for the users field.
 func (r *queryResolver) Users(ctx context.Context, limit *int, offset *int) ([]*model.User, error) {
-	// Set default values if limit or offset are nil
-	limitVal := 10 // Default limit
-	offsetVal := 0 // Default offset
-
-	if limit != nil {
-		limitVal = *limit
-	}
-	if offset != nil {
-		offsetVal = *offset
+	limitVal, offsetVal, err := pagination.Offset(limit, offset, 10)
+	if err != nil {
+		return nil, err
 	}
 
 	return r.UserService.ListUsers(ctx, limitVal, offsetVal)
 }
 
 // SearchProjects is the resolver for the searchProjects field.
-func (r *queryResolver) SearchProjects(ctx context.Context, query string) ([]*model.Project, error) {
-	// You can add default limit and offset values here
-	return r.ProjectService.SearchProjects(ctx, query, 10, 0)
+func (r *queryResolver) SearchProjects(ctx context.Context, query string, limit *int, offset *int) ([]*model.SearchHit, error) {
+	limitVal, offsetVal, err := pagination.Offset(limit, offset, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ProjectService.SearchProjects(ctx, query, limitVal, offsetVal)
+}
+
+// ProjectFacets is the resolver for the projectFacets field.
+func (r *queryResolver) ProjectFacets(ctx context.Context, filter *model.ProjectFilterInput, query *string) (*model.ProjectFacets, error) {
+	selected := projectFilter(filter, nil, nil, nil)
+	if query != nil {
+		selected.Query = strings.TrimSpace(*query)
+	}
+	return r.ProjectService.ProjectFacets(ctx, selected)
 }
 
 // Task is the resolver for the task field.
@@ -317,14 +589,20 @@ func (r *queryResolver) Task(ctx context.Context, id string) (*model.Task, error
 
 // Tasks is the resolver for the tasks field.
 func (r *queryResolver) Tasks(ctx context.Context, projectID string, status *model.TaskStatus, limit *int, offset *int) ([]*model.Task, error) {
-	// Implement filtering by status, limit, and offset in your TaskService
-	return r.TaskService.ListTasksByProject(ctx, projectID)
+	limitVal, offsetVal, err := pagination.Offset(limit, offset, pagination.MaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return r.TaskService.ListTasksByProject(ctx, projectID, status, limitVal, offsetVal)
 }
 
 // UserTasks is the resolver for the userTasks field.
 func (r *queryResolver) UserTasks(ctx context.Context, userID string, status *model.TaskStatus, limit *int, offset *int) ([]*model.Task, error) {
-	// Implement filtering by status, limit, and offset in your TaskService
-	return r.TaskService.GetTasksByUser(ctx, userID)
+	limitVal, offsetVal, err := pagination.Offset(limit, offset, pagination.MaxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return r.TaskService.GetTasksByUser(ctx, userID, status, limitVal, offsetVal)
 }
 
 // Team is the resolver for the team field.
@@ -337,32 +615,204 @@ func (r *queryResolver) TeamsByProject(ctx context.Context, projectID string) ([
 	return r.TeamService.GetTeamsByProject(ctx, projectID)
 }
 
+// PersonalAccessTokens is the resolver for the personalAccessTokens field.
+func (r *queryResolver) PersonalAccessTokens(ctx context.Context) ([]*model.PersonalAccessToken, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.AccessTokenService.ListTokens(ctx, userID)
+}
+
+// MySessions is the resolver for the mySessions field.
+func (r *queryResolver) MySessions(ctx context.Context) ([]*model.Session, error) {
+	userID, err := auth.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := auth.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentID := auth.GetSessionIDFromContext(ctx)
+	result := make([]*model.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, newSession(session, currentID))
+	}
+	return result, nil
+}
+
+// ModerationReports is the resolver for the moderationReports field.
+func (r *queryResolver) ModerationReports(ctx context.Context, status *model.ModerationReportStatus, limit *int, offset *int) ([]*model.ModerationReport, error) {
+	l, o, err := pagination.Offset(limit, offset, 20)
+	if err != nil {
+		return nil, err
+	}
+	return r.ModerationService.ListReports(ctx, status, l, o)
+}
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, projectID *string, actorID *string, from *string, to *string, limit *int, offset *int) ([]*model.AuditEvent, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+
+	var filter audit.Filter
+	if projectID != nil {
+		filter.ProjectID = *projectID
+	}
+	if actorID != nil {
+		filter.ActorID = *actorID
+	}
+	var err error
+	if filter.Limit, filter.Offset, err = pagination.Offset(limit, offset, defaultAuditLogLimit); err != nil {
+		return nil, err
+	}
+	if filter.From, err = parseAuditTime("from", from); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseAuditTime("to", to); err != nil {
+		return nil, err
+	}
+
+	// Project owners can read their own project's log; everything else is
+	// admin only
+	admin, err := policy.IsAdmin(ctx, r.Store, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		if filter.ProjectID == "" {
+			return nil, policyError(policy.ErrForbidden)
+		}
+		if err := policy.RequireProjectRole(ctx, r.Store, userID, policy.ResourceProject, filter.ProjectID, policy.RoleOwner); err != nil {
+			return nil, policyError(err)
+		}
+	}
+
+	events, err := r.Store.AuditLog(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.AuditEvent, len(events))
+	for i, event := range events {
+		result[i] = newAuditEvent(event, admin)
+	}
+	return result, nil
+}
+
 // JoinRequests is the resolver for the joinRequests field.
 func (r *queryResolver) JoinRequests(ctx context.Context, projectID string) ([]*model.JoinRequest, error) {
 	return r.JoinRequestService.GetJoinRequestsByProject(ctx, projectID)
 }
 
+// ProjectsConnection is the resolver for the projectsConnection field.
+func (r *queryResolver) ProjectsConnection(ctx context.Context, category *string, status *model.ProjectStatus, technology *string, filter *model.ProjectFilterInput, first *int, after *string, last *int, before *string, orderBy *model.ConnectionOrder) (*model.ProjectConnection, error) {
+	return r.ProjectService.ListProjectsConnection(ctx, projectFilter(filter, category, status, technology),
+		connectionArgs(first, after, last, before, orderBy), wantsTotal(ctx))
+}
+
+// UsersConnection is the resolver for the usersConnection field.
+func (r *queryResolver) UsersConnection(ctx context.Context, first *int, after *string, last *int, before *string, orderBy *model.ConnectionOrder) (*model.UserConnection, error) {
+	return r.UserService.ListUsersConnection(ctx, connectionArgs(first, after, last, before, orderBy), wantsTotal(ctx))
+}
+
+// TasksConnection is the resolver for the tasksConnection field.
+func (r *queryResolver) TasksConnection(ctx context.Context, projectID *string, assigneeID *string, status *model.TaskStatus, first *int, after *string, last *int, before *string, orderBy *model.ConnectionOrder) (*model.TaskConnection, error) {
+	filter := repository.TaskFilter{ProjectID: projectID, AssigneeID: assigneeID, Status: status}
+	return r.TaskService.ListTasksConnection(ctx, filter, connectionArgs(first, after, last, before, orderBy), wantsTotal(ctx))
+}
+
+// JoinRequestsConnection is the resolver for the joinRequestsConnection field.
+func (r *queryResolver) JoinRequestsConnection(ctx context.Context, projectID string, status *model.JoinRequestStatus, first *int, after *string, last *int, before *string, orderBy *model.ConnectionOrder) (*model.JoinRequestConnection, error) {
+	return r.JoinRequestService.ListJoinRequestsConnection(ctx, projectID, status,
+		connectionArgs(first, after, last, before, orderBy), wantsTotal(ctx))
+}
+
+// Assignee is the resolver for the assignee field.
+func (r *taskResolver) Assignee(ctx context.Context, obj *model.Task) (*model.User, error) {
+	// Unassigned tasks have no user to load
+	if obj.Assignee == nil {
+		return nil, nil
+	}
+	return loaders.For(ctx).UserByID.Load(ctx, obj.Assignee.ID)
+}
+
+// Members is the resolver for the members field.
+func (r *teamResolver) Members(ctx context.Context, obj *model.Team) ([]*model.TeamMember, error) {
+	return loaders.For(ctx).MembersByTeam.Load(ctx, obj.ID)
+}
+
+// Role is the resolver for the role field.
+func (r *userResolver) Role(ctx context.Context, obj *model.User) (model.UserRole, error) {
+	user, err := fullUser(ctx, obj)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+// SuspendedAt is the resolver for the suspendedAt field.
+func (r *userResolver) SuspendedAt(ctx context.Context, obj *model.User) (*string, error) {
+	// Only the user themselves and admins may see a suspension
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if userID != obj.ID {
+		admin, err := viewerIsAdmin(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, nil
+		}
+	}
+
+	user, err := fullUser(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return user.SuspendedAt, nil
+}
+
+// Projects is the resolver for the projects field.
+func (r *userResolver) Projects(ctx context.Context, obj *model.User) ([]*model.Project, error) {
+	return loaders.For(ctx).ProjectsByMember.Load(ctx, obj.ID)
+}
+
+// OwnedProjects is the resolver for the ownedProjects field.
+func (r *userResolver) OwnedProjects(ctx context.Context, obj *model.User) ([]*model.Project, error) {
+	return loaders.For(ctx).ProjectsByOwner.Load(ctx, obj.ID)
+}
+
+// AuditEvent returns AuditEventResolver implementation.
+func (r *Resolver) AuditEvent() AuditEventResolver { return &auditEventResolver{r} }
+
+// JoinRequest returns JoinRequestResolver implementation.
+func (r *Resolver) JoinRequest() JoinRequestResolver { return &joinRequestResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Project returns ProjectResolver implementation.
+func (r *Resolver) Project() ProjectResolver { return &projectResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Task returns TaskResolver implementation.
+func (r *Resolver) Task() TaskResolver { return &taskResolver{r} }
+
+// Team returns TeamResolver implementation.
+func (r *Resolver) Team() TeamResolver { return &teamResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type auditEventResolver struct{ *Resolver }
+type joinRequestResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type projectResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
-
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *mutationResolver) RejectJoinRequest(ctx context.Context, requestID string) (*model.JoinRequest, error) {
-	userID, err := auth.GetUserIDFromContext(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("unauthorized: %w", err)
-	}
-	return r.JoinRequestService.RejectJoinRequest(ctx, requestID, userID)
-}
-*/
+type taskResolver struct{ *Resolver }
+type teamResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,25 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func newSession(session *auth.Session, currentSessionID string) *model.Session {
+	result := &model.Session{
+		ID:         session.ID,
+		Device:     session.Device(),
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		Current:    session.ID == currentSessionID,
+	}
+	if session.IPAddress != "" {
+		result.IPAddress = &session.IPAddress
+	}
+	if session.UserAgent != "" {
+		result.UserAgent = &session.UserAgent
+	}
+	return result
+}
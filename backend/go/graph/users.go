@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/loaders"
+)
+
+// fullUser returns obj if it carries the user's role and suspension, and
+// otherwise the full user from the request's loader. Users and team members
+// are read with both; users embedded in other rows, such as a project's
+// owner, only carry a few columns and have no role.
+func fullUser(ctx context.Context, obj *model.User) (*model.User, error) {
+	if obj.Role != "" {
+		return obj, nil
+	}
+	return loaders.For(ctx).UserByID.Load(ctx, obj.ID)
+}
+
+// viewerIsAdmin reports whether the caller is a site administrator, going
+// through the request's loader so it costs at most one query per request
+func viewerIsAdmin(ctx context.Context, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	viewer, err := loaders.For(ctx).UserByID.Load(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return viewer.Role == model.UserRoleAdmin, nil
+}
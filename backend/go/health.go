@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/migrate"
+)
+
+// readinessTimeout bounds how long a readiness probe waits on the database
+const readinessTimeout = 2 * time.Second
+
+// healthHandler serves the liveness and readiness probes
+type healthHandler struct {
+	db       *sql.DB
+	migrator *migrate.Migrator
+}
+
+func newHealthHandler(db *sql.DB) (*healthHandler, error) {
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &healthHandler{db: db, migrator: migrator}, nil
+}
+
+// probeResult is the body of both probe responses
+type probeResult struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Live reports that the process is up and serving requests. It deliberately
+// ignores the database so an outage there does not restart every replica.
+func (h *healthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, probeResult{Status: "ok"})
+}
+
+// Ready reports whether this instance can serve traffic: the database must
+// answer and every embedded migration must have been applied. The checks only
+// read from the database.
+func (h *healthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	// Only generic check states go in the response, which is unauthenticated;
+	// the underlying errors are logged
+	result := probeResult{Status: "ok", Checks: map[string]string{}}
+	fail := func(check, state string, err error) {
+		slog.WarnContext(ctx, "Readiness check failed", "check", check, "error", err)
+		result.Status = "unavailable"
+		result.Checks[check] = state
+	}
+
+	if err := h.db.PingContext(ctx); err != nil {
+		fail("database", "unavailable", err)
+	} else {
+		result.Checks["database"] = "ok"
+
+		pending, err := h.migrator.Pending(ctx)
+		switch {
+		case err != nil:
+			fail("migrations", "unavailable", err)
+		case len(pending) > 0:
+			fail("migrations", "pending", fmt.Errorf("%d pending, next is %04d_%s", len(pending), pending[0].Version, pending[0].Name))
+		default:
+			result.Checks["migrations"] = "ok"
+		}
+	}
+
+	status := http.StatusOK
+	if result.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeProbe(w, status, result)
+}
+
+func writeProbe(w http.ResponseWriter, status int, result probeResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("Failed to write probe response", "error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadyHidesDatabaseErrors(t *testing.T) {
+	// Reserve a port and close it so nothing is listening there
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	db, err := sql.Open("postgres", fmt.Sprintf("host=127.0.0.1 port=%d user=nobody dbname=none sslmode=disable connect_timeout=1", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	health, err := newHealthHandler(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	health.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+	var body probeResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	want := probeResult{Status: "unavailable", Checks: map[string]string{"database": "unavailable"}}
+	if fmt.Sprint(body) != fmt.Sprint(want) {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
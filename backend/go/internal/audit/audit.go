@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionLogin              = "user.login"
+	ActionLoginFailed        = "user.login_failed"
+	ActionPasswordChange     = "user.password_change"
+	ActionPasswordReset      = "user.password_reset"
+	ActionTwoFactorEnable    = "user.two_factor_enable"
+	ActionTwoFactorDisable   = "user.two_factor_disable"
+	ActionAccessTokenCreate  = "user.access_token_create"
+	ActionAccessTokenRevoke  = "user.access_token_revoke"
+	ActionUserSuspend        = "user.suspend"
+	ActionUserUnsuspend      = "user.unsuspend"
+	ActionUserRoleChange     = "user.role_change"
+	ActionUserUnlock         = "user.unlock"
+	ActionProjectUpdate      = "project.update"
+	ActionProjectDelete      = "project.delete"
+	ActionProjectHide        = "project.hide"
+	ActionProjectUnhide      = "project.unhide"
+	ActionProjectTransfer    = "project.transfer_ownership"
+	ActionJoinRequestApprove = "join_request.approve"
+	ActionJoinRequestDeny    = "join_request.deny"
+	ActionModerationResolve  = "moderation_report.resolve"
+)
+
+// Target types of audit events
+const (
+	TargetUser             = "USER"
+	TargetProject          = "PROJECT"
+	TargetJoinRequest      = "JOIN_REQUEST"
+	TargetAccessToken      = "ACCESS_TOKEN"
+	TargetModerationReport = "MODERATION_REPORT"
+)
+
+// Event is one entry in the audit log. Before and After are stored as JSON
+// snapshots of whatever changed.
+type Event struct {
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	ProjectID  string
+	IPAddress  string
+	Before     interface{}
+	After      interface{}
+}
+
+// Execer is satisfied by *sql.Tx and *sql.DB. Pass the transaction of the
+// action being audited so the event is only kept if the action commits.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Record appends an event to the audit log. The actor and IP address are
+// taken from the request context unless set on the event.
+func Record(ctx context.Context, exec Execer, event Event) error {
+	if event.ActorID == "" {
+		event.ActorID, _ = auth.GetUserIDFromContext(ctx)
+	}
+	if event.IPAddress == "" {
+		event.IPAddress = auth.GetClientIPFromContext(ctx)
+	}
+
+	before, err := marshalSnapshot(event.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalSnapshot(event.After)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO audit_events (id, action, actor_id, target_type, target_id, project_id, ip_address, before, after, created_at)
+			  VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10)`
+	_, err = exec.ExecContext(ctx, query,
+		uuid.New().String(), event.Action, event.ActorID, event.TargetType, event.TargetID,
+		event.ProjectID, event.IPAddress, before, after, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// Filter narrows down audit log queries. Zero values are ignored.
+type Filter struct {
+	ProjectID string
+	ActorID   string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+// StoredEvent is an event read back from the audit log, with the snapshots
+// left as raw JSON
+type StoredEvent struct {
+	ID         string
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	ProjectID  string
+	IPAddress  string
+	Before     []byte
+	After      []byte
+	CreatedAt  time.Time
+}
+
+// Querier is satisfied by *sql.Tx and *sql.DB
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// List returns audit events matching the filter, newest first
+func List(ctx context.Context, q Querier, filter Filter) ([]*StoredEvent, error) {
+	query := `
+		SELECT id, action, COALESCE(actor_id, ''), target_type, target_id, COALESCE(project_id, ''),
+			   COALESCE(ip_address, ''), before, after, created_at
+		FROM audit_events
+		WHERE ($1 = '' OR project_id = $1)
+		  AND ($2 = '' OR actor_id = $2)
+		  AND ($3::timestamptz IS NULL OR created_at >= $3)
+		  AND ($4::timestamptz IS NULL OR created_at < $4)
+		ORDER BY created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := q.QueryContext(ctx, query,
+		filter.ProjectID, filter.ActorID, nullTime(filter.From), nullTime(filter.To), filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*StoredEvent
+	for rows.Next() {
+		var e StoredEvent
+		err := rows.Scan(&e.ID, &e.Action, &e.ActorID, &e.TargetType, &e.TargetID, &e.ProjectID,
+			&e.IPAddress, &e.Before, &e.After, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+func marshalSnapshot(v interface{}) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PersonalAccessTokenPrefix marks bearer tokens that are personal access
+// tokens rather than JWTs
+const PersonalAccessTokenPrefix = "pat_"
+
+// Scopes that can be granted to a personal access token
+const (
+	ScopeProjectsRead  = "projects:read"
+	ScopeProjectsWrite = "projects:write"
+	ScopeTasksRead     = "tasks:read"
+	ScopeTasksWrite    = "tasks:write"
+	ScopeTeamsAdmin    = "teams:admin"
+	ScopeUsersRead     = "users:read"
+)
+
+// lastUsedResolution limits how often last_used_at is written for a token
+// that is used in a tight loop
+const lastUsedResolution = time.Minute
+
+var ErrInvalidAccessToken = errors.New("invalid, expired or revoked personal access token")
+
+// IsPersonalAccessToken reports whether a bearer token looks like a personal
+// access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// GeneratePersonalAccessToken returns a new token and the hash to store
+func GeneratePersonalAccessToken() (string, string, error) {
+	token, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	token = PersonalAccessTokenPrefix + token
+	return token, HashToken(token), nil
+}
+
+// PersonalAccessToken is what authentication needs to know about a stored
+// personal access token
+type PersonalAccessToken struct {
+	ID        string
+	UserID    string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// AuthenticatePersonalAccessToken looks up an active personal access token
+// and returns the owning user ID and the scopes it was granted
+func AuthenticatePersonalAccessToken(ctx context.Context, token string) (string, []string, error) {
+	pat, err := sessions.PersonalAccessToken(ctx, HashToken(token))
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	if pat.ExpiresAt != nil && now.After(*pat.ExpiresAt) {
+		return "", nil, ErrInvalidAccessToken
+	}
+
+	if err := sessions.TouchPersonalAccessToken(ctx, pat.ID, now.UTC(), now.Add(-lastUsedResolution).UTC()); err != nil {
+		return "", nil, fmt.Errorf("failed to update personal access token: %w", err)
+	}
+
+	return pat.UserID, pat.Scopes, nil
+}
@@ -0,0 +1,89 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+func TestAuthenticatePersonalAccessToken(t *testing.T) {
+	store, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	token, tokenHash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := &repository.AccessToken{UserID: userID, Name: "ci", Scopes: []string{auth.ScopeTasksRead}}
+	if err := store.AccessTokens().Create(ctx, stored, tokenHash); err != nil {
+		t.Fatal(err)
+	}
+
+	gotUser, scopes, err := auth.AuthenticatePersonalAccessToken(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUser != userID || len(scopes) != 1 || scopes[0] != auth.ScopeTasksRead {
+		t.Errorf("authenticated as %s with %v, want %s with [%s]", gotUser, scopes, userID, auth.ScopeTasksRead)
+	}
+
+	tokens, err := store.AccessTokens().ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens[0].LastUsedAt == nil {
+		t.Error("last use of the token was not recorded")
+	}
+
+	if _, _, err := auth.AuthenticatePersonalAccessToken(ctx, "pat_unknown"); !errors.Is(err, auth.ErrInvalidAccessToken) {
+		t.Errorf("unknown token: err = %v, want %v", err, auth.ErrInvalidAccessToken)
+	}
+
+	if err := store.AccessTokens().Revoke(ctx, userID, stored.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := auth.AuthenticatePersonalAccessToken(ctx, token); !errors.Is(err, auth.ErrInvalidAccessToken) {
+		t.Errorf("revoked token: err = %v, want %v", err, auth.ErrInvalidAccessToken)
+	}
+}
+
+func TestAuthenticateExpiredPersonalAccessToken(t *testing.T) {
+	store, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	token, tokenHash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := time.Now().Add(-time.Minute)
+	stored := &repository.AccessToken{UserID: userID, Name: "old", ExpiresAt: &expired}
+	if err := store.AccessTokens().Create(ctx, stored, tokenHash); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := auth.AuthenticatePersonalAccessToken(ctx, token); !errors.Is(err, auth.ErrInvalidAccessToken) {
+		t.Errorf("expired token: err = %v, want %v", err, auth.ErrInvalidAccessToken)
+	}
+}
+
+func TestCheckUserActive(t *testing.T) {
+	store, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	if err := auth.CheckUserActive(ctx, userID); err != nil {
+		t.Errorf("active user: %v", err)
+	}
+	if err := store.Users().Suspend(ctx, userID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.CheckUserActive(ctx, userID); !errors.Is(err, auth.ErrUserSuspended) {
+		t.Errorf("suspended user: err = %v, want %v", err, auth.ErrUserSuspended)
+	}
+	if err := auth.CheckUserActive(ctx, "missing"); !errors.Is(err, auth.ErrUserSuspended) {
+		t.Errorf("deleted user: err = %v, want %v", err, auth.ErrUserSuspended)
+	}
+}
@@ -2,22 +2,23 @@ package auth
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"net/http"
-	"os"
-	"strings"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // Key is used as the key for storing the user ID in the context.
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+const (
+	UserIDKey    contextKey = "userID"
+	TokenKey     contextKey = "token"
+	TokenIDKey   contextKey = "tokenID"
+	ClientIPKey  contextKey = "clientIP"
+	UserAgentKey contextKey = "userAgent"
+	SessionIDKey contextKey = "sessionID"
+	ScopesKey    contextKey = "scopes"
+)
+
+var ErrUserSuspended = errors.New("this account has been suspended")
 
 // SetUserID adds the user ID to the context.
 func SetUserID(ctx context.Context, userID string) context.Context {
@@ -33,166 +34,90 @@ func GetUserIDFromContext(ctx context.Context) (string, error) {
 	return userID, nil
 }
 
-// TODO: Move this to a secure configuration, consider using environment variables
-var jwtSecretKey []byte
-
-func GenerateSecureKey(keySize int) ([]byte, error) {
-	key := make([]byte, keySize)
-	_, err := rand.Read(key)
-	if err != nil {
-		return nil, err
+// AuthenticateUser is a middleware for GraphQL resolvers
+func AuthenticateUser(ctx context.Context, next func(context.Context) (interface{}, error)) (interface{}, error) {
+	token := GetTokenFromContext(ctx)
+	if token == "" {
+		return nil, errors.New("no token provided")
 	}
-	return key, nil
-}
 
-func InitJWTSecretKey() error {
-	if envKey := os.Getenv("JWT_SECRET_KEY"); envKey != "" {
-		// fmt.Println(envKey)
-
-		jwtSecretKey = []byte(envKey)
-		return nil
-	}
-	key, err := GenerateSecureKey(32) // 256-bit key
+	claims, err := ValidateToken(ctx, token)
 	if err != nil {
-		return err
+		return nil, errors.New("invalid token")
 	}
-	jwtSecretKey = key
 
-	// Optionally, you can print the generated key for manual setting in environment variables
-	fmt.Printf("Generated JWT Secret Key: %s\n", base64.StdEncoding.EncodeToString(key))
+	// Add the user ID to the context
+	ctx = SetUserID(ctx, claims.UserID)
+	ctx = SetToken(ctx, token, claims.ID)
 
-	return nil
+	return next(ctx)
 }
 
-// CustomClaims extends StandardClaims to include additional fields
-type CustomClaims struct {
-	jwt.RegisteredClaims
-	UserID string `json:"user_id"`
+// SetToken adds the raw bearer token and its jti to the context.
+func SetToken(ctx context.Context, token, tokenID string) context.Context {
+	ctx = context.WithValue(ctx, TokenKey, token)
+	return context.WithValue(ctx, TokenIDKey, tokenID)
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID string) (string, error) {
-	claims := CustomClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-		UserID: userID,
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecretKey)
+// GetTokenFromContext extracts the raw bearer token from the context.
+func GetTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(TokenKey).(string)
+	return token
 }
 
-// AuthMiddleware checks for a JWT token in the Authorization header
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			// No token provided, allow the request to proceed
-			// Consider if you want to restrict access to authenticated users only
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims := &CustomClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return jwtSecretKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
-
-		// Add user ID to the context
-		ctx := SetUserID(r.Context(), claims.UserID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// GetTokenIDFromContext extracts the jti of the bearer token from the context.
+func GetTokenIDFromContext(ctx context.Context) string {
+	tokenID, _ := ctx.Value(TokenIDKey).(string)
+	return tokenID
 }
 
-// ValidateToken verifies the integrity and expiration of a token
-func ValidateToken(tokenString string) (*CustomClaims, error) {
-	if _, blacklisted := tokenBlacklist[tokenString]; blacklisted {
-		return nil, errors.New("token has been invalidated")
-	}
-
-	claims := &CustomClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return jwtSecretKey, nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	if !token.Valid {
-		return nil, errors.New("invalid token")
-	}
-
-	return claims, nil
+// SetClientIP adds the caller's IP address to the context.
+func SetClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ClientIPKey, ip)
 }
 
-// RefreshToken generates a new token for a user
-func RefreshToken(oldTokenString string) (string, error) {
-	claims, err := ValidateToken(oldTokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Generate a new token with a renewed expiration time
-	return GenerateToken(claims.UserID)
+// GetClientIPFromContext returns the caller's IP address, or an empty string
+// outside of an HTTP request.
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
 }
 
-// GetUserIDFromToken extracts the user ID from a token string
-func GetUserIDFromToken(tokenString string) (string, error) {
-	claims, err := ValidateToken(tokenString)
-	if err != nil {
-		return "", err
-	}
-	return claims.UserID, nil
+// SetUserAgent adds the caller's User-Agent header to the context.
+func SetUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, UserAgentKey, userAgent)
 }
 
-// AuthenticateUser is a middleware for GraphQL resolvers
-func AuthenticateUser(ctx context.Context, next func(context.Context) (interface{}, error)) (interface{}, error) {
-	token := GetTokenFromContext(ctx)
-	if token == "" {
-		return nil, errors.New("no token provided")
-	}
-
-	claims, err := ValidateToken(token)
-	if err != nil {
-		return nil, errors.New("invalid token")
-	}
-
-	// Add the user ID to the context
-	ctx = SetUserID(ctx, claims.UserID)
+// GetUserAgentFromContext returns the caller's User-Agent header, or an empty
+// string outside of an HTTP request.
+func GetUserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(UserAgentKey).(string)
+	return userAgent
+}
 
-	return next(ctx)
+// SetSessionID adds the ID of the session the caller is logged in with to
+// the context.
+func SetSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, SessionIDKey, sessionID)
 }
 
-// GetTokenFromContext extracts the token from the context
-// You'll need to implement this based on how you're passing the token in your GraphQL requests
-func GetTokenFromContext(ctx context.Context) string {
-	// Implementation depends on how you're passing the token
-	// For example, if it's in the context as "token":
-	token, _ := ctx.Value("token").(string)
-	return token
+// GetSessionIDFromContext returns the caller's session ID, or an empty string
+// when the request was not made with a login session.
+func GetSessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(SessionIDKey).(string)
+	return sessionID
 }
 
-var tokenBlacklist = make(map[string]time.Time)
+// SetTokenScopes marks the request as authenticated by a personal access
+// token with the given scopes.
+func SetTokenScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, ScopesKey, scopes)
+}
 
-func InvalidateToken(tokenString string) {
-	_, err := ValidateToken(tokenString)
-	if err == nil {
-		tokenBlacklist[tokenString] = time.Now().Add(24 * time.Hour) // Keep in blacklist for 24 hours
-	}
+// GetTokenScopesFromContext returns the scopes of the personal access token
+// used for the request. ok is false when the caller used a login session,
+// which is not restricted by scopes.
+func GetTokenScopesFromContext(ctx context.Context) (scopes []string, ok bool) {
+	scopes, ok = ctx.Value(ScopesKey).([]string)
+	return scopes, ok
 }
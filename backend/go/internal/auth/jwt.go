@@ -1 +1,105 @@
 package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// AccessTokenTTL is kept short because access tokens are verified without a
+// round trip to the database; long-lived access is provided by refresh tokens.
+const AccessTokenTTL = 15 * time.Minute
+
+// CustomClaims extends StandardClaims to include additional fields
+type CustomClaims struct {
+	jwt.RegisteredClaims
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid"`
+}
+
+// GenerateAccessToken creates a short-lived JWT for a user bound to a session
+func GenerateAccessToken(userID, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+	claims := CustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{accessTokenKind.audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		UserID:    userID,
+		SessionID: sessionID,
+	}
+
+	signed, err := keys.sign(accessTokenKind, claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ValidateToken verifies the integrity, type and expiration of an access
+// token and checks
+// that neither the token nor the session it belongs to has been revoked
+func ValidateToken(ctx context.Context, tokenString string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+	if err := keys.parse(accessTokenKind, tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, errors.New("token is not bound to a session")
+	}
+
+	revoked, err := sessions.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("token has been invalidated")
+	}
+
+	active, err := IsSessionActive(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errors.New("token has been invalidated")
+	}
+
+	return claims, nil
+}
+
+// GetUserIDFromToken extracts the user ID from a token string
+func GetUserIDFromToken(ctx context.Context, tokenString string) (string, error) {
+	claims, err := ValidateToken(ctx, tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// InvalidateToken revokes the token itself and the session it was issued
+// for, which also invalidates every refresh token in that session
+func InvalidateToken(ctx context.Context, tokenString string) error {
+	claims, err := ValidateToken(ctx, tokenString)
+	if err != nil {
+		return err
+	}
+
+	if err := sessions.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+	// A session revoked in the meantime needs no further work
+	err = RevokeUserSession(ctx, claims.UserID, claims.SessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func TestValidateToken(t *testing.T) {
+	_, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	pair, err := auth.CreateSession(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := auth.ValidateToken(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != userID || claims.SessionID != pair.SessionID {
+		t.Errorf("claims = %+v, want user %s in session %s", claims, userID, pair.SessionID)
+	}
+
+	if err := auth.InvalidateToken(ctx, pair.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.ValidateToken(ctx, pair.AccessToken); err == nil {
+		t.Error("ValidateToken accepted a logged out token")
+	}
+}
+
+func TestTokensAreOnlyAcceptedForTheirPurpose(t *testing.T) {
+	_, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	pair, err := auth.CreateSession(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verification, err := auth.GenerateEmailVerificationToken(userID, "ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, _, err := auth.GenerateTwoFactorChallenge(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"verification": verification, "challenge": challenge} {
+		if _, err := auth.ValidateToken(ctx, token); err == nil {
+			t.Errorf("ValidateToken accepted a %s token", name)
+		}
+	}
+
+	if _, _, err := auth.ParseEmailVerificationToken(pair.AccessToken); err == nil {
+		t.Error("ParseEmailVerificationToken accepted an access token")
+	}
+	if _, _, err := auth.ParseEmailVerificationToken(challenge); err == nil {
+		t.Error("ParseEmailVerificationToken accepted a challenge token")
+	}
+	if _, err := auth.ParseTwoFactorChallenge(verification); err == nil {
+		t.Error("ParseTwoFactorChallenge accepted a verification token")
+	}
+
+	gotUser, email, err := auth.ParseEmailVerificationToken(verification)
+	if err != nil || gotUser != userID || email != "ada@example.com" {
+		t.Errorf("ParseEmailVerificationToken = %q, %q, %v", gotUser, email, err)
+	}
+}
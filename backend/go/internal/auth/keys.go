@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the key used to sign new tokens and every public key that is
+// still accepted when verifying them. Rotating keys means adding the new
+// public key to the verification set everywhere first, then switching the
+// signing key, and removing the old public key once its tokens have expired.
+type KeySet struct {
+	signer *signingKey
+	keys   map[string]*verificationKey
+}
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+var keys *KeySet
+
+// SetKeySet replaces the keys used to sign and verify tokens
+func SetKeySet(ks *KeySet) {
+	keys = ks
+}
+
+// InitJWTKeys loads the signing key from signingKeyFile and additional
+// verification keys from verificationDir. For local development, ephemeral
+// generates a throwaway key instead; tokens signed with it stop working on
+// restart.
+func InitJWTKeys(signingKeyFile, verificationDir string, ephemeral bool) error {
+	if signingKeyFile == "" {
+		if !ephemeral {
+			return errors.New("no JWT signing key file configured")
+		}
+		slog.Warn("Using an ephemeral JWT signing key; tokens will not survive a restart")
+		ks, err := GenerateEphemeralKeySet()
+		if err != nil {
+			return err
+		}
+		SetKeySet(ks)
+		return nil
+	}
+
+	ks, err := LoadKeySet(signingKeyFile, verificationDir)
+	if err != nil {
+		return err
+	}
+	SetKeySet(ks)
+	return nil
+}
+
+// LoadKeySet reads a PEM encoded RSA or Ed25519 private key used for signing,
+// and optionally every .pem file in verificationDir as additional
+// verification keys. Those may hold public or private keys.
+func LoadKeySet(signingKeyFile, verificationDir string) (*KeySet, error) {
+	data, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	signer, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", signingKeyFile, err)
+	}
+
+	ks, err := newKeySet(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	if verificationDir == "" {
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(verificationDir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list verification keys: %w", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key: %w", err)
+		}
+		pub, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", file, err)
+		}
+		if _, err := ks.addVerificationKey(pub); err != nil {
+			return nil, fmt.Errorf("verification key %s: %w", file, err)
+		}
+	}
+
+	return ks, nil
+}
+
+// GenerateEphemeralKeySet creates a key set with a fresh Ed25519 key that
+// only lives in memory
+func GenerateEphemeralKeySet() (*KeySet, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return newKeySet(priv)
+}
+
+func newKeySet(signer crypto.Signer) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*verificationKey)}
+	vk, err := ks.addVerificationKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	ks.signer = &signingKey{kid: vk.kid, method: vk.method, key: signer}
+	return ks, nil
+}
+
+func (ks *KeySet) addVerificationKey(pub crypto.PublicKey) (*verificationKey, error) {
+	method, err := signingMethodFor(pub)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := keyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	vk := &verificationKey{kid: kid, method: method, key: pub}
+	ks.keys[kid] = vk
+	return vk, nil
+}
+
+// tokenKind is what a JWT was issued for. Every kind has its own typ header
+// and audience, and a token is only accepted as the kind it was issued as,
+// so a verification link or 2FA challenge can never pass as an access token.
+type tokenKind struct {
+	typ      string
+	audience string
+}
+
+var (
+	accessTokenKind        = tokenKind{typ: "at+jwt", audience: "projectivity-api"}
+	emailVerificationKind  = tokenKind{typ: "email-verification+jwt", audience: "projectivity-email-verification"}
+	twoFactorChallengeKind = tokenKind{typ: "two-factor-challenge+jwt", audience: "projectivity-two-factor-challenge"}
+)
+
+// sign signs claims with the current signing key and sets the kid and typ
+// headers. The claims must carry the kind's audience.
+func (ks *KeySet) sign(kind tokenKind, claims jwt.Claims) (string, error) {
+	if ks == nil {
+		return "", errors.New("JWT keys are not initialized")
+	}
+	token := jwt.NewWithClaims(ks.signer.method, claims)
+	token.Header["kid"] = ks.signer.kid
+	token.Header["typ"] = kind.typ
+	return token.SignedString(ks.signer.key)
+}
+
+// parse verifies a token of the given kind into claims, requiring the
+// matching typ header, audience and an expiry
+func (ks *KeySet) parse(kind tokenKind, tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc,
+		jwt.WithAudience(kind.audience), jwt.WithExpirationRequired())
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	if typ, _ := token.Header["typ"].(string); typ != kind.typ {
+		return fmt.Errorf("unexpected token type %q", typ)
+	}
+	return nil
+}
+
+// keyFunc picks the verification key named by the token's kid header and
+// makes sure the token uses the algorithm that key is meant for
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if ks == nil {
+		return nil, errors.New("JWT keys are not initialized")
+	}
+	kid, _ := token.Header["kid"].(string)
+	vk, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return vk.key, nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the verification keys as a JSON Web Key Set
+func (ks *KeySet) JWKS() []JWK {
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		vk := ks.keys[kid]
+		k := JWK{Kid: kid, Use: "sig", Alg: vk.method.Alg()}
+		switch pub := vk.key.(type) {
+		case *rsa.PublicKey:
+			k.Kty = "RSA"
+			k.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			k.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			k.Kty = "OKP"
+			k.Crv = "Ed25519"
+			k.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set = append(set, k)
+	}
+	return set
+}
+
+// JWKSHandler serves the current verification keys so other services can
+// verify our tokens, typically mounted at /.well-known/jwks.json
+func JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keys == nil {
+			http.Error(w, "JWT keys are not initialized", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(map[string][]JWK{"keys": keys.JWKS()}); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write JWKS", "error", err)
+		}
+	})
+}
+
+func signingMethodFor(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// keyID derives a stable kid from the public key so the same key gets the
+// same kid on every instance without extra configuration
+func keyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case ed25519.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			signer, err := parsePrivateKey(data)
+			if err != nil {
+				return nil, err
+			}
+			return signer.Public(), nil
+		}
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
@@ -1 +1,91 @@
 package auth
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AuthMiddleware checks for a JWT or personal access token in the
+// Authorization header
+func AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := SetClientIP(r.Context(), clientIP(r))
+		ctx = SetUserAgent(ctx, r.UserAgent())
+		r = r.WithContext(ctx)
+
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			// No token provided, allow the request to proceed
+			// Consider if you want to restrict access to authenticated users only
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+		if IsPersonalAccessToken(tokenString) {
+			userID, scopes, err := AuthenticatePersonalAccessToken(r.Context(), tokenString)
+			if err != nil {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			if !checkUserActive(w, r, userID) {
+				return
+			}
+
+			ctx = SetUserID(ctx, userID)
+			ctx = SetTokenScopes(ctx, scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		claims, err := ValidateToken(r.Context(), tokenString)
+		if err != nil {
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		if !checkUserActive(w, r, claims.UserID) {
+			return
+		}
+
+		TouchSession(ctx, claims.SessionID)
+
+		// Add the user ID, raw token, jti and session to the context so
+		// resolvers (logout in particular) can act on the token that was presented
+		ctx = SetUserID(ctx, claims.UserID)
+		ctx = SetToken(ctx, tokenString, claims.ID)
+		ctx = SetSessionID(ctx, claims.SessionID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// checkUserActive rejects requests from suspended users, writing the
+// response itself. It reports whether the request may continue.
+func checkUserActive(w http.ResponseWriter, r *http.Request, userID string) bool {
+	err := CheckUserActive(r.Context(), userID)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, ErrUserSuspended):
+		http.Error(w, "Account suspended", http.StatusForbidden)
+	default:
+		slog.ErrorContext(r.Context(), "Failed to check account status", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+	return false
+}
+
+// clientIP returns the host part of RemoteAddr. Behind a trusted proxy,
+// RemoteAddr has already been rewritten by TrustedProxies.RealIP.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenTTL is how long a refresh token can be exchanged for a new
+// token pair. Every exchange rotates the refresh token.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// sessionTouchInterval is how stale a session's last-use time may get before
+// a request writes it again
+const sessionTouchInterval = 5 * time.Minute
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used; session revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+)
+
+// Session is a login on one device
+type Session struct {
+	ID         string
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// Device returns a short human readable description of the session's device
+func (s *Session) Device() string {
+	return DescribeDevice(s.UserAgent)
+}
+
+// RefreshToken is a stored refresh token. Only the hash of the token itself
+// is kept.
+type RefreshToken struct {
+	ID        string
+	SessionID string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	// UsedAt is set once the token has been exchanged
+	UsedAt *time.Time
+	// SessionRevoked is set when the token's session has been revoked
+	SessionRevoked bool
+}
+
+// SessionStore persists sessions and the refresh tokens issued for them
+type SessionStore interface {
+	// Create inserts a session for userID together with its first refresh
+	// token
+	Create(ctx context.Context, userID string, session *Session, token *RefreshToken) error
+	// RefreshToken looks up a refresh token by its hash, returning
+	// ErrInvalidRefreshToken if there is none
+	RefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// Rotate marks used as exchanged, stores next for the same session and
+	// records the session's use from ip. It returns ErrRefreshTokenReused if
+	// used was already exchanged and ErrInvalidRefreshToken if the session
+	// has been revoked.
+	Rotate(ctx context.Context, used, next *RefreshToken, ip string) error
+	// Revoke revokes an active session of the user, returning
+	// ErrSessionNotFound if there is none
+	Revoke(ctx context.Context, userID, sessionID string) error
+	// RevokeAll revokes every active session of the user except
+	// keepSessionID, which may be empty
+	RevokeAll(ctx context.Context, userID, keepSessionID string) error
+	// List returns the active sessions of the user, most recently used first
+	List(ctx context.Context, userID string) ([]*Session, error)
+	// Touch records a use of the session from ip at the given time unless it
+	// was already used after staleBefore
+	Touch(ctx context.Context, sessionID, ip string, at, staleBefore time.Time) error
+	// IsActive reports whether the session exists and has not been revoked
+	IsActive(ctx context.Context, sessionID string) (bool, error)
+
+	// RevokeToken rejects the access token with the given ID (jti) until it
+	// expires
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+
+	// PersonalAccessToken looks up an unrevoked personal access token by its
+	// hash, returning ErrInvalidAccessToken if there is none
+	PersonalAccessToken(ctx context.Context, tokenHash string) (*PersonalAccessToken, error)
+	// TouchPersonalAccessToken records a use of the token at the given time
+	// unless it was already used after staleBefore
+	TouchPersonalAccessToken(ctx context.Context, id string, at, staleBefore time.Time) error
+
+	// IsUserSuspended reports whether the user has been suspended by a
+	// moderator or no longer exists
+	IsUserSuspended(ctx context.Context, userID string) (bool, error)
+}
+
+var sessions SessionStore
+
+// SetSessionStore sets the store used by the session functions, by
+// ValidateToken and by AuthMiddleware. It must be called before the server
+// starts handling requests.
+func SetSessionStore(store SessionStore) {
+	sessions = store
+}
+
+// TokenPair is returned on login and on every refresh
+type TokenPair struct {
+	UserID           string
+	SessionID        string
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// GenerateOpaqueToken returns a random URL-safe token together with the hash
+// that should be persisted in its place
+func GenerateOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes an opaque token for storage and lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession starts a new session (a refresh token family) for a user and
+// issues its first token pair
+func CreateSession(ctx context.Context, userID string) (*TokenPair, error) {
+	return CreateSessionIn(ctx, sessions, userID)
+}
+
+// CreateSessionIn is CreateSession with the given store, such as one bound
+// to the caller's transaction
+func CreateSessionIn(ctx context.Context, store SessionStore, userID string) (*TokenPair, error) {
+	now := time.Now().UTC()
+	session := &Session{
+		ID:         uuid.New().String(),
+		UserAgent:  GetUserAgentFromContext(ctx),
+		IPAddress:  GetClientIPFromContext(ctx),
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+
+	token, pair, err := issueTokenPair(userID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Create(ctx, userID, session, token); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return pair, nil
+}
+
+// RefreshSession exchanges a refresh token for a new token pair. A refresh
+// token can only be used once; presenting one that was already rotated means
+// it leaked, so the whole session is revoked.
+func RefreshSession(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	used, err := sessions.RefreshToken(ctx, HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if used.SessionRevoked {
+		return nil, ErrInvalidRefreshToken
+	}
+	if used.UsedAt != nil {
+		return nil, revokeReusedSession(ctx, used)
+	}
+	if time.Now().After(used.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, pair, err := issueTokenPair(used.UserID, used.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sessions.Rotate(ctx, used, next, GetClientIPFromContext(ctx))
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// Another request exchanged the same token first
+		return nil, revokeReusedSession(ctx, used)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// revokeReusedSession revokes the session of a refresh token that was
+// presented again after being exchanged
+func revokeReusedSession(ctx context.Context, token *RefreshToken) error {
+	slog.WarnContext(ctx, "Refresh token reuse detected, revoking session", "session_id", token.SessionID)
+	if err := sessions.Revoke(ctx, token.UserID, token.SessionID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// RevokeUserSessions revokes every active session belonging to a user
+func RevokeUserSessions(ctx context.Context, userID string) error {
+	if err := sessions.RevokeAll(ctx, userID, ""); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the active sessions of a user, most recently used
+// first
+func ListSessions(ctx context.Context, userID string) ([]*Session, error) {
+	list, err := sessions.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return list, nil
+}
+
+// RevokeUserSession revokes one of the user's own sessions
+func RevokeUserSession(ctx context.Context, userID, sessionID string) error {
+	return sessions.Revoke(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions revokes every session of a user except the given one
+func RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error {
+	if err := sessions.RevokeAll(ctx, userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+var sessionTouches = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: make(map[string]time.Time)}
+
+// TouchSession records that a session was used. To avoid a write on every
+// request it only updates sessions not touched in the last
+// sessionTouchInterval, tracked in memory and double-checked by the store
+// for the benefit of other instances. Failures are logged, not returned.
+func TouchSession(ctx context.Context, sessionID string) {
+	now := time.Now()
+
+	sessionTouches.Lock()
+	if last, ok := sessionTouches.last[sessionID]; ok && now.Sub(last) < sessionTouchInterval {
+		sessionTouches.Unlock()
+		return
+	}
+	sessionTouches.last[sessionID] = now
+	if len(sessionTouches.last) > 10000 {
+		for id, last := range sessionTouches.last {
+			if now.Sub(last) >= sessionTouchInterval {
+				delete(sessionTouches.last, id)
+			}
+		}
+	}
+	sessionTouches.Unlock()
+
+	err := sessions.Touch(ctx, sessionID, GetClientIPFromContext(ctx), now.UTC(), now.Add(-sessionTouchInterval).UTC())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update last use of session", "session_id", sessionID, "error", err)
+	}
+}
+
+// IsSessionActive reports whether a session exists and has not been revoked.
+// The check goes to the store so a revocation is seen by every instance.
+func IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	active, err := sessions.IsActive(ctx, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+// issueTokenPair generates a refresh token to store for the session and the
+// token pair to hand to the client
+func issueTokenPair(userID, sessionID string) (*RefreshToken, *TokenPair, error) {
+	refreshToken, refreshHash, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	stored := &RefreshToken{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		UserID:    userID,
+		TokenHash: refreshHash,
+		ExpiresAt: now.Add(RefreshTokenTTL),
+		CreatedAt: now,
+	}
+
+	accessToken, accessExpiresAt, err := GenerateAccessToken(userID, sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return stored, &TokenPair{
+		UserID:           userID,
+		SessionID:        sessionID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
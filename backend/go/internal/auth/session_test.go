@@ -0,0 +1,140 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/memory"
+)
+
+func TestMain(m *testing.M) {
+	if err := auth.InitJWTKeys("", "", true); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// newSessionStore points the session functions at a fresh in-memory store
+// and returns it with the ID of a user to start sessions for
+func newSessionStore(t *testing.T) (*memory.Store, string) {
+	t.Helper()
+
+	store := memory.NewStore()
+	auth.SetSessionStore(store.Sessions())
+
+	user := &model.User{Username: "ada", Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace"}
+	if err := store.Users().Create(context.Background(), user, []byte("hash")); err != nil {
+		t.Fatal(err)
+	}
+	return store, user.ID
+}
+
+func TestRefreshSessionRotatesToken(t *testing.T) {
+	_, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	first, err := auth.CreateSession(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := auth.RefreshSession(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.SessionID != first.SessionID || second.UserID != userID {
+		t.Errorf("refreshed pair = %+v, want the same session and user", second)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token was not rotated")
+	}
+
+	// The new token can be exchanged in turn
+	if _, err := auth.RefreshSession(ctx, second.RefreshToken); err != nil {
+		t.Errorf("refreshing with the rotated token: %v", err)
+	}
+}
+
+func TestRefreshSessionReuseRevokesSession(t *testing.T) {
+	_, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	first, err := auth.CreateSession(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := auth.RefreshSession(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.RefreshSession(ctx, first.RefreshToken); !errors.Is(err, auth.ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token: err = %v, want %v", err, auth.ErrRefreshTokenReused)
+	}
+
+	active, err := auth.IsSessionActive(ctx, first.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Error("session is still active after its refresh token was reused")
+	}
+
+	// The legitimate holder of the latest token is signed out too
+	if _, err := auth.RefreshSession(ctx, second.RefreshToken); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("latest token after reuse: err = %v, want %v", err, auth.ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshSessionRejectsExpiredToken(t *testing.T) {
+	store, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	session := &auth.Session{ID: "session-1", CreatedAt: now.Add(-2 * auth.RefreshTokenTTL), LastUsedAt: now.Add(-auth.RefreshTokenTTL)}
+	err = store.Sessions().Create(ctx, userID, session, &auth.RefreshToken{
+		ID:        "token-1",
+		SessionID: session.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(-time.Minute),
+		CreatedAt: session.CreatedAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.RefreshSession(ctx, token); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("expired token: err = %v, want %v", err, auth.ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshSessionRejectsRevokedSession(t *testing.T) {
+	_, userID := newSessionStore(t)
+	ctx := context.Background()
+
+	pair, err := auth.CreateSession(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.RevokeUserSession(ctx, userID, pair.SessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.RefreshSession(ctx, pair.RefreshToken); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("token of a revoked session: err = %v, want %v", err, auth.ErrInvalidRefreshToken)
+	}
+	if _, err := auth.RefreshSession(ctx, "not-a-refresh-token"); !errors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("unknown token: err = %v, want %v", err, auth.ErrInvalidRefreshToken)
+	}
+}
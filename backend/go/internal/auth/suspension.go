@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckUserActive returns ErrUserSuspended when the user has been suspended
+// by a moderator or no longer exists
+func CheckUserActive(ctx context.Context, userID string) error {
+	suspended, err := sessions.IsUserSuspended(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check account status: %w", err)
+	}
+
+	if suspended {
+		return ErrUserSuspended
+	}
+	return nil
+}
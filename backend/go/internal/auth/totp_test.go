@@ -0,0 +1,126 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B,
+// "12345678901234567890", base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is their last six digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		at := time.Unix(v.unix, 0)
+		code, err := auth.TOTPCode(rfc6238Secret, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("TOTPCode at %d = %s, want %s", v.unix, code, v.code)
+		}
+
+		step, ok := auth.ValidateTOTPCode(rfc6238Secret, v.code, at)
+		if !ok || step != v.unix/30 {
+			t.Errorf("ValidateTOTPCode(%s) at %d = %d, %v, want step %d", v.code, v.unix, step, ok, v.unix/30)
+		}
+	}
+}
+
+func TestValidateTOTPCodeSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	for _, tc := range []struct {
+		offset time.Duration
+		valid  bool
+	}{
+		{-90 * time.Second, false},
+		{-30 * time.Second, true},
+		{0, true},
+		{30 * time.Second, true},
+		{90 * time.Second, false},
+	} {
+		code, err := auth.TOTPCode(rfc6238Secret, now.Add(tc.offset))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := auth.ValidateTOTPCode(rfc6238Secret, code, now); ok != tc.valid {
+			t.Errorf("code from %s away: valid = %v, want %v", tc.offset, ok, tc.valid)
+		}
+	}
+}
+
+func TestValidateTOTPCodeRejectsMalformedInput(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	for _, code := range []string{"", "00592", "0059244", "abcdef"} {
+		if _, ok := auth.ValidateTOTPCode(rfc6238Secret, code, now); ok {
+			t.Errorf("ValidateTOTPCode accepted %q", code)
+		}
+	}
+	if _, ok := auth.ValidateTOTPCode("not base32!", "005924", now); ok {
+		t.Error("ValidateTOTPCode accepted an invalid secret")
+	}
+	if _, ok := auth.ValidateTOTPCode(strings.ToLower(rfc6238Secret), " 005924 ", now); !ok {
+		t.Error("ValidateTOTPCode rejected a lower-case secret or padded code")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 160 bits in unpadded base32
+	if len(secret) != 32 {
+		t.Errorf("secret %q has %d characters, want 32", secret, len(secret))
+	}
+
+	code, err := auth.TOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := auth.ValidateTOTPCode(secret, code, time.Now()); !ok {
+		t.Error("a fresh secret does not validate its own code")
+	}
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	token, expiresAt, err := auth.GenerateTwoFactorChallenge("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(expiresAt) > auth.TwoFactorChallengeTTL {
+		t.Errorf("challenge expires at %s, later than the TTL allows", expiresAt)
+	}
+
+	userID, err := auth.ParseTwoFactorChallenge(token)
+	if err != nil || userID != "user-1" {
+		t.Errorf("ParseTwoFactorChallenge = %q, %v, want user-1", userID, err)
+	}
+
+	// A session's access token is not a challenge
+	access, _, err := auth.GenerateAccessToken("user-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.ParseTwoFactorChallenge(access); err == nil {
+		t.Error("ParseTwoFactorChallenge accepted an access token")
+	}
+}
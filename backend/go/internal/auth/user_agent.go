@@ -0,0 +1,62 @@
+package auth
+
+import "strings"
+
+// uaMatch maps a User-Agent substring to a display name. Order matters: more
+// specific tokens come first, e.g. Edge and Opera also claim to be Chrome.
+type uaMatch struct {
+	token string
+	name  string
+}
+
+var uaBrowsers = []uaMatch{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"PostmanRuntime/", "Postman"},
+}
+
+var uaPlatforms = []uaMatch{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DescribeDevice turns a User-Agent header into a short label such as
+// "Firefox on Windows". It is meant for display only.
+func DescribeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := firstMatch(userAgent, uaBrowsers)
+	platform := firstMatch(userAgent, uaPlatforms)
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
+
+func firstMatch(userAgent string, matches []uaMatch) string {
+	for _, m := range matches {
+		if strings.Contains(userAgent, m.token) {
+			return m.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete server configuration. Values are resolved in order:
+// built-in defaults, then the optional YAML file named by CONFIG_FILE, then
+// environment variables.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	JWT      JWTConfig      `yaml:"jwt"`
+	CORS     CORSConfig     `yaml:"cors"`
+	Mail     MailConfig     `yaml:"mail"`
+	Log      LogConfig      `yaml:"log"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	// AppBaseURL is the frontend URL used in links sent by email
+	AppBaseURL string `yaml:"appBaseUrl"`
+}
+
+type ServerConfig struct {
+	Port         int           `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`
+	// ShutdownTimeout is how long in-flight requests may run after SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// TrustedProxies are the CIDR ranges or addresses of the load balancers
+	// whose X-Forwarded-For and X-Real-IP headers are believed. Headers from
+	// anyone else are ignored.
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// DatabaseConfig describes the Postgres connection. URL takes precedence over
+// the individual connection fields when set.
+type DatabaseConfig struct {
+	URL             string        `yaml:"url"`
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslMode"`
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnectTimeout  time.Duration `yaml:"connectTimeout"`
+	// AutoMigrate applies pending migrations when the server starts
+	AutoMigrate bool `yaml:"autoMigrate"`
+}
+
+type JWTConfig struct {
+	SigningKeyFile      string `yaml:"signingKeyFile"`
+	VerificationKeysDir string `yaml:"verificationKeysDir"`
+	// DevEphemeralKey generates a throwaway signing key for local development
+	DevEphemeralKey bool `yaml:"devEphemeralKey"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+}
+
+// MailConfig configures outgoing mail. Without an SMTP host, messages are
+// written to OutboxDir instead.
+type MailConfig struct {
+	From         string `yaml:"from"`
+	OutboxDir    string `yaml:"outboxDir"`
+	SMTPHost     string `yaml:"smtpHost"`
+	SMTPPort     int    `yaml:"smtpPort"`
+	SMTPUsername string `yaml:"smtpUsername"`
+	SMTPPassword string `yaml:"smtpPassword"`
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error
+	Level string `yaml:"level"`
+	// Format is text or json
+	Format string `yaml:"format"`
+}
+
+// TracingConfig selects where OpenTelemetry spans are sent
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp
+	Exporter string `yaml:"exporter"`
+	// OTLPEndpoint is the collector's OTLP/HTTP URL. When empty the exporter
+	// falls back to the standard OTEL_EXPORTER_OTLP_* variables.
+	OTLPEndpoint string `yaml:"otlpEndpoint"`
+	// SampleRatio is the fraction of new traces recorded; requests that
+	// arrive with a sampled parent are always recorded
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// Default returns the configuration used for local development
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "projectivity_user",
+			Name:            "projectivity",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+		},
+		Mail: MailConfig{
+			From:      "Projectivity <no-reply@projectivity.local>",
+			OutboxDir: "outbox",
+			SMTPPort:  587,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		AppBaseURL: "http://localhost:3000",
+	}
+}
+
+// Load builds the configuration from defaults, the file named by CONFIG_FILE
+// and the environment, and validates the result
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer f.Close()
+
+	// Reject unknown keys so a typo doesn't silently fall back to a default
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server port %d is out of range", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server read timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server write timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server idle timeout must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server shutdown timeout must be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validProxy(proxy), "invalid trusted proxy %q", proxy)
+	}
+
+	db := c.Database
+	if db.URL != "" {
+		u, err := url.Parse(db.URL)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"),
+			"database URL must be a postgres:// or postgresql:// URL")
+	} else {
+		check(db.Host != "", "database host is required when no database URL is set")
+		check(db.Port > 0 && db.Port <= 65535, "database port %d is out of range", db.Port)
+		check(db.User != "", "database user is required when no database URL is set")
+		check(db.Name != "", "database name is required when no database URL is set")
+		check(oneOf(db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+			"unknown database sslmode %q", db.SSLMode)
+	}
+	check(db.MaxOpenConns > 0, "database max open connections must be positive")
+	check(db.MaxIdleConns >= 0 && db.MaxIdleConns <= db.MaxOpenConns,
+		"database max idle connections must be between 0 and max open connections")
+	check(db.ConnMaxLifetime >= 0, "database connection max lifetime cannot be negative")
+	check(db.ConnectTimeout > 0, "database connect timeout must be positive")
+
+	check(c.JWT.SigningKeyFile != "" || c.JWT.DevEphemeralKey,
+		"a JWT signing key file is required (or enable the ephemeral development key)")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "at least one CORS origin is required")
+
+	check(c.Mail.From != "", "mail from address is required")
+	if c.Mail.SMTPHost != "" {
+		check(c.Mail.SMTPPort > 0 && c.Mail.SMTPPort <= 65535, "SMTP port %d is out of range", c.Mail.SMTPPort)
+	} else {
+		check(c.Mail.OutboxDir != "", "mail outbox directory is required when no SMTP host is set")
+	}
+
+	check(oneOf(c.Log.Level, "debug", "info", "warn", "error"), "unknown log level %q", c.Log.Level)
+	check(oneOf(c.Log.Format, "text", "json"), "unknown log format %q", c.Log.Format)
+
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "otlp"), "unknown tracing exporter %q", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing sample ratio must be between 0 and 1")
+
+	check(c.AppBaseURL != "", "app base URL is required")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validProxy accepts a CIDR range or a single IP address
+func validProxy(proxy string) bool {
+	if _, _, err := net.ParseCIDR(proxy); err == nil {
+		return true
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envMap is a lookupFunc over a fixed set of variables
+func envMap(vars map[string]string) lookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrecedence(t *testing.T) {
+	file := "server:\n  port: 9000\n  readTimeout: 1m\nlog:\n  level: debug\n"
+
+	tests := []struct {
+		name        string
+		file        string
+		env         map[string]string
+		wantPort    int
+		wantTimeout time.Duration
+		wantLevel   string
+	}{
+		{"defaults", "", nil, 8080, 15 * time.Second, "info"},
+		{"file over defaults", file, nil, 9000, time.Minute, "debug"},
+		{"env over defaults", "", map[string]string{"PORT": "9100", "LOG_LEVEL": "warn"}, 9100, 15 * time.Second, "warn"},
+		{"env over file", file, map[string]string{"PORT": "9100", "HTTP_READ_TIMEOUT": "2m"}, 9100, 2 * time.Minute, "debug"},
+		{"empty env keeps file", file, map[string]string{"PORT": "", "LOG_LEVEL": "  "}, 9000, time.Minute, "debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if tt.file != "" {
+				if err := cfg.loadFile(writeConfigFile(t, tt.file)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := cfg.loadEnv(envMap(tt.env)); err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Server.Port != tt.wantPort || cfg.Server.ReadTimeout != tt.wantTimeout || cfg.Log.Level != tt.wantLevel {
+				t.Errorf("port %d, read timeout %s, log level %s; want %d, %s, %s",
+					cfg.Server.Port, cfg.Server.ReadTimeout, cfg.Log.Level, tt.wantPort, tt.wantTimeout, tt.wantLevel)
+			}
+			// Settings neither source mentions keep their defaults
+			if cfg.Database.Host != "localhost" {
+				t.Errorf("database host = %q, want the default", cfg.Database.Host)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfigFile(t, "server:\n  port: 9000\nlog:\n  level: debug\njwt:\n  devEphemeralKey: true\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("PORT", "9100")
+	// Empty variables are ignored, so these can't mask the file
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("JWT_DEV_EPHEMERAL_KEY", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 9100 || cfg.Log.Level != "debug" || !cfg.JWT.DevEphemeralKey {
+		t.Errorf("port %d, log level %s, ephemeral key %v; want 9100 from the environment and the rest from the file",
+			cfg.Server.Port, cfg.Log.Level, cfg.JWT.DevEphemeralKey)
+	}
+
+	t.Setenv("PORT", "0")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "server port 0 is out of range") {
+		t.Errorf("Load with an invalid port: err = %v", err)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	tests := []struct {
+		key, value string
+		field      func(c *Config) interface{}
+		want       interface{}
+	}{
+		{"PORT", "9000", func(c *Config) interface{} { return c.Server.Port }, 9000},
+		{"HTTP_READ_TIMEOUT", "90s", func(c *Config) interface{} { return c.Server.ReadTimeout }, 90 * time.Second},
+		{"DB_CONN_MAX_LIFETIME", " 1h30m ", func(c *Config) interface{} { return c.Database.ConnMaxLifetime }, 90 * time.Minute},
+		{"DB_AUTO_MIGRATE", "true", func(c *Config) interface{} { return c.Database.AutoMigrate }, true},
+		{"DB_AUTO_MIGRATE", "1", func(c *Config) interface{} { return c.Database.AutoMigrate }, true},
+		{"JWT_DEV_EPHEMERAL_KEY", "FALSE", func(c *Config) interface{} { return c.JWT.DevEphemeralKey }, false},
+		{"TRACING_SAMPLE_RATIO", "0.25", func(c *Config) interface{} { return c.Tracing.SampleRatio }, 0.25},
+		{"CORS_ALLOWED_ORIGINS", "https://a.test", func(c *Config) interface{} { return c.CORS.AllowedOrigins }, []string{"https://a.test"}},
+		{"CORS_ALLOWED_ORIGINS", " https://a.test , ,https://b.test,", func(c *Config) interface{} { return c.CORS.AllowedOrigins },
+			[]string{"https://a.test", "https://b.test"}},
+		{"TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.1", func(c *Config) interface{} { return c.Server.TrustedProxies },
+			[]string{"10.0.0.0/8", "192.168.1.1"}},
+		{"DATABASE_URL", " postgres://db.test/app ", func(c *Config) interface{} { return c.Database.URL }, "postgres://db.test/app"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		if err := cfg.loadEnv(envMap(map[string]string{tt.key: tt.value})); err != nil {
+			t.Errorf("%s=%q: %v", tt.key, tt.value, err)
+			continue
+		}
+		if got := tt.field(cfg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s=%q: got %v, want %v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEnvInvalid(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"PORT", "eighty"},
+		{"HTTP_WRITE_TIMEOUT", "30"},
+		{"DB_CONNECT_TIMEOUT", "soon"},
+		{"DB_AUTO_MIGRATE", "yes"},
+		{"DB_MAX_OPEN_CONNS", "1.5"},
+		{"TRACING_SAMPLE_RATIO", "half"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		err := cfg.loadEnv(envMap(map[string]string{tt.key: tt.value}))
+		if want := `invalid value "` + tt.value + `" for ` + tt.key; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s=%q: err = %v, want %q", tt.key, tt.value, err, want)
+		}
+	}
+
+	// Only the first bad variable is reported
+	cfg := Default()
+	err := cfg.loadEnv(envMap(map[string]string{"PORT": "x", "LOG_LEVEL": "debug", "TRACING_SAMPLE_RATIO": "y"}))
+	if err == nil || !strings.Contains(err.Error(), "PORT") || strings.Contains(err.Error(), "TRACING_SAMPLE_RATIO") {
+		t.Errorf("err = %v, want only the PORT error", err)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	tests := []struct {
+		name, body, wantErr string
+	}{
+		{"unknown key", "server:\n  prot: 9000\n", "field prot not found"},
+		{"bad duration", "server:\n  readTimeout: soon\n", "failed to parse config file"},
+		{"wrong type", "server:\n  port: high\n", "failed to parse config file"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		if err := cfg.loadFile(writeConfigFile(t, tt.body)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	cfg := Default()
+	if err := cfg.loadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("loadFile accepted a missing file")
+	}
+	if err := cfg.loadFile(writeConfigFile(t, "")); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestExampleConfig(t *testing.T) {
+	cfg := Default()
+	if err := cfg.loadFile("../../config.example.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(c *Config)
+		wantErr string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"port", func(c *Config) { c.Server.Port = 70000 }, "server port 70000 is out of range"},
+		{"timeout", func(c *Config) { c.Server.ShutdownTimeout = 0 }, "server shutdown timeout must be positive"},
+		{"proxy", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/33"} }, `invalid trusted proxy "10.0.0.0/33"`},
+		{"database URL", func(c *Config) { c.Database.URL = "mysql://db.test/app" }, "database URL must be a postgres:// or postgresql:// URL"},
+		{"database URL skips fields", func(c *Config) { c.Database.URL = "postgres://db.test/app"; c.Database.Host = "" }, ""},
+		{"database host", func(c *Config) { c.Database.Host = "" }, "database host is required"},
+		{"sslmode", func(c *Config) { c.Database.SSLMode = "always" }, `unknown database sslmode "always"`},
+		{"idle connections", func(c *Config) { c.Database.MaxIdleConns = 30 }, "database max idle connections must be between 0 and max open connections"},
+		{"signing key", func(c *Config) { c.JWT.DevEphemeralKey = false }, "a JWT signing key file is required"},
+		{"CORS", func(c *Config) { c.CORS.AllowedOrigins = nil }, "at least one CORS origin is required"},
+		{"SMTP port", func(c *Config) { c.Mail.SMTPHost = "smtp.test"; c.Mail.SMTPPort = 0 }, "SMTP port 0 is out of range"},
+		{"outbox", func(c *Config) { c.Mail.OutboxDir = "" }, "mail outbox directory is required"},
+		{"log level", func(c *Config) { c.Log.Level = "trace" }, `unknown log level "trace"`},
+		{"tracing exporter", func(c *Config) { c.Tracing.Exporter = "jaeger" }, `unknown tracing exporter "jaeger"`},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "tracing sample ratio must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.JWT.DevEphemeralKey = true
+		tt.change(cfg)
+
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Every problem is reported at once
+	cfg := Default()
+	cfg.Server.Port = 0
+	cfg.Log.Format = "xml"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server port 0") || !strings.Contains(err.Error(), `unknown log format "xml"`) ||
+		!strings.Contains(err.Error(), "JWT signing key") {
+		t.Errorf("err = %v, want the port, log format and signing key problems", err)
+	}
+}
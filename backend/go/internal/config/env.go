@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lookupFunc matches os.LookupEnv
+type lookupFunc func(key string) (string, bool)
+
+// loadEnv overrides settings with any of the supported environment variables
+// that are set
+func (c *Config) loadEnv(lookup lookupFunc) error {
+	e := envReader{lookup: lookup}
+
+	e.int("PORT", &c.Server.Port)
+	e.duration("HTTP_READ_TIMEOUT", &c.Server.ReadTimeout)
+	e.duration("HTTP_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	e.duration("HTTP_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	e.duration("HTTP_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	e.list("TRUSTED_PROXIES", &c.Server.TrustedProxies)
+
+	e.string("DATABASE_URL", &c.Database.URL)
+	e.string("DB_HOST", &c.Database.Host)
+	e.int("DB_PORT", &c.Database.Port)
+	e.string("DB_USER", &c.Database.User)
+	e.string("DB_PASSWORD", &c.Database.Password)
+	e.string("DB_NAME", &c.Database.Name)
+	e.string("DB_SSLMODE", &c.Database.SSLMode)
+	e.int("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	e.int("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	e.duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	e.duration("DB_CONNECT_TIMEOUT", &c.Database.ConnectTimeout)
+	e.bool("DB_AUTO_MIGRATE", &c.Database.AutoMigrate)
+
+	e.string("JWT_SIGNING_KEY_FILE", &c.JWT.SigningKeyFile)
+	e.string("JWT_VERIFICATION_KEYS_DIR", &c.JWT.VerificationKeysDir)
+	e.bool("JWT_DEV_EPHEMERAL_KEY", &c.JWT.DevEphemeralKey)
+
+	e.list("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+
+	e.string("MAIL_FROM", &c.Mail.From)
+	e.string("MAIL_OUTBOX_DIR", &c.Mail.OutboxDir)
+	e.string("SMTP_HOST", &c.Mail.SMTPHost)
+	e.int("SMTP_PORT", &c.Mail.SMTPPort)
+	e.string("SMTP_USERNAME", &c.Mail.SMTPUsername)
+	e.string("SMTP_PASSWORD", &c.Mail.SMTPPassword)
+
+	e.string("LOG_LEVEL", &c.Log.Level)
+	e.string("LOG_FORMAT", &c.Log.Format)
+
+	e.string("TRACING_EXPORTER", &c.Tracing.Exporter)
+	e.string("TRACING_OTLP_ENDPOINT", &c.Tracing.OTLPEndpoint)
+	e.float("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	e.string("APP_BASE_URL", &c.AppBaseURL)
+
+	return e.err
+}
+
+// envReader parses environment variables into config fields, keeping the
+// first error. Unset or empty variables leave the field unchanged.
+type envReader struct {
+	lookup lookupFunc
+	err    error
+}
+
+func (e *envReader) get(key string) (string, bool) {
+	value, ok := e.lookup(key)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+func (e *envReader) fail(key, value string, err error) {
+	if e.err == nil {
+		e.err = fmt.Errorf("invalid value %q for %s: %w", value, key, err)
+	}
+}
+
+func (e *envReader) string(key string, dst *string) {
+	if value, ok := e.get(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if value, ok := e.get(key); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	if value, ok := e.get(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = b
+	}
+}
+
+func (e *envReader) float(key string, dst *float64) {
+	if value, ok := e.get(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = f
+	}
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	if value, ok := e.get(key); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = d
+	}
+}
+
+// list reads a comma separated list
+func (e *envReader) list(key string, dst *[]string) {
+	if value, ok := e.get(key); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
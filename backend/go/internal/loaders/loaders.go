@@ -0,0 +1,114 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+	"github.com/vikstrous/dataloadgen"
+)
+
+type contextKey string
+
+const loadersKey contextKey = "loaders"
+
+// wait is how long a loader collects keys before fetching them. gqlgen
+// resolves the fields of list items concurrently, so a short wait is enough
+// to gather a whole list into one batch.
+const wait = 2 * time.Millisecond
+
+// ErrNotFound is wrapped by the error of a by-ID lookup whose row is missing
+var ErrNotFound = errors.New("not found")
+
+// Loaders batch and cache the lookups made by nested field resolvers during
+// a single request. Every lookup is keyed by an ID and costs one query per
+// batch, however many objects in the response ask for it.
+type Loaders struct {
+	UserByID         *dataloadgen.Loader[string, *model.User]
+	ProjectsByOwner  *dataloadgen.Loader[string, []*model.Project]
+	ProjectsByMember *dataloadgen.Loader[string, []*model.Project]
+	// TeamByProject loads the default team of a project, or nil
+	TeamByProject    *dataloadgen.Loader[string, *model.Team]
+	MembersByTeam    *dataloadgen.Loader[string, []*model.TeamMember]
+	MembersByProject *dataloadgen.Loader[string, []*model.TeamMember]
+}
+
+// New returns empty loaders. Results are cached for the lifetime of the
+// Loaders, so create them per request.
+func New(users *services.UserService, projects *services.ProjectService, teams *services.TeamService) *Loaders {
+	return &Loaders{
+		UserByID: dataloadgen.NewLoader(byID(users.GetUsersByIDs, func(u *model.User) string { return u.ID }, "user"),
+			dataloadgen.WithWait(wait)),
+		ProjectsByOwner:  dataloadgen.NewLoader(grouped(projects.GetProjectsByOwners), dataloadgen.WithWait(wait)),
+		ProjectsByMember: dataloadgen.NewLoader(grouped(users.GetProjectsByMembers), dataloadgen.WithWait(wait)),
+		TeamByProject:    dataloadgen.NewLoader(grouped(teams.GetDefaultTeams), dataloadgen.WithWait(wait)),
+		MembersByTeam:    dataloadgen.NewLoader(grouped(teams.GetTeamMembersByTeams), dataloadgen.WithWait(wait)),
+		MembersByProject: dataloadgen.NewLoader(grouped(projects.GetTeamMembersByProjects), dataloadgen.WithWait(wait)),
+	}
+}
+
+// Middleware gives every request its own loaders, so nothing is cached
+// across requests or users
+func Middleware(users *services.UserService, projects *services.ProjectService, teams *services.TeamService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), loadersKey, New(users, projects, teams))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// For returns the loaders of the current request. It panics if Middleware
+// is not installed, which is a wiring bug rather than a runtime condition.
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey).(*Loaders)
+}
+
+// byID adapts a lookup returning rows in any order to the order of the keys.
+// Keys without a row fail with a not found error.
+func byID[V any](fetch func(context.Context, []string) ([]V, error), id func(V) string, name string) func(context.Context, []string) ([]V, []error) {
+	return func(ctx context.Context, keys []string) ([]V, []error) {
+		rows, err := fetch(ctx, keys)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		found := make(map[string]V, len(rows))
+		for _, row := range rows {
+			found[id(row)] = row
+		}
+
+		results := make([]V, len(keys))
+		errs := make([]error, len(keys))
+		for i, key := range keys {
+			row, ok := found[key]
+			if !ok {
+				errs[i] = fmt.Errorf("%s %w", name, ErrNotFound)
+				continue
+			}
+			results[i] = row
+		}
+		return results, errs
+	}
+}
+
+// grouped adapts a lookup returning a map to the order of the keys. Keys
+// missing from the map get the zero value, an empty list or no team.
+func grouped[V any](fetch func(context.Context, []string) (map[string]V, error)) func(context.Context, []string) ([]V, []error) {
+	return func(ctx context.Context, keys []string) ([]V, []error) {
+		found, err := fetch(ctx, keys)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		results := make([]V, len(keys))
+		for i, key := range keys {
+			results[i] = found[key]
+		}
+		return results, nil
+	}
+}
@@ -0,0 +1,317 @@
+package loaders_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/evan3v4n/Projectivity/backend/go/graph"
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/loaders"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/memory"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+)
+
+// calls counts the lookups nested fields can trigger
+type calls struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+func (c *calls) add(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count[name]++
+}
+
+func (c *calls) reset() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := c.count
+	c.count = map[string]int{}
+	return seen
+}
+
+// countingStore wraps a store and counts calls to the per-ID and batched
+// lookups used when resolving nested fields
+type countingStore struct {
+	repository.Store
+	calls *calls
+}
+
+func (s countingStore) Users() repository.UserRepository {
+	return countingUsers{s.Store.Users(), s.calls}
+}
+
+func (s countingStore) Projects() repository.ProjectRepository {
+	return countingProjects{s.Store.Projects(), s.calls}
+}
+
+func (s countingStore) Teams() repository.TeamRepository {
+	return countingTeams{s.Store.Teams(), s.calls}
+}
+
+type countingUsers struct {
+	repository.UserRepository
+	calls *calls
+}
+
+func (r countingUsers) GetByID(ctx context.Context, id string) (*model.User, error) {
+	r.calls.add("Users.GetByID")
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func (r countingUsers) Role(ctx context.Context, id string) (model.UserRole, error) {
+	r.calls.add("Users.Role")
+	return r.UserRepository.Role(ctx, id)
+}
+
+func (r countingUsers) SuspendedAt(ctx context.Context, id string) (*string, error) {
+	r.calls.add("Users.SuspendedAt")
+	return r.UserRepository.SuspendedAt(ctx, id)
+}
+
+func (r countingUsers) ListByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	r.calls.add("Users.ListByIDs")
+	return r.UserRepository.ListByIDs(ctx, ids)
+}
+
+type countingProjects struct {
+	repository.ProjectRepository
+	calls *calls
+}
+
+func (r countingProjects) List(ctx context.Context, filter repository.ProjectFilter, sort model.ProjectSort, limit, offset int) ([]*model.Project, error) {
+	r.calls.add("Projects.List")
+	return r.ProjectRepository.List(ctx, filter, sort, limit, offset)
+}
+
+func (r countingProjects) ListByOwner(ctx context.Context, ownerID string, viewer repository.Viewer) ([]*model.Project, error) {
+	r.calls.add("Projects.ListByOwner")
+	return r.ProjectRepository.ListByOwner(ctx, ownerID, viewer)
+}
+
+func (r countingProjects) ListByOwners(ctx context.Context, ownerIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	r.calls.add("Projects.ListByOwners")
+	return r.ProjectRepository.ListByOwners(ctx, ownerIDs, viewer)
+}
+
+func (r countingProjects) ListByMember(ctx context.Context, userID string, viewer repository.Viewer) ([]*model.Project, error) {
+	r.calls.add("Projects.ListByMember")
+	return r.ProjectRepository.ListByMember(ctx, userID, viewer)
+}
+
+func (r countingProjects) ListByMembers(ctx context.Context, userIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	r.calls.add("Projects.ListByMembers")
+	return r.ProjectRepository.ListByMembers(ctx, userIDs, viewer)
+}
+
+type countingTeams struct {
+	repository.TeamRepository
+	calls *calls
+}
+
+func (r countingTeams) ByProject(ctx context.Context, projectID string) ([]*model.Team, error) {
+	r.calls.add("Teams.ByProject")
+	return r.TeamRepository.ByProject(ctx, projectID)
+}
+
+func (r countingTeams) Members(ctx context.Context, teamID string) ([]*model.TeamMember, error) {
+	r.calls.add("Teams.Members")
+	return r.TeamRepository.Members(ctx, teamID)
+}
+
+func (r countingTeams) ProjectMembers(ctx context.Context, projectID string) ([]*model.TeamMember, error) {
+	r.calls.add("Teams.ProjectMembers")
+	return r.TeamRepository.ProjectMembers(ctx, projectID)
+}
+
+func (r countingTeams) DefaultByProjects(ctx context.Context, projectIDs []string) (map[string]*model.Team, error) {
+	r.calls.add("Teams.DefaultByProjects")
+	return r.TeamRepository.DefaultByProjects(ctx, projectIDs)
+}
+
+func (r countingTeams) MembersByTeams(ctx context.Context, teamIDs []string) (map[string][]*model.TeamMember, error) {
+	r.calls.add("Teams.MembersByTeams")
+	return r.TeamRepository.MembersByTeams(ctx, teamIDs)
+}
+
+func (r countingTeams) MembersByProjects(ctx context.Context, projectIDs []string) (map[string][]*model.TeamMember, error) {
+	r.calls.add("Teams.MembersByProjects")
+	return r.TeamRepository.MembersByProjects(ctx, projectIDs)
+}
+
+// testServer serves the GraphQL schema over a counting in-memory store
+type testServer struct {
+	handler  http.Handler
+	calls    *calls
+	users    *services.UserService
+	projects *services.ProjectService
+}
+
+func newTestServer() *testServer {
+	c := &calls{count: map[string]int{}}
+	store := countingStore{memory.NewStore(), c}
+	users := services.NewUserService(store, nil, "http://app.test/")
+	projects := services.NewProjectService(store, users)
+	teams := services.NewTeamService(store)
+
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
+		Resolvers: &graph.Resolver{
+			Store:          store,
+			UserService:    users,
+			ProjectService: projects,
+			TeamService:    teams,
+		},
+		Directives: graph.NewDirectiveRoot(store),
+	}))
+	return &testServer{
+		handler:  loaders.Middleware(users, projects, teams)(srv),
+		calls:    c,
+		users:    users,
+		projects: projects,
+	}
+}
+
+// seed creates n projects, each with its own owner and one more member
+func (s *testServer) seed(t *testing.T, n int) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 0; i < n; i++ {
+		var ids [2]string
+		for j, role := range []string{"owner", "member"} {
+			user := &model.User{
+				Username:  fmt.Sprintf("%s%d", role, i),
+				Email:     fmt.Sprintf("%s%d@example.com", role, i),
+				FirstName: "Test",
+				LastName:  role,
+				Skills:    []string{},
+			}
+			if err := s.users.Store.Users().Create(ctx, user, []byte("hash")); err != nil {
+				t.Fatal(err)
+			}
+			ids[j] = user.ID
+		}
+
+		project, err := s.projects.CreateProject(ctx, model.CreateProjectInput{
+			Title:              fmt.Sprintf("Project %d", i),
+			Description:        "Seeded",
+			Category:           "Web",
+			Technologies:       []string{"go"},
+			OpenPositions:      2,
+			TimeCommitment:     "5h/week",
+			LearningObjectives: []string{},
+		}, ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.projects.AddTeamMember(ctx, project.ID, ids[1], "Member"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.calls.reset()
+}
+
+const projectsQuery = `{
+  projects(limit: 50) {
+    title
+    owner { username firstName role suspendedAt projects { title } ownedProjects { title } }
+    team { name members { role user { username role } } }
+    teamMembers { role user { username role } }
+  }
+}`
+
+type projectsResponse struct {
+	Data struct {
+		Projects []struct {
+			Title string
+			Owner struct {
+				Username      string
+				FirstName     string
+				Role          string
+				Projects      []struct{ Title string }
+				OwnedProjects []struct{ Title string }
+			}
+			Team struct {
+				Name    string
+				Members []struct{ Role string }
+			}
+			TeamMembers []struct{ Role string }
+		}
+	}
+	Errors []struct{ Message string }
+}
+
+func (s *testServer) query(t *testing.T) projectsResponse {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"query": projectsQuery})
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	var resp projectsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body, err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query failed: %v", resp.Errors)
+	}
+	return resp
+}
+
+func TestNestedFieldsAreBatched(t *testing.T) {
+	var perSize []map[string]int
+	for _, n := range []int{3, 6} {
+		s := newTestServer()
+		s.seed(t, n)
+
+		resp := s.query(t)
+		if len(resp.Data.Projects) != n {
+			t.Fatalf("got %d projects, want %d", len(resp.Data.Projects), n)
+		}
+		for _, p := range resp.Data.Projects {
+			if p.Owner.FirstName != "Test" || p.Owner.Role != "USER" || len(p.Owner.Projects) != 1 || len(p.Owner.OwnedProjects) != 1 {
+				t.Errorf("%s: incomplete owner %+v", p.Title, p.Owner)
+			}
+			if p.Team.Name != p.Title+" Team" || len(p.Team.Members) != 2 || len(p.TeamMembers) != 2 {
+				t.Errorf("%s: team %+v, members %+v", p.Title, p.Team, p.TeamMembers)
+			}
+		}
+		perSize = append(perSize, s.calls.reset())
+	}
+
+	for name, count := range perSize[1] {
+		if count != 1 {
+			t.Errorf("%s called %d times for 6 projects, want once", name, count)
+		}
+		if perSize[0][name] != count {
+			t.Errorf("%s called %d times for 3 projects and %d for 6", name, perSize[0][name], count)
+		}
+	}
+	for _, single := range []string{"Users.GetByID", "Users.Role", "Users.SuspendedAt", "Teams.ByProject", "Teams.Members", "Teams.ProjectMembers"} {
+		if perSize[1][single] != 0 {
+			t.Errorf("%s was used instead of a batch", single)
+		}
+	}
+}
+
+func TestLoadersArePerRequest(t *testing.T) {
+	s := newTestServer()
+	s.seed(t, 2)
+
+	s.query(t)
+	first := s.calls.reset()
+	s.query(t)
+	if second := s.calls.reset(); fmt.Sprint(second) != fmt.Sprint(first) {
+		t.Errorf("second request made %v lookups, first %v; results leaked across requests", second, first)
+	}
+}
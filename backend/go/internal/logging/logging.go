@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "requestID"
+	operationKey contextKey = "operation"
+)
+
+// WithRequestID stores the ID of the HTTP request being served
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// GetRequestID returns the ID set by the RequestID middleware, if any
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithOperation stores the name of the GraphQL operation being executed
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey, name)
+}
+
+// GetOperation returns the GraphQL operation name, if any
+func GetOperation(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey).(string)
+	return name
+}
+
+// New returns a logger writing to w at the given level ("debug", "info",
+// "warn" or "error") as "json" or "text". Sensitive values are redacted and
+// records logged with a context carry its request ID, user ID, GraphQL
+// operation and trace ID.
+func New(w io.Writer, level, format string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// contextHandler adds request-scoped attributes from the context to every
+// record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := GetRequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if userID, err := auth.GetUserIDFromContext(ctx); err == nil {
+			r.AddAttrs(slog.String("user_id", userID))
+		}
+		if op := GetOperation(ctx); op != "" {
+			r.AddAttrs(slog.String("operation", op))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,152 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// decode returns the JSON records written to buf, one per line
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "info", "json")
+
+	logger.Info("Sending invite to alice@example.com",
+		"password", "hunter2",
+		"refresh_token", "abc",
+		"email", "bob@example.com",
+		"email_changed", true,
+		"header", "Bearer eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln",
+		"error", errors.New("token pat_0123456789abcdef rejected"),
+		"project_id", "p1",
+	)
+
+	record := decode(t, &buf)[0]
+	want := map[string]any{
+		"msg":           "Sending invite to [email]",
+		"password":      "[REDACTED]",
+		"refresh_token": "[REDACTED]",
+		"email":         "[REDACTED]",
+		"email_changed": true,
+		"header":        "[token]",
+		"error":         "token [token] rejected",
+		"project_id":    "p1",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
+func TestContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "debug", "json")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = auth.SetUserID(ctx, "user-1")
+	ctx = WithOperation(ctx, "CreateProject")
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.DebugContext(ctx, "Executing")
+	logger.Info("No context")
+
+	records := decode(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for key, value := range map[string]string{
+		"request_id": "req-1",
+		"user_id":    "user-1",
+		"operation":  "CreateProject",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+	} {
+		if records[0][key] != value {
+			t.Errorf("%s = %v, want %s", key, records[0][key], value)
+		}
+		if _, ok := records[1][key]; ok {
+			t.Errorf("record logged without context has %s", key)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "warn", "text")
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	if out := buf.String(); strings.Contains(out, "hidden") || !strings.Contains(out, "shown") {
+		t.Errorf("unexpected output for level warn: %q", out)
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(New(&buf, "info", "json"))
+
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		reused   bool
+	}{
+		{"generated", "", false},
+		{"reused", "lb-42.abc", true},
+		{"invalid replaced", "bad id\nwith newline", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/query", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if id == "" || id != seen {
+				t.Fatalf("response ID %q, handler saw %q", id, seen)
+			}
+			if (id == tt.incoming) != tt.reused {
+				t.Errorf("ID %q, incoming %q, want reused %v", id, tt.incoming, tt.reused)
+			}
+
+			record := decode(t, &buf)[0]
+			if record["request_id"] != id || record["status"] != float64(http.StatusTeapot) {
+				t.Errorf("unexpected access log %v", record)
+			}
+		})
+	}
+}
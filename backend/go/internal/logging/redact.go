@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// JWTs, personal access tokens and bearer credentials
+	tokenPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*|pat_[A-Za-z0-9_\-]+|(?i:bearer)\s+\S+`)
+)
+
+// sensitiveKey reports whether an attribute holds a credential or personal
+// data and must never be logged
+func sensitiveKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	switch {
+	case strings.Contains(k, "password"),
+		strings.Contains(k, "secret"),
+		strings.HasSuffix(k, "token"),
+		strings.HasSuffix(k, "email"),
+		k == "authorization", k == "cookie", k == "code":
+		return true
+	}
+	return false
+}
+
+// Redact masks email addresses and tokens that appear in free text
+func Redact(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[email]")
+	return tokenPattern.ReplaceAllString(s, "[token]")
+}
+
+// redactAttr is the handlers' ReplaceAttr hook. It also sees the message,
+// so text logged through the standard log package is scrubbed as well.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if sensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case slog.KindAny:
+		// Errors and other values are logged by their text
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "projectivity"
+
+// Registry holds every metric served on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	OperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_operation_duration_seconds",
+		Help:      "Duration of GraphQL operations by root field.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	OperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_operation_errors_total",
+		Help:      "GraphQL operations that returned at least one error, by root field.",
+	}, []string{"operation"})
+
+	ResolverDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "graphql_resolver_duration_seconds",
+		Help:      "Duration of GraphQL field resolvers by parent type and field.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"object", "field"})
+
+	ResolverErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "graphql_resolver_errors_total",
+		Help:      "GraphQL field resolvers that returned an error.",
+	}, []string{"object", "field"})
+
+	ProjectsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "projects_created_total",
+		Help:      "Projects created.",
+	})
+
+	JoinRequestsSubmitted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "join_requests_submitted_total",
+		Help:      "Requests to join a project.",
+	})
+
+	JoinRequestsDecided = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "join_requests_decided_total",
+		Help:      "Join requests approved or rejected by the project owner.",
+	}, []string{"status"})
+
+	TasksCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_completed_total",
+		Help:      "Tasks moved to DONE.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		OperationDuration,
+		OperationErrors,
+		ResolverDuration,
+		ResolverErrors,
+		ProjectsCreated,
+		JoinRequestsSubmitted,
+		JoinRequestsDecided,
+		TasksCompleted,
+	)
+}
+
+var (
+	dbMu        sync.Mutex
+	dbCollector prometheus.Collector
+)
+
+// RegisterDB exports the connection pool statistics of db, replacing the
+// pool registered before
+func RegisterDB(db *sql.DB) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+
+	if dbCollector != nil {
+		Registry.Unregister(dbCollector)
+	}
+	dbCollector = collectors.NewDBStatsCollector(db, "projectivity")
+	Registry.MustRegister(dbCollector)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/projects/1", "/projects/2", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if n := testutil.CollectAndCount(HTTPRequestDuration); n != 2 {
+		t.Errorf("got %d series, want one per route", n)
+	}
+	body := scrape(t)
+	for _, line := range []string{
+		`projectivity_http_request_duration_seconds_count{method="GET",route="/projects/{id}",status="404"} 2`,
+		`projectivity_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("missing %s", line)
+		}
+	}
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+// stubDriver lets sql.Open succeed without a database; pool statistics are
+// available without ever connecting
+type stubDriver struct{}
+
+func (stubDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+
+func init() {
+	sql.Register("metrics-stub", stubDriver{})
+}
+
+func TestRegisterDBReplacesPool(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		db, err := sql.Open("metrics-stub", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(25)
+		RegisterDB(db)
+	}
+
+	if body := scrape(t); !strings.Contains(body, `go_sql_max_open_connections{db_name="projectivity"} 25`) {
+		t.Errorf("pool statistics missing from:\n%s", body)
+	}
+}
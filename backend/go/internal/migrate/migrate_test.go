@@ -0,0 +1,405 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// testDatabaseEnv names the variable holding the DSN of a local Postgres the
+// tests may create throwaway schemas in, as for the end-to-end tests
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+// sqlSources are the packages whose queries the migrations have to support
+var sqlSources = []string{"../repository/postgres", "../audit"}
+
+var (
+	createTable = regexp.MustCompile(`(?i)\bCREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([a-z_][a-z0-9_]*)`)
+	// tableRef only matches upper-case keywords, as queries here are written,
+	// so prose such as "failed to delete from ..." in messages is ignored
+	tableRef = regexp.MustCompile(`\b(?:FROM|JOIN|INTO|UPDATE)\s+([a-z_][a-z0-9_]*)\b(\s*\()?`)
+	// notTables follow FROM, JOIN or UPDATE in queries without naming a table
+	notTables = map[string]bool{"lateral": true, "set": true}
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr string
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"0002_b.up.sql":   {Data: []byte("B")},
+				"0002_b.down.sql": {Data: []byte("-B")},
+				"0001_a.up.sql":   {Data: []byte("A")},
+				"0001_a.down.sql": {Data: []byte("-A")},
+				"README.md":       {Data: []byte("not a migration")},
+			},
+			want: []int64{1, 2},
+		},
+		{
+			name:    "badly named",
+			files:   fstest.MapFS{"1-init.sql": {Data: []byte("A")}},
+			wantErr: "migration file 1-init.sql is not named like 0001_name.up.sql",
+		},
+		{
+			name:    "missing down",
+			files:   fstest.MapFS{"0001_a.up.sql": {Data: []byte("A")}},
+			wantErr: "migration 0001_a needs both an up and a down file",
+		},
+		{
+			name: "version reused",
+			files: fstest.MapFS{
+				"0001_a.up.sql":   {Data: []byte("A")},
+				"0001_b.down.sql": {Data: []byte("-B")},
+			},
+			wantErr: "migration version 1 is used by both a and b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, m := range migrations {
+				got = append(got, m.Version)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_init.up.sql", "0001_init.down.sql"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("--"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	up, down, err := Create(dir, "  Add Widgets! ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(up) != "0002_add_widgets.up.sql" || filepath.Base(down) != "0002_add_widgets.down.sql" {
+		t.Errorf("created %s and %s", up, down)
+	}
+
+	if _, _, err := Create(dir, "!!!"); err == nil {
+		t.Error("Create accepted a name without letters or digits")
+	}
+}
+
+// TestMigrationsCreateQueriedTables guards against shipping queries against
+// a table no migration creates
+func TestMigrationsCreateQueriedTables(t *testing.T) {
+	created := map[string]bool{"migrations": true}
+	entries, err := embedded.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		body, err := embedded.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, match := range createTable.FindAllStringSubmatch(string(body), -1) {
+			created[strings.ToLower(match[1])] = true
+		}
+	}
+
+	queried := queriedTables(t)
+	if len(queried) == 0 {
+		t.Fatal("found no queries to check")
+	}
+	for table, file := range queried {
+		if !created[table] {
+			t.Errorf("%s queries table %s, which no migration creates", file, table)
+		}
+	}
+}
+
+// queriedTables returns the tables named in the SQL string literals of
+// sqlSources, each with a file that uses it
+func queriedTables(t *testing.T) map[string]string {
+	t.Helper()
+	tables := make(map[string]string)
+	for _, dir := range sqlSources {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				lit, ok := n.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				query, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return true
+				}
+				for _, match := range tableRef.FindAllStringSubmatch(query, -1) {
+					// Skip set-returning functions such as unnest(...)
+					name := strings.ToLower(match[1])
+					if match[2] != "" || notTables[name] {
+						continue
+					}
+					tables[name] = filepath.Base(path)
+				}
+				return true
+			})
+		}
+	}
+	return tables
+}
+
+// openTestSchema returns a connection to a fresh schema that is dropped when
+// the test ends, or skips the test if no database is configured
+func openTestSchema(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set; skipping migration test", testDatabaseEnv)
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("migrate_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	db, err := sql.Open("postgres", withSearchPath(dsn, schema+",public"))
+	if err != nil {
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, schema
+}
+
+// withSearchPath adds a search_path run-time parameter to a URL or
+// key=value DSN
+func withSearchPath(dsn, searchPath string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			q := u.Query()
+			q.Set("search_path", searchPath)
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + searchPath
+}
+
+// tablesIn lists the tables of a schema
+func tablesIn(t *testing.T, db *sql.DB, schema string) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT table_name FROM information_schema.tables WHERE table_schema = $1 ORDER BY table_name`, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return tables
+}
+
+func TestUpDown(t *testing.T) {
+	db, schema := openTestSchema(t)
+	ctx := context.Background()
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := len(migrator.migrations)
+
+	// Pending only reads, even before anything has been applied
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != total {
+		t.Errorf("pending = %d, want all %d", len(pending), total)
+	}
+	if tables := tablesIn(t, db, schema); len(tables) != 0 {
+		t.Errorf("Pending created %v", tables)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != total {
+		t.Errorf("applied %d migrations, want %d", len(applied), total)
+	}
+	if pending, err := migrator.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("pending after Up = %d, %v, want none", len(pending), err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("migration %04d_%s is not recorded as applied", status.Version, status.Name)
+		}
+	}
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Errorf("second Up applied %d, %v, want nothing", len(again), err)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || reverted[0].Version != migrator.migrations[total-1].Version {
+		t.Errorf("Down(1) reverted %+v, want the latest migration", reverted)
+	}
+
+	// Reverting everything leaves only the bookkeeping table behind
+	if reverted, err := migrator.Down(ctx, total); err != nil || len(reverted) != total-1 {
+		t.Fatalf("Down(%d) reverted %d, %v, want %d", total, len(reverted), err, total-1)
+	}
+	if tables := tablesIn(t, db, schema); fmt.Sprint(tables) != "[migrations]" {
+		t.Errorf("tables after reverting everything = %v, want only migrations", tables)
+	}
+
+	// The down migrations must undo enough for the up migrations to run again
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != total {
+		t.Errorf("Up after Down applied %d, %v, want %d", len(applied), err, total)
+	}
+}
+
+func TestUpWaitsForLock(t *testing.T) {
+	db, _ := openTestSchema(t)
+	ctx := context.Background()
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		t.Fatal(err)
+	}
+
+	// While another session holds the lock, Up blocks until it gives up
+	short, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := migrator.Up(short); err == nil {
+		t.Fatal("Up ran while another session held the migration lock")
+	}
+	if pending, err := migrator.Pending(ctx); err != nil || len(pending) != len(migrator.migrations) {
+		t.Errorf("pending = %d, %v, want everything left pending", len(pending), err)
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Errorf("Up after the lock was released: %v", err)
+	}
+}
+
+func TestConcurrentUpAppliesEachMigrationOnce(t *testing.T) {
+	db, _ := openTestSchema(t)
+	ctx := context.Background()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		applied int
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrator, err := New(db)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			migrations, err := migrator.Up(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			applied += len(migrations)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != len(migrator.migrations) {
+		t.Errorf("applied %d migrations in total, want each of the %d once", applied, len(migrator.migrations))
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS join_requests;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS project_owners;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
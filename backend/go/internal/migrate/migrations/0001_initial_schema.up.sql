@@ -0,0 +1,109 @@
+-- Core schema as it existed before migrations were introduced. Every
+-- statement is guarded with IF NOT EXISTS so that running it against a
+-- database created by hand only records the version.
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS users (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username            TEXT NOT NULL UNIQUE,
+    email               TEXT NOT NULL UNIQUE,
+    password_hash       TEXT NOT NULL,
+    first_name          TEXT NOT NULL DEFAULT '',
+    last_name           TEXT NOT NULL DEFAULT '',
+    bio                 TEXT,
+    profile_image_url   TEXT,
+    skills              TEXT[] NOT NULL DEFAULT '{}',
+    education_level     TEXT,
+    years_experience    INTEGER NOT NULL DEFAULT 0,
+    preferred_role      TEXT,
+    github_url          TEXT,
+    linkedin_url        TEXT,
+    portfolio_url       TEXT,
+    email_verified      BOOLEAN NOT NULL DEFAULT FALSE,
+    time_zone           TEXT,
+    available_hours     TEXT,
+    certifications      TEXT[],
+    languages           TEXT[],
+    project_preferences TEXT[],
+    last_active         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    joined_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title               TEXT NOT NULL,
+    description         TEXT NOT NULL DEFAULT '',
+    category            TEXT NOT NULL DEFAULT '',
+    status              TEXT NOT NULL,
+    technologies        TEXT[] NOT NULL DEFAULT '{}',
+    open_positions      INTEGER NOT NULL DEFAULT 0,
+    time_commitment     TEXT NOT NULL DEFAULT '',
+    popularity          INTEGER NOT NULL DEFAULT 0,
+    timeline            TEXT,
+    learning_objectives TEXT[] NOT NULL DEFAULT '{}',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_projects_category ON projects (category);
+
+-- Every project has exactly one owner
+CREATE TABLE IF NOT EXISTS project_owners (
+    project_id UUID PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_owners_user_id ON project_owners (user_id);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        TEXT NOT NULL,
+    description TEXT,
+    project_id  UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_project_id ON teams (project_id);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id   UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id   UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role      TEXT NOT NULL DEFAULT 'Member',
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members (user_id);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title       TEXT NOT NULL,
+    description TEXT,
+    status      TEXT NOT NULL,
+    priority    TEXT NOT NULL,
+    due_date    TIMESTAMPTZ,
+    project_id  UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    assignee_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks (assignee_id);
+
+CREATE TABLE IF NOT EXISTS join_requests (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status     TEXT NOT NULL DEFAULT 'PENDING',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_join_requests_project_id ON join_requests (project_id);
+CREATE INDEX IF NOT EXISTS idx_join_requests_user_id ON join_requests (user_id);
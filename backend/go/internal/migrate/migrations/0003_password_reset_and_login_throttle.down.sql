@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS login_throttle;
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,24 @@
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'USER' CHECK (role IN ('USER', 'ADMIN')),
+    ADD COLUMN suspended_at TIMESTAMPTZ,
+    ADD COLUMN suspension_reason TEXT;
+
+ALTER TABLE projects
+    ADD COLUMN hidden_at TIMESTAMPTZ,
+    ADD COLUMN hidden_reason TEXT;
+
+CREATE TABLE moderation_reports (
+    id              UUID PRIMARY KEY,
+    reporter_id     UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_type     TEXT NOT NULL,
+    -- Not a foreign key: the target may be a project or a user
+    target_id       TEXT NOT NULL,
+    reason          TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'OPEN',
+    resolved_by     UUID REFERENCES users (id) ON DELETE SET NULL,
+    resolution_note TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at     TIMESTAMPTZ
+);
+
+CREATE INDEX idx_moderation_reports_status ON moderation_reports (status, created_at);
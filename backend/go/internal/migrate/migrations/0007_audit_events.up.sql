@@ -0,0 +1,33 @@
+-- Actor, target and project ids are plain text rather than foreign keys so
+-- events outlive the rows they describe.
+CREATE TABLE audit_events (
+    id          UUID PRIMARY KEY,
+    action      TEXT NOT NULL,
+    actor_id    TEXT,
+    target_type TEXT NOT NULL,
+    target_id   TEXT NOT NULL,
+    project_id  TEXT,
+    ip_address  TEXT,
+    before      JSONB,
+    after       JSONB,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at DESC);
+CREATE INDEX idx_audit_events_project_id ON audit_events (project_id, created_at DESC);
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id, created_at DESC);
+
+-- The audit log is append-only
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
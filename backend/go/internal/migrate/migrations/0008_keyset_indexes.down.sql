@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_join_requests_project_created_at_id;
+DROP INDEX IF EXISTS idx_tasks_assignee_created_at_id;
+DROP INDEX IF EXISTS idx_tasks_project_created_at_id;
+DROP INDEX IF EXISTS idx_users_created_at_id;
+DROP INDEX IF EXISTS idx_projects_created_at_id;
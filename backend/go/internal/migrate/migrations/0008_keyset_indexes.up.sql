@@ -0,0 +1,7 @@
+-- Connections page through rows by (created_at, id), so each listing needs an
+-- index ending in those columns to seek straight to the cursor.
+CREATE INDEX idx_projects_created_at_id ON projects (created_at, id);
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_tasks_project_created_at_id ON tasks (project_id, created_at, id);
+CREATE INDEX idx_tasks_assignee_created_at_id ON tasks (assignee_id, created_at, id);
+CREATE INDEX idx_join_requests_project_created_at_id ON join_requests (project_id, created_at, id);
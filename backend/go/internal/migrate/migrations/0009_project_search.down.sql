@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_projects_search_text_trgm;
+DROP INDEX IF EXISTS idx_projects_search_vector;
+
+DROP TRIGGER IF EXISTS projects_search_document ON projects;
+DROP FUNCTION IF EXISTS projects_search_document();
+
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS search_text,
+    DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed; other objects may have come to use it
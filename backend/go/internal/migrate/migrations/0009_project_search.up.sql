@@ -0,0 +1,33 @@
+-- Project search reads a stored, weighted document instead of building one
+-- per row and query. search_text feeds the trigram index used to match
+-- misspelled titles, technologies and categories.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE projects
+    ADD COLUMN search_vector TSVECTOR NOT NULL DEFAULT ''::tsvector,
+    ADD COLUMN search_text TEXT NOT NULL DEFAULT '';
+
+CREATE FUNCTION projects_search_document() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', NEW.title), 'A') ||
+        setweight(to_tsvector('english', array_to_string(NEW.technologies, ' ')), 'B') ||
+        setweight(to_tsvector('english', NEW.category), 'B') ||
+        setweight(to_tsvector('english', NEW.description), 'C') ||
+        setweight(to_tsvector('english', array_to_string(NEW.learning_objectives, ' ')), 'D');
+    NEW.search_text :=
+        NEW.title || ' ' || array_to_string(NEW.technologies, ' ') || ' ' || NEW.category;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER projects_search_document
+    BEFORE INSERT OR UPDATE OF title, description, category, technologies, learning_objectives
+    ON projects
+    FOR EACH ROW EXECUTE FUNCTION projects_search_document();
+
+-- Fire the trigger for the existing rows
+UPDATE projects SET title = title;
+
+CREATE INDEX idx_projects_search_vector ON projects USING GIN (search_vector);
+CREATE INDEX idx_projects_search_text_trgm ON projects USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,157 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor is returned for cursors this server did not issue
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Args are the Relay connection arguments of a query
+type Args struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+	Order  model.ConnectionOrder
+}
+
+// Page holds the items of one page in display order with their cursors
+type Page[T any] struct {
+	Items    []T
+	Cursors  []string
+	PageInfo *model.PageInfo
+}
+
+// cursor is the JSON behind an opaque cursor
+type cursor struct {
+	CreatedAt string `json:"t"`
+	ID        string `json:"i"`
+}
+
+// EncodeCursor returns the opaque cursor of a position
+func EncodeCursor(c repository.Cursor) string {
+	data, _ := json.Marshal(cursor{CreatedAt: c.CreatedAt, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor
+func DecodeCursor(s string) (repository.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return repository.Cursor{}, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return repository.Cursor{}, ErrInvalidCursor
+	}
+	// Both parts end up as query parameters, so reject anything Postgres
+	// would fail to cast
+	if _, err := time.Parse(time.RFC3339Nano, c.CreatedAt); err != nil {
+		return repository.Cursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return repository.Cursor{}, ErrInvalidCursor
+	}
+	return repository.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}, nil
+}
+
+// Offset resolves the limit and offset arguments of a plain listing.
+// defaultLimit applies when no limit is given and larger limits are capped at
+// MaxPageSize; negative values are rejected.
+func Offset(limit, offset *int, defaultLimit int) (int, int, error) {
+	l, o := defaultLimit, 0
+	if limit != nil {
+		l = *limit
+	}
+	if offset != nil {
+		o = *offset
+	}
+	if l < 0 {
+		return 0, 0, errors.New("limit cannot be negative")
+	}
+	if o < 0 {
+		return 0, 0, errors.New("offset cannot be negative")
+	}
+	return min(l, MaxPageSize), o, nil
+}
+
+// Fetch loads the page selected by args. fetch is called once, for one row
+// more than the page size so the extra row tells whether more follow. Paging
+// backward fetches in the opposite order and reverses the result.
+func Fetch[T any](args Args, fetch func(repository.Page) ([]T, error), position func(T) repository.Cursor) (*Page[T], error) {
+	if args.First != nil && args.Last != nil {
+		return nil, errors.New("first and last cannot be used together")
+	}
+	if args.After != nil && args.Last != nil || args.Before != nil && args.First != nil ||
+		args.After != nil && args.Before != nil {
+		return nil, errors.New("use after with first, or before with last")
+	}
+
+	backward := args.Last != nil || args.Before != nil
+	size := DefaultPageSize
+	if args.First != nil {
+		size = *args.First
+	} else if args.Last != nil {
+		size = *args.Last
+	}
+	if size < 0 || size > MaxPageSize {
+		return nil, fmt.Errorf("page size must be between 0 and %d", MaxPageSize)
+	}
+
+	page := repository.Page{
+		Limit:     size + 1,
+		Ascending: (args.Order == model.ConnectionOrderOldestFirst) != backward,
+	}
+	from := args.After
+	if backward {
+		from = args.Before
+	}
+	if from != nil {
+		after, err := DecodeCursor(*from)
+		if err != nil {
+			return nil, err
+		}
+		page.After = &after
+	}
+
+	items, err := fetch(page)
+	if err != nil {
+		return nil, err
+	}
+	more := len(items) > size
+	if more {
+		items = items[:size]
+	}
+
+	info := &model.PageInfo{HasNextPage: more, HasPreviousPage: from != nil}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		info = &model.PageInfo{HasNextPage: from != nil, HasPreviousPage: more}
+	}
+
+	cursors := make([]string, len(items))
+	for i, item := range items {
+		cursors[i] = EncodeCursor(position(item))
+	}
+	if len(cursors) > 0 {
+		info.StartCursor = &cursors[0]
+		info.EndCursor = &cursors[len(cursors)-1]
+	}
+	return &Page[T]{Items: items, Cursors: cursors, PageInfo: info}, nil
+}
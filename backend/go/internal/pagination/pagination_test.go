@@ -0,0 +1,174 @@
+package pagination
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+// rows returns n positions one second apart, oldest first
+func rows(n int) []repository.Cursor {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var positions []repository.Cursor
+	for i := 0; i < n; i++ {
+		positions = append(positions, repository.Cursor{
+			CreatedAt: start.Add(time.Duration(i) * time.Second).Format(time.RFC3339Nano),
+			ID:        fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+		})
+	}
+	return positions
+}
+
+// fetcher serves pages of positions the way a repository would
+func fetcher(positions []repository.Cursor) func(repository.Page) ([]repository.Cursor, error) {
+	return func(page repository.Page) ([]repository.Cursor, error) {
+		sorted := append([]repository.Cursor(nil), positions...)
+		sort.Slice(sorted, func(i, j int) bool {
+			if page.Ascending {
+				return sorted[i].ID < sorted[j].ID
+			}
+			return sorted[i].ID > sorted[j].ID
+		})
+
+		var selected []repository.Cursor
+		for _, p := range sorted {
+			if page.After != nil && (page.Ascending && p.ID <= page.After.ID || !page.Ascending && p.ID >= page.After.ID) {
+				continue
+			}
+			if len(selected) < page.Limit {
+				selected = append(selected, p)
+			}
+		}
+		return selected, nil
+	}
+}
+
+func identity(c repository.Cursor) repository.Cursor { return c }
+
+func ints(n int) *int { return &n }
+
+// ids returns the row numbers of the items of a page
+func ids(page *Page[repository.Cursor]) string {
+	var out []string
+	for _, item := range page.Items {
+		n, _ := strconv.Atoi(item.ID[24:])
+		out = append(out, strconv.Itoa(n))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	position := rows(1)[0]
+	decoded, err := DecodeCursor(EncodeCursor(position))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != position {
+		t.Errorf("decoded %+v, want %+v", decoded, position)
+	}
+
+	for _, bad := range []string{"", "not base64!", EncodeCursor(repository.Cursor{CreatedAt: "yesterday", ID: position.ID}),
+		EncodeCursor(repository.Cursor{CreatedAt: position.CreatedAt, ID: "1; DROP TABLE projects"})} {
+		if _, err := DecodeCursor(bad); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	fetch := fetcher(rows(5))
+	newest := model.ConnectionOrderNewestFirst
+	oldest := model.ConnectionOrderOldestFirst
+
+	first, err := Fetch(Args{First: ints(2), Order: newest}, fetch, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(first); got != "4,3" {
+		t.Errorf("first page = %s", got)
+	}
+	if !first.PageInfo.HasNextPage || first.PageInfo.HasPreviousPage {
+		t.Errorf("first page info = %+v", first.PageInfo)
+	}
+
+	tests := []struct {
+		name         string
+		args         Args
+		want         string
+		next, before bool
+	}{
+		{"forward from cursor", Args{First: ints(2), After: first.PageInfo.EndCursor, Order: newest}, "2,1", true, true},
+		{"last page", Args{First: ints(10), After: first.PageInfo.EndCursor, Order: newest}, "2,1,0", false, true},
+		{"oldest first", Args{First: ints(2), Order: oldest}, "0,1", true, false},
+		{"backward from cursor", Args{Last: ints(2), Before: first.PageInfo.EndCursor, Order: oldest}, "1,2", true, true},
+		{"backward from end", Args{Last: ints(2), Order: newest}, "1,0", false, true},
+		{"empty page", Args{First: ints(0), Order: newest}, "", true, false},
+		{"default size", Args{Order: newest}, "4,3,2,1,0", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := Fetch(tt.args, fetch, identity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(page); got != tt.want {
+				t.Errorf("items = %q, want %q", got, tt.want)
+			}
+			if page.PageInfo.HasNextPage != tt.next || page.PageInfo.HasPreviousPage != tt.before {
+				t.Errorf("page info = %+v, want next %v previous %v", page.PageInfo, tt.next, tt.before)
+			}
+			if len(page.Items) > 0 && *page.PageInfo.StartCursor != page.Cursors[0] {
+				t.Errorf("start cursor does not match the first edge")
+			}
+		})
+	}
+}
+
+func TestFetchRejectsInvalidArgs(t *testing.T) {
+	cursor := EncodeCursor(rows(1)[0])
+	for name, args := range map[string]Args{
+		"too large":      {First: ints(MaxPageSize + 1)},
+		"negative":       {Last: ints(-1)},
+		"first and last": {First: ints(1), Last: ints(1)},
+		"first, before":  {First: ints(1), Before: &cursor},
+		"bad cursor":     {After: str("garbage")},
+	} {
+		if _, err := Fetch(args, fetcher(nil), identity); err == nil {
+			t.Errorf("%s: accepted %+v", name, args)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit, offset *int
+		wantLimit     int
+		wantOffset    int
+	}{
+		{"defaults", nil, nil, 10, 0},
+		{"given", ints(5), ints(15), 5, 15},
+		{"capped", ints(MaxPageSize * 10), nil, MaxPageSize, 0},
+	}
+	for _, tt := range tests {
+		limit, offset, err := Offset(tt.limit, tt.offset, 10)
+		if err != nil || limit != tt.wantLimit || offset != tt.wantOffset {
+			t.Errorf("%s: got %d, %d, %v; want %d, %d", tt.name, limit, offset, err, tt.wantLimit, tt.wantOffset)
+		}
+	}
+
+	if _, _, err := Offset(ints(-1), nil, 10); err == nil {
+		t.Error("accepted a negative limit")
+	}
+	if _, _, err := Offset(nil, ints(-1), 10); err == nil {
+		t.Error("accepted a negative offset")
+	}
+}
+
+func str(s string) *string { return &s }
@@ -0,0 +1,248 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+var (
+	ErrUnauthenticated  = errors.New("authentication required")
+	ErrForbidden        = errors.New("you do not have permission to perform this action")
+	ErrEmailNotVerified = errors.New("please verify your email address first")
+)
+
+// Role is a caller's relationship to a project. The values match the
+// ProjectRole enum in the GraphQL schema.
+type Role string
+
+const (
+	RoleNone   Role = ""
+	RoleMember Role = "MEMBER"
+	RoleOwner  Role = "OWNER"
+)
+
+// Satisfies reports whether a caller holding r meets the required role.
+// Owners implicitly have every member permission.
+func (r Role) Satisfies(required Role) bool {
+	switch required {
+	case RoleOwner:
+		return r == RoleOwner
+	case RoleMember:
+		return r == RoleOwner || r == RoleMember
+	}
+	return true
+}
+
+// Resource identifies what kind of entity an ID refers to. The values match
+// the ProjectResource enum in the GraphQL schema.
+type Resource string
+
+const (
+	ResourceProject     Resource = "PROJECT"
+	ResourceTeam        Resource = "TEAM"
+	ResourceTask        Resource = "TASK"
+	ResourceJoinRequest Resource = "JOIN_REQUEST"
+)
+
+// ProjectIDFor resolves the project a resource belongs to
+func ProjectIDFor(ctx context.Context, store repository.Store, resource Resource, id string) (string, error) {
+	var project *model.Project
+	switch resource {
+	case ResourceProject:
+		return id, nil
+	case ResourceTeam:
+		team, err := store.Teams().GetByID(ctx, id)
+		if err != nil {
+			return "", resourceError(resource, err)
+		}
+		project = team.Project
+	case ResourceTask:
+		task, err := store.Tasks().GetByID(ctx, id)
+		if err != nil {
+			return "", resourceError(resource, err)
+		}
+		project = task.Project
+	case ResourceJoinRequest:
+		joinRequest, err := store.JoinRequests().GetByID(ctx, id)
+		if err != nil {
+			return "", resourceError(resource, err)
+		}
+		project = joinRequest.Project
+	default:
+		return "", fmt.Errorf("unknown resource type %q", resource)
+	}
+	return project.ID, nil
+}
+
+func resourceError(resource Resource, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%s not found", resource.label())
+	}
+	return fmt.Errorf("failed to resolve project for %s: %w", resource.label(), err)
+}
+
+// ProjectRoleOf returns the caller's role in a project
+func ProjectRoleOf(ctx context.Context, store repository.Store, userID, projectID string) (Role, error) {
+	if userID == "" {
+		return RoleNone, nil
+	}
+
+	isOwner, err := store.Projects().IsOwner(ctx, projectID, userID)
+	if err != nil {
+		return RoleNone, fmt.Errorf("failed to check project role: %w", err)
+	}
+	if isOwner {
+		return RoleOwner, nil
+	}
+
+	isMember, err := store.Teams().IsProjectMember(ctx, projectID, userID)
+	if err != nil {
+		return RoleNone, fmt.Errorf("failed to check project role: %w", err)
+	}
+	if isMember {
+		return RoleMember, nil
+	}
+	return RoleNone, nil
+}
+
+// RequireProjectRole checks that the caller holds at least the required role
+// in the project the resource belongs to
+func RequireProjectRole(ctx context.Context, store repository.Store, userID string, resource Resource, id string, required Role) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+
+	projectID, err := ProjectIDFor(ctx, store, resource, id)
+	if err != nil {
+		return err
+	}
+
+	role, err := ProjectRoleOf(ctx, store, userID, projectID)
+	if err != nil {
+		return err
+	}
+	if !role.Satisfies(required) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// RequireSelf checks that the caller is acting on their own user record
+func RequireSelf(ctx context.Context, userID, targetUserID string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+	if userID != targetUserID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// RequireTokenScope restricts callers that authenticated with a personal
+// access token. An empty scope means the operation is not available to
+// tokens at all. Login sessions are not restricted by scopes.
+func RequireTokenScope(ctx context.Context, scope string) error {
+	granted, ok := auth.GetTokenScopesFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if scope == "" {
+		return fmt.Errorf("%w: personal access tokens cannot be used for this operation", ErrForbidden)
+	}
+	for _, s := range granted {
+		if s == scope {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: token is missing the %s scope", ErrForbidden, scope)
+}
+
+// Site-wide user roles. The values match the UserRole enum in the GraphQL
+// schema.
+const (
+	UserRoleUser  = "USER"
+	UserRoleAdmin = "ADMIN"
+)
+
+// IsAdmin reports whether a user holds the site administrator role
+func IsAdmin(ctx context.Context, store repository.Store, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+
+	role, err := store.Users().Role(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to look up user role: %w", err)
+	}
+	return string(role) == UserRoleAdmin, nil
+}
+
+// RequireAdmin checks that the caller is a site administrator
+func RequireAdmin(ctx context.Context, store repository.Store, userID string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+
+	admin, err := IsAdmin(ctx, store, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanViewProject reports whether a project is visible to the caller. Projects
+// hidden by moderators remain visible to their owner and to admins.
+func CanViewProject(ctx context.Context, store repository.Store, userID, projectID string) (bool, error) {
+	admin, err := IsAdmin(ctx, store, userID)
+	if err != nil {
+		return false, err
+	}
+
+	visible, err := store.Projects().Visible(ctx, projectID, repository.Viewer{UserID: userID, Admin: admin})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up project: %w", err)
+	}
+	return visible, nil
+}
+
+// RequireVerifiedEmail checks that the caller has verified their email
+// address
+func RequireVerifiedEmail(ctx context.Context, store repository.Store, userID string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+
+	user, err := store.Users().GetByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrUnauthenticated
+	} else if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+
+	if !user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+func (r Resource) label() string {
+	switch r {
+	case ResourceTeam:
+		return "team"
+	case ResourceTask:
+		return "task"
+	case ResourceJoinRequest:
+		return "join request"
+	}
+	return "project"
+}
@@ -0,0 +1,235 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/policy"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/memory"
+)
+
+// fixture is a project with an owner and a team member, a stranger who asked
+// to join it and a site admin
+type fixture struct {
+	store                            repository.Store
+	owner, member, stranger, admin   string
+	project, team, task, joinRequest string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	f := &fixture{store: store, project: "project-1", team: "team-1"}
+
+	createUser := func(username string) string {
+		user := &model.User{Username: username, Email: username + "@example.com"}
+		if err := store.Users().Create(ctx, user, []byte("hash")); err != nil {
+			t.Fatalf("create user %s: %v", username, err)
+		}
+		return user.ID
+	}
+	f.owner = createUser("owner")
+	f.member = createUser("member")
+	f.stranger = createUser("stranger")
+	f.admin = createUser("admin")
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(store.Users().SetRole(ctx, f.admin, model.UserRoleAdmin))
+	must(store.Users().SetEmailVerified(ctx, f.member))
+	must(store.Projects().Create(ctx, &model.Project{ID: f.project, Title: "Policy"}, f.owner))
+	must(store.Teams().Create(ctx, &model.Team{ID: f.team, Name: "Core"}, f.project))
+	must(store.Teams().AddMember(ctx, f.team, &model.TeamMember{ID: "member-1", User: &model.User{ID: f.member}, Role: "Member"}))
+
+	task := &model.Task{Title: "Write tests", Project: &model.Project{ID: f.project}}
+	must(store.Tasks().Create(ctx, task))
+	f.task = task.ID
+
+	must(store.JoinRequests().Create(ctx, f.project, f.stranger))
+	joinRequest, err := store.JoinRequests().GetByUserAndProject(ctx, f.stranger, f.project)
+	must(err)
+	f.joinRequest = joinRequest.ID
+	return f
+}
+
+func TestRequireProjectRole(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	resources := []struct {
+		resource policy.Resource
+		id       string
+	}{
+		{policy.ResourceProject, f.project},
+		{policy.ResourceTeam, f.team},
+		{policy.ResourceTask, f.task},
+		{policy.ResourceJoinRequest, f.joinRequest},
+	}
+	callers := []struct {
+		name   string
+		userID string
+		// wantMember and wantOwner are the errors when MEMBER or OWNER is required
+		wantMember, wantOwner error
+	}{
+		{"owner", f.owner, nil, nil},
+		{"member", f.member, nil, policy.ErrForbidden},
+		{"stranger", f.stranger, policy.ErrForbidden, policy.ErrForbidden},
+		{"admin", f.admin, policy.ErrForbidden, policy.ErrForbidden},
+		{"anonymous", "", policy.ErrUnauthenticated, policy.ErrUnauthenticated},
+	}
+
+	for _, r := range resources {
+		for _, c := range callers {
+			err := policy.RequireProjectRole(ctx, f.store, c.userID, r.resource, r.id, policy.RoleMember)
+			if !errors.Is(err, c.wantMember) {
+				t.Errorf("%s %s as MEMBER: err = %v, want %v", r.resource, c.name, err, c.wantMember)
+			}
+			err = policy.RequireProjectRole(ctx, f.store, c.userID, r.resource, r.id, policy.RoleOwner)
+			if !errors.Is(err, c.wantOwner) {
+				t.Errorf("%s %s as OWNER: err = %v, want %v", r.resource, c.name, err, c.wantOwner)
+			}
+		}
+	}
+}
+
+func TestProjectIDFor(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		resource policy.Resource
+		id       string
+		want     string
+		wantErr  string
+	}{
+		{policy.ResourceProject, f.project, f.project, ""},
+		{policy.ResourceTeam, f.team, f.project, ""},
+		{policy.ResourceTask, f.task, f.project, ""},
+		{policy.ResourceJoinRequest, f.joinRequest, f.project, ""},
+		{policy.ResourceTeam, "missing", "", "team not found"},
+		{policy.ResourceTask, "missing", "", "task not found"},
+		{policy.ResourceJoinRequest, "missing", "", "join request not found"},
+		{policy.Resource("MILESTONE"), "1", "", `unknown resource type "MILESTONE"`},
+	}
+	for _, tt := range tests {
+		got, err := policy.ProjectIDFor(ctx, f.store, tt.resource, tt.id)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ProjectIDFor(%s, %s): err = %v, want %q", tt.resource, tt.id, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ProjectIDFor(%s, %s) = %q, %v, want %q", tt.resource, tt.id, got, err, tt.want)
+		}
+	}
+}
+
+func TestProjectRoleOf(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name   string
+		userID string
+		want   policy.Role
+	}{
+		{"owner", f.owner, policy.RoleOwner},
+		{"member", f.member, policy.RoleMember},
+		{"stranger", f.stranger, policy.RoleNone},
+		{"anonymous", "", policy.RoleNone},
+	}
+	for _, tt := range tests {
+		got, err := policy.ProjectRoleOf(context.Background(), f.store, tt.userID, f.project)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: role = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestCanViewProject(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		userID        string
+		visible       bool
+		visibleHidden bool
+	}{
+		{"owner", f.owner, true, true},
+		{"member", f.member, true, false},
+		{"stranger", f.stranger, true, false},
+		{"admin", f.admin, true, true},
+		{"anonymous", "", true, false},
+	}
+	check := func(hidden bool) {
+		for _, tt := range tests {
+			want := tt.visible
+			if hidden {
+				want = tt.visibleHidden
+			}
+			got, err := policy.CanViewProject(ctx, f.store, tt.userID, f.project)
+			if err != nil || got != want {
+				t.Errorf("%s (hidden %v): visible = %v, %v, want %v", tt.name, hidden, got, err, want)
+			}
+		}
+	}
+
+	check(false)
+	if err := f.store.Projects().Hide(ctx, f.project, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	check(true)
+
+	if visible, err := policy.CanViewProject(ctx, f.store, f.admin, "missing"); err != nil || visible {
+		t.Errorf("missing project: visible = %v, %v", visible, err)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name   string
+		userID string
+		want   error
+	}{
+		{"admin", f.admin, nil},
+		{"owner", f.owner, policy.ErrForbidden},
+		{"deleted user", "missing", policy.ErrForbidden},
+		{"anonymous", "", policy.ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		if err := policy.RequireAdmin(context.Background(), f.store, tt.userID); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name   string
+		userID string
+		want   error
+	}{
+		{"verified", f.member, nil},
+		{"unverified", f.owner, policy.ErrEmailNotVerified},
+		{"deleted user", "missing", policy.ErrUnauthenticated},
+		{"anonymous", "", policy.ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		if err := policy.RequireVerifiedEmail(context.Background(), f.store, tt.userID); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type accessTokenRepository struct {
+	db *db
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *repository.AccessToken, tokenHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[token.UserID]; !ok {
+		return fmt.Errorf("failed to create personal access token: %w",
+			foreignKeyError("personal_access_tokens", "user_id", token.UserID))
+	}
+
+	token.ID = uuid.New().String()
+	token.CreatedAt = time.Now().UTC()
+	stored := *token
+	stored.Scopes = cloneStrings(token.Scopes)
+	st.accessTokens[token.ID] = accessTokenRow{token: stored, tokenHash: tokenHash, seq: st.nextSeq()}
+	return nil
+}
+
+func (r *accessTokenRepository) ListByUser(ctx context.Context, userID string) ([]*repository.AccessToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var rows []accessTokenRow
+	for _, row := range r.db.state.accessTokens {
+		if row.token.UserID == userID && !row.revoked {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row accessTokenRow) int64 { return row.seq }, true)
+
+	tokens := []*repository.AccessToken{}
+	for _, row := range rows {
+		token := row.token
+		token.Scopes = cloneStrings(token.Scopes)
+		tokens = append(tokens, &token)
+	}
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) Revoke(ctx context.Context, userID, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.accessTokens[id]
+	if !ok || row.token.UserID != userID || row.revoked {
+		return repository.ErrNotFound
+	}
+	row.revoked = true
+	st.accessTokens[id] = row
+	return nil
+}
+
+func (r *accessTokenRepository) RevokeAll(ctx context.Context, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	for id, row := range st.accessTokens {
+		if row.token.UserID == userID {
+			row.revoked = true
+			st.accessTokens[id] = row
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type joinRequestRepository struct {
+	db *db
+}
+
+// toJoinRequest joins a request with its user and project. It reports false
+// if either is gone, like the inner joins in Postgres.
+func (st *state) toJoinRequest(row joinRequestRow) (*model.JoinRequest, bool) {
+	user, ok := st.users[row.userID]
+	if !ok {
+		return nil, false
+	}
+	project, ok := st.projects[row.projectID]
+	if !ok {
+		return nil, false
+	}
+
+	return &model.JoinRequest{
+		ID:        row.id,
+		Status:    row.status,
+		CreatedAt: row.createdAt,
+		User:      &model.User{ID: user.user.ID, Username: user.user.Username},
+		Project:   &model.Project{ID: project.project.ID, Title: project.project.Title},
+	}, true
+}
+
+func (st *state) selectJoinRequests(match func(joinRequestRow) bool) []*model.JoinRequest {
+	var rows []joinRequestRow
+	for _, row := range st.joinRequests {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row joinRequestRow) int64 { return row.seq }, false)
+
+	joinRequests := []*model.JoinRequest{}
+	for _, row := range rows {
+		if jr, ok := st.toJoinRequest(row); ok {
+			joinRequests = append(joinRequests, jr)
+		}
+	}
+	return joinRequests
+}
+
+func (r *joinRequestRepository) Create(ctx context.Context, projectID, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.projects[projectID]; !ok {
+		return fmt.Errorf("failed to create join request: %w", foreignKeyError("join_requests", "project_id", projectID))
+	}
+	if _, ok := st.users[userID]; !ok {
+		return fmt.Errorf("failed to create join request: %w", foreignKeyError("join_requests", "user_id", userID))
+	}
+
+	id := uuid.New().String()
+	st.joinRequests[id] = joinRequestRow{
+		id:        id,
+		projectID: projectID,
+		userID:    userID,
+		status:    model.JoinRequestStatusPending,
+		createdAt: now(),
+		seq:       st.nextSeq(),
+	}
+	return nil
+}
+
+func (r *joinRequestRepository) GetByID(ctx context.Context, id string) (*model.JoinRequest, error) {
+	return r.first(func(row joinRequestRow) bool { return row.id == id })
+}
+
+func (r *joinRequestRepository) GetByUserAndProject(ctx context.Context, userID, projectID string) (*model.JoinRequest, error) {
+	return r.first(func(row joinRequestRow) bool {
+		return row.userID == userID && row.projectID == projectID
+	})
+}
+
+func (r *joinRequestRepository) first(match func(joinRequestRow) bool) (*model.JoinRequest, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	joinRequests := r.db.state.selectJoinRequests(match)
+	if len(joinRequests) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return joinRequests[0], nil
+}
+
+func (r *joinRequestRepository) ByProject(ctx context.Context, projectID string) ([]*model.JoinRequest, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.state.selectJoinRequests(func(row joinRequestRow) bool {
+		return row.projectID == projectID
+	}), nil
+}
+
+func joinRequestFilter(projectID string, status *model.JoinRequestStatus) func(joinRequestRow) bool {
+	return func(row joinRequestRow) bool {
+		return row.projectID == projectID && (status == nil || row.status == *status)
+	}
+}
+
+func (r *joinRequestRepository) ListPage(ctx context.Context, projectID string, status *model.JoinRequestStatus, page repository.Page) ([]*model.JoinRequest, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	joinRequests := r.db.state.selectJoinRequests(joinRequestFilter(projectID, status))
+	return keysetPage(joinRequests, func(jr *model.JoinRequest) repository.Cursor {
+		return repository.Cursor{CreatedAt: jr.CreatedAt, ID: jr.ID}
+	}, page), nil
+}
+
+func (r *joinRequestRepository) Count(ctx context.Context, projectID string, status *model.JoinRequestStatus) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return len(r.db.state.selectJoinRequests(joinRequestFilter(projectID, status))), nil
+}
+
+func (r *joinRequestRepository) UpdateStatus(ctx context.Context, id string, status model.JoinRequestStatus) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.joinRequests[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	row.status = status
+	st.joinRequests[id] = row
+	return nil
+}
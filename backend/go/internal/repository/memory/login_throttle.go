@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type loginThrottleRepository struct {
+	db *db
+}
+
+func (r *loginThrottleRepository) Get(ctx context.Context, keys ...repository.ThrottleKey) ([]*repository.ThrottleState, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	states := []*repository.ThrottleState{}
+	for _, key := range keys {
+		if state, ok := r.db.state.throttle[key]; ok {
+			states = append(states, &state)
+		}
+	}
+	return states, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key repository.ThrottleKey, at, resetBefore time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	state, ok := st.throttle[key]
+	if !ok || state.LastFailureAt.Before(resetBefore) {
+		state = repository.ThrottleState{ThrottleKey: key, LockedUntil: state.LockedUntil}
+	}
+	state.Failures++
+	state.LastFailureAt = at
+	st.throttle[key] = state
+	return state.Failures, nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key repository.ThrottleKey, until time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if state, ok := st.throttle[key]; ok {
+		state.LockedUntil = &until
+		state.Failures = 0
+		st.throttle[key] = state
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) Clear(ctx context.Context, key repository.ThrottleKey) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	delete(r.db.state.throttle, key)
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type moderationReportRepository struct {
+	db *db
+}
+
+func toReport(row reportRow) *model.ModerationReport {
+	report := row.report
+	report.Reporter = &model.User{ID: row.reporterID}
+	if row.resolvedBy != nil {
+		report.ResolvedBy = &model.User{ID: *row.resolvedBy}
+	}
+	return &report
+}
+
+func (r *moderationReportRepository) Create(ctx context.Context, report *model.ModerationReport, reporterID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[reporterID]; !ok {
+		return fmt.Errorf("failed to create moderation report: %w",
+			foreignKeyError("moderation_reports", "reporter_id", reporterID))
+	}
+
+	report.ID = uuid.New().String()
+	report.Status = model.ModerationReportStatusOpen
+	report.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	stored := *report
+	stored.Reporter = nil
+	stored.ResolvedBy = nil
+	st.reports[report.ID] = reportRow{report: stored, reporterID: reporterID, seq: st.nextSeq()}
+	return nil
+}
+
+func (r *moderationReportRepository) GetByID(ctx context.Context, id string) (*model.ModerationReport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.state.reports[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return toReport(row), nil
+}
+
+func (r *moderationReportRepository) List(ctx context.Context, status *model.ModerationReportStatus, limit, offset int) ([]*model.ModerationReport, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var rows []reportRow
+	for _, row := range r.db.state.reports {
+		if status == nil || row.report.Status == *status {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row reportRow) int64 { return row.seq }, true)
+
+	reports := []*model.ModerationReport{}
+	for _, row := range paginate(rows, limit, offset) {
+		reports = append(reports, toReport(row))
+	}
+	return reports, nil
+}
+
+func (r *moderationReportRepository) Resolve(ctx context.Context, id, adminID string, status model.ModerationReportStatus, note *string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.reports[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	resolvedAt := time.Now().UTC().Format(time.RFC3339)
+	row.report.Status = status
+	row.report.ResolutionNote = note
+	row.report.ResolvedAt = &resolvedAt
+	row.resolvedBy = &adminID
+	st.reports[id] = row
+	return nil
+}
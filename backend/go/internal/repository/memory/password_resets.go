@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type passwordResetRepository struct {
+	db *db
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[userID]; !ok {
+		return fmt.Errorf("failed to store reset token: %w", foreignKeyError("password_reset_tokens", "user_id", userID))
+	}
+
+	// Only the most recent link should work
+	for hash, token := range st.resetTokens {
+		if token.userID == userID {
+			token.used = true
+			st.resetTokens[hash] = token
+		}
+	}
+	st.resetTokens[tokenHash] = resetTokenRow{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash string) (string, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	token, ok := st.resetTokens[tokenHash]
+	if !ok || token.used || !token.expiresAt.After(time.Now()) {
+		return "", repository.ErrNotFound
+	}
+	token.used = true
+	st.resetTokens[tokenHash] = token
+	return token.userID, nil
+}
@@ -0,0 +1,530 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type projectRepository struct {
+	db *db
+}
+
+// toProject returns a copy of the project joined with its owner. It reports
+// false when the owner no longer exists, like the inner join in Postgres.
+func (st *state) toProject(row projectRow) (*model.Project, bool) {
+	owner, ok := st.users[row.ownerID]
+	if !ok {
+		return nil, false
+	}
+
+	project := row.project
+	project.Technologies = cloneStrings(project.Technologies)
+	project.LearningObjectives = cloneStrings(project.LearningObjectives)
+	project.TeamMembers = []*model.TeamMember{}
+	project.Owner = &model.User{
+		ID:       owner.user.ID,
+		Username: owner.user.Username,
+		Email:    owner.user.Email,
+	}
+	return &project, true
+}
+
+// selectProjects returns the projects matching keep, sorted by less
+func (st *state) selectProjects(keep func(projectRow) bool, less func(a, b projectRow) bool) []*model.Project {
+	var rows []projectRow
+	for _, row := range st.projects {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+
+	projects := []*model.Project{}
+	for _, row := range rows {
+		if project, ok := st.toProject(row); ok {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+func newestFirst(a, b projectRow) bool {
+	return a.seq > b.seq
+}
+
+func (r *projectRepository) Create(ctx context.Context, project *model.Project, ownerID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, exists := st.projects[project.ID]; exists {
+		return fmt.Errorf("failed to insert project: duplicate id %s", project.ID)
+	}
+	if _, ok := st.users[ownerID]; !ok {
+		return fmt.Errorf("failed to set project owner: %w", foreignKeyError("project_owners", "user_id", ownerID))
+	}
+
+	row := *project
+	row.Owner = nil
+	row.Team = nil
+	row.TeamMembers = nil
+	row.Technologies = cloneStrings(project.Technologies)
+	row.LearningObjectives = cloneStrings(project.LearningObjectives)
+	st.projects[project.ID] = projectRow{project: row, ownerID: ownerID, seq: st.nextSeq()}
+	return nil
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id string) (*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.projects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	project, ok := st.toProject(row)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return project, nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *model.Project) error {
+	return r.modify(project.ID, func(p *model.Project) {
+		p.Title = project.Title
+		p.Description = project.Description
+		p.Category = project.Category
+		p.Status = project.Status
+		p.OpenPositions = project.OpenPositions
+		p.TimeCommitment = project.TimeCommitment
+		p.LearningObjectives = cloneStrings(project.LearningObjectives)
+		p.UpdatedAt = project.UpdatedAt
+	})
+}
+
+// modify applies fn to a copy of the stored project and saves it
+func (r *projectRepository) modify(id string, fn func(*model.Project)) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.projects[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	fn(&row.project)
+	st.projects[id] = row
+	return nil
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.projects[id]; !ok {
+		return repository.ErrNotFound
+	}
+	st.deleteProject(id)
+	return nil
+}
+
+// deleteProject removes a project and everything that references it
+func (st *state) deleteProject(id string) {
+	for jrID, jr := range st.joinRequests {
+		if jr.projectID == id {
+			delete(st.joinRequests, jrID)
+		}
+	}
+	for teamID, team := range st.teams {
+		if team.projectID == id {
+			st.deleteTeam(teamID)
+		}
+	}
+	for taskID, task := range st.tasks {
+		if task.projectID == id {
+			delete(st.tasks, taskID)
+		}
+	}
+	delete(st.projects, id)
+}
+
+// projectFilter returns the match function for a filter
+func projectFilter(filter repository.ProjectFilter) func(projectRow) bool {
+	return func(row projectRow) bool {
+		p := row.project
+		if row.hiddenAt != nil {
+			return false
+		}
+		if len(filter.Categories) > 0 && !containsString(filter.Categories, p.Category) {
+			return false
+		}
+		if len(filter.Statuses) > 0 && !containsStatus(filter.Statuses, p.Status) {
+			return false
+		}
+		if len(filter.Technologies) > 0 && !matchTechnologies(p.Technologies, filter.Technologies, filter.AllTechnologies) {
+			return false
+		}
+		if filter.MinOpenPositions != nil && p.OpenPositions < *filter.MinOpenPositions {
+			return false
+		}
+		if filter.MaxOpenPositions != nil && p.OpenPositions > *filter.MaxOpenPositions {
+			return false
+		}
+		if filter.TimeCommitment != nil && p.TimeCommitment != *filter.TimeCommitment {
+			return false
+		}
+		if filter.CreatedAfter != nil && compareTimes(p.CreatedAt, *filter.CreatedAfter) < 0 {
+			return false
+		}
+		if filter.CreatedBefore != nil && compareTimes(p.CreatedAt, *filter.CreatedBefore) >= 0 {
+			return false
+		}
+		if filter.OwnerID != nil && row.ownerID != *filter.OwnerID {
+			return false
+		}
+		if filter.LearningObjective != nil && !containsFold(*filter.LearningObjective, p.LearningObjectives...) {
+			return false
+		}
+		if filter.Query != "" && !matchesWords(&p, searchWords(filter.Query)) {
+			return false
+		}
+		return true
+	}
+}
+
+func containsStatus(statuses []model.ProjectStatus, status model.ProjectStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTechnologies reports whether a project uses any, or with all set
+// every, of the wanted technologies
+func matchTechnologies(technologies, wanted []string, all bool) bool {
+	for _, technology := range wanted {
+		found := containsString(technologies, technology)
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
+
+// projectOrders mirrors the ORDER BY clauses of the Postgres project sorts
+var projectOrders = map[model.ProjectSort]func(a, b projectRow) bool{
+	model.ProjectSortNewest: newestFirst,
+	model.ProjectSortPopularity: func(a, b projectRow) bool {
+		if a.project.Popularity != b.project.Popularity {
+			return a.project.Popularity > b.project.Popularity
+		}
+		return newestFirst(a, b)
+	},
+	model.ProjectSortOpenPositions: func(a, b projectRow) bool {
+		if a.project.OpenPositions != b.project.OpenPositions {
+			return a.project.OpenPositions > b.project.OpenPositions
+		}
+		return newestFirst(a, b)
+	},
+}
+
+func (r *projectRepository) List(ctx context.Context, filter repository.ProjectFilter, sort model.ProjectSort, limit, offset int) ([]*model.Project, error) {
+	less, ok := projectOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown project sort %q", sort)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	projects := r.db.state.selectProjects(projectFilter(filter), less)
+	return paginate(projects, limit, offset), nil
+}
+
+func (r *projectRepository) ListPage(ctx context.Context, filter repository.ProjectFilter, page repository.Page) ([]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	projects := r.db.state.selectProjects(projectFilter(filter), newestFirst)
+	return keysetPage(projects, projectCursor, page), nil
+}
+
+func (r *projectRepository) Count(ctx context.Context, filter repository.ProjectFilter) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return len(r.db.state.selectProjects(projectFilter(filter), newestFirst)), nil
+}
+
+func projectCursor(p *model.Project) repository.Cursor {
+	return repository.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+func (r *projectRepository) Facets(ctx context.Context, filter repository.ProjectFilter) (*model.ProjectFacets, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	categories, technologies := map[string]int{}, map[string]int{}
+	statuses, timeCommitments := map[string]int{}, map[string]int{}
+	projects := r.db.state.selectProjects(projectFilter(filter), newestFirst)
+	for _, p := range projects {
+		categories[p.Category]++
+		statuses[string(p.Status)]++
+		timeCommitments[p.TimeCommitment]++
+		seen := map[string]bool{}
+		for _, technology := range p.Technologies {
+			if !seen[technology] {
+				seen[technology] = true
+				technologies[technology]++
+			}
+		}
+	}
+
+	return &model.ProjectFacets{
+		Total:           len(projects),
+		Categories:      facetCounts(categories),
+		Technologies:    facetCounts(technologies),
+		Statuses:        facetCounts(statuses),
+		TimeCommitments: facetCounts(timeCommitments),
+	}, nil
+}
+
+// facetCounts orders counts like the Postgres facet query, leaving out
+// blank values
+func facetCounts(counts map[string]int) []*model.FacetCount {
+	facets := []*model.FacetCount{}
+	for value, count := range counts {
+		if value != "" {
+			facets = append(facets, &model.FacetCount{Value: value, Count: count})
+		}
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
+// visibleTo reports whether a project may be listed for viewer
+func visibleTo(row projectRow, viewer repository.Viewer) bool {
+	return row.hiddenAt == nil || viewer.Admin || row.ownerID == viewer.UserID
+}
+
+func (r *projectRepository) ListByOwner(ctx context.Context, ownerID string, viewer repository.Viewer) ([]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.state.selectProjects(func(row projectRow) bool {
+		return row.ownerID == ownerID && visibleTo(row, viewer)
+	}, newestFirst), nil
+}
+
+func (r *projectRepository) ListByMember(ctx context.Context, userID string, viewer repository.Viewer) ([]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	memberOf := map[string]bool{}
+	for _, m := range st.members {
+		if m.userID == userID {
+			memberOf[st.teams[m.teamID].projectID] = true
+		}
+	}
+
+	return st.selectProjects(func(row projectRow) bool {
+		return memberOf[row.project.ID] && visibleTo(row, viewer)
+	}, newestFirst), nil
+}
+
+func (r *projectRepository) ListByOwners(ctx context.Context, ownerIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	projects := map[string][]*model.Project{}
+	for _, ownerID := range ownerIDs {
+		owned := st.selectProjects(func(row projectRow) bool {
+			return row.ownerID == ownerID && visibleTo(row, viewer)
+		}, newestFirst)
+		if len(owned) > 0 {
+			projects[ownerID] = owned
+		}
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) ListByMembers(ctx context.Context, userIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	// user ID -> project IDs of the teams they belong to
+	memberOf := map[string]map[string]bool{}
+	for _, m := range st.members {
+		if !containsString(userIDs, m.userID) {
+			continue
+		}
+		if memberOf[m.userID] == nil {
+			memberOf[m.userID] = map[string]bool{}
+		}
+		memberOf[m.userID][st.teams[m.teamID].projectID] = true
+	}
+
+	projects := map[string][]*model.Project{}
+	for userID, projectIDs := range memberOf {
+		projects[userID] = st.selectProjects(func(row projectRow) bool {
+			return projectIDs[row.project.ID] && visibleTo(row, viewer)
+		}, newestFirst)
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) Related(ctx context.Context, project *model.Project, limit int) ([]*model.Project, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	matching := func(row projectRow) int {
+		n := 0
+		for _, t := range row.project.Technologies {
+			if containsString(project.Technologies, t) {
+				n++
+			}
+		}
+		return n
+	}
+
+	projects := r.db.state.selectProjects(func(row projectRow) bool {
+		return row.project.ID != project.ID && row.hiddenAt == nil &&
+			(row.project.Category == project.Category || matching(row) > 0)
+	}, func(a, b projectRow) bool {
+		if ma, mb := matching(a), matching(b); ma != mb {
+			return ma > mb
+		}
+		return a.project.Popularity > b.project.Popularity
+	})
+	return paginate(projects, limit, 0), nil
+}
+
+func (r *projectRepository) UpdateStatus(ctx context.Context, id string, status model.ProjectStatus) error {
+	return r.modify(id, func(p *model.Project) {
+		p.Status = status
+		p.UpdatedAt = now()
+	})
+}
+
+func (r *projectRepository) AdjustPopularity(ctx context.Context, id string, delta int) error {
+	return r.modify(id, func(p *model.Project) {
+		p.Popularity += delta
+		if p.Popularity < 0 {
+			p.Popularity = 0
+		}
+		p.UpdatedAt = now()
+	})
+}
+
+func (r *projectRepository) AddTechnology(ctx context.Context, id, technology string) (bool, error) {
+	added := false
+	err := r.modify(id, func(p *model.Project) {
+		if containsString(p.Technologies, technology) {
+			return
+		}
+		p.Technologies = append(cloneStrings(p.Technologies), technology)
+		p.UpdatedAt = now()
+		added = true
+	})
+	return added, err
+}
+
+func (r *projectRepository) RemoveTechnology(ctx context.Context, id, technology string) (bool, error) {
+	removed := false
+	err := r.modify(id, func(p *model.Project) {
+		if !containsString(p.Technologies, technology) {
+			return
+		}
+		remaining := []string{}
+		for _, t := range p.Technologies {
+			if t != technology {
+				remaining = append(remaining, t)
+			}
+		}
+		p.Technologies = remaining
+		p.UpdatedAt = now()
+		removed = true
+	})
+	return removed, err
+}
+
+func (r *projectRepository) DecrementOpenPositions(ctx context.Context, id string) error {
+	return r.modify(id, func(p *model.Project) {
+		p.OpenPositions--
+	})
+}
+
+func (r *projectRepository) IsOwner(ctx context.Context, projectID, userID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.state.projects[projectID]
+	return ok && row.ownerID == userID, nil
+}
+
+func (r *projectRepository) Visible(ctx context.Context, id string, viewer repository.Viewer) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.state.projects[id]
+	return ok && visibleTo(row, viewer), nil
+}
+
+func (r *projectRepository) SetOwner(ctx context.Context, projectID, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.projects[projectID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := st.users[userID]; !ok {
+		return fmt.Errorf("failed to update project owner: %w", foreignKeyError("project_owners", "user_id", userID))
+	}
+	row.ownerID = userID
+	st.projects[projectID] = row
+	return nil
+}
+
+func (r *projectRepository) Hide(ctx context.Context, id, reason string) error {
+	hiddenAt := now()
+	return r.setHidden(id, &hiddenAt)
+}
+
+func (r *projectRepository) Unhide(ctx context.Context, id string) error {
+	return r.setHidden(id, nil)
+}
+
+func (r *projectRepository) setHidden(id string, hiddenAt *string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.projects[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	row.hiddenAt = hiddenAt
+	st.projects[id] = row
+	return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+)
+
+// Weights of the fields of a project, in the order of the Postgres search
+// document weights A to D
+const (
+	titleWeight       = 1.0
+	technologyWeight  = 0.4
+	descriptionWeight = 0.2
+	objectiveWeight   = 0.1
+)
+
+// searchWords splits a query into words the way the Postgres prefix query is
+// built
+func searchWords(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matchesWords reports whether every word appears in the title,
+// technologies, category, description or learning objectives of a project
+func matchesWords(p *model.Project, words []string) bool {
+	fields := []string{p.Title, p.Category, p.Description}
+	fields = append(fields, p.Technologies...)
+	fields = append(fields, p.LearningObjectives...)
+	for _, word := range words {
+		if !containsFold(word, fields...) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchScore adds up the weights of the fields each word appears in
+func searchScore(p *model.Project, words []string) float64 {
+	var score float64
+	for _, word := range words {
+		if containsFold(word, p.Title) {
+			score += titleWeight
+		}
+		if containsFold(word, append([]string{p.Category}, p.Technologies...)...) {
+			score += technologyWeight
+		}
+		if containsFold(word, p.Description) {
+			score += descriptionWeight
+		}
+		if containsFold(word, p.LearningObjectives...) {
+			score += objectiveWeight
+		}
+	}
+	return score
+}
+
+// Search matches words anywhere in the searched fields, which covers the
+// prefix matching of the Postgres search but not its typo tolerance.
+func (r *projectRepository) Search(ctx context.Context, query string, limit, offset int) ([]*model.SearchHit, error) {
+	words := searchWords(query)
+	if len(words) == 0 {
+		return []*model.SearchHit{}, nil
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	projects := r.db.state.selectProjects(func(row projectRow) bool {
+		return row.hiddenAt == nil && matchesWords(&row.project, words)
+	}, func(a, b projectRow) bool {
+		scoreA, scoreB := searchScore(&a.project, words), searchScore(&b.project, words)
+		if scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		return newestFirst(a, b)
+	})
+
+	hits := []*model.SearchHit{}
+	for _, project := range paginate(projects, limit, offset) {
+		hits = append(hits, &model.SearchHit{
+			Project:        project,
+			Score:          searchScore(project, words),
+			TitleHighlight: highlight(project.Title, words),
+			Snippet:        highlight(project.Description, words),
+		})
+	}
+	return hits, nil
+}
+
+// highlight escapes text for HTML and wraps the occurrences of the words in
+// <mark> tags
+func highlight(text string, words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	pattern := regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:match[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[match[0]:match[1]]) + "</mark>")
+		last = match[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+type sessionRepository struct {
+	db *db
+}
+
+func (r *sessionRepository) Create(ctx context.Context, userID string, session *auth.Session, token *auth.RefreshToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[userID]; !ok {
+		return fmt.Errorf("failed to insert session: %w", foreignKeyError("sessions", "user_id", userID))
+	}
+	st.sessions[session.ID] = sessionRow{session: *session, userID: userID}
+	st.refreshTokens[token.TokenHash] = *token
+	return nil
+}
+
+func (r *sessionRepository) RefreshToken(ctx context.Context, tokenHash string) (*auth.RefreshToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	token, ok := st.refreshTokens[tokenHash]
+	if !ok {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	session, ok := st.sessions[token.SessionID]
+	if !ok {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	token.UserID = session.userID
+	token.SessionRevoked = session.revokedAt != nil
+	return &token, nil
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, used, next *auth.RefreshToken, ip string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	token, ok := st.refreshTokens[used.TokenHash]
+	if !ok || token.UsedAt != nil {
+		return auth.ErrRefreshTokenReused
+	}
+	session, ok := st.sessions[used.SessionID]
+	if !ok || session.revokedAt != nil {
+		return auth.ErrInvalidRefreshToken
+	}
+
+	now := time.Now().UTC()
+	token.UsedAt = &now
+	st.refreshTokens[used.TokenHash] = token
+
+	session.session.LastUsedAt = now
+	if ip != "" {
+		session.session.IPAddress = ip
+	}
+	st.sessions[used.SessionID] = session
+
+	st.refreshTokens[next.TokenHash] = *next
+	return nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, userID, sessionID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	session, ok := st.sessions[sessionID]
+	if !ok || session.userID != userID || session.revokedAt != nil {
+		return auth.ErrSessionNotFound
+	}
+	now := time.Now().UTC()
+	session.revokedAt = &now
+	st.sessions[sessionID] = session
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID, keepSessionID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	now := time.Now().UTC()
+	for id, session := range st.sessions {
+		if session.userID == userID && session.revokedAt == nil && id != keepSessionID {
+			session.revokedAt = &now
+			st.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *sessionRepository) List(ctx context.Context, userID string) ([]*auth.Session, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var sessions []*auth.Session
+	for _, row := range r.db.state.sessions {
+		if row.userID == userID && row.revokedAt == nil {
+			session := row.session
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, sessionID, ip string, at, staleBefore time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	session, ok := st.sessions[sessionID]
+	if !ok || !session.session.LastUsedAt.Before(staleBefore) {
+		return nil
+	}
+	session.session.LastUsedAt = at
+	if ip != "" {
+		session.session.IPAddress = ip
+	}
+	st.sessions[sessionID] = session
+	return nil
+}
+
+func (r *sessionRepository) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	session, ok := r.db.state.sessions[sessionID]
+	return ok && session.revokedAt == nil, nil
+}
+
+func (r *sessionRepository) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	// Entries are only useful until the token would have expired anyway
+	now := time.Now()
+	for id, exp := range st.revokedTokens {
+		if exp.Before(now) {
+			delete(st.revokedTokens, id)
+		}
+	}
+	st.revokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *sessionRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	_, revoked := r.db.state.revokedTokens[tokenID]
+	return revoked, nil
+}
+
+func (r *sessionRepository) PersonalAccessToken(ctx context.Context, tokenHash string) (*auth.PersonalAccessToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, row := range r.db.state.accessTokens {
+		if row.tokenHash == tokenHash && !row.revoked {
+			return &auth.PersonalAccessToken{
+				ID:        row.token.ID,
+				UserID:    row.token.UserID,
+				Scopes:    cloneStrings(row.token.Scopes),
+				ExpiresAt: row.token.ExpiresAt,
+			}, nil
+		}
+	}
+	return nil, auth.ErrInvalidAccessToken
+}
+
+func (r *sessionRepository) TouchPersonalAccessToken(ctx context.Context, id string, at, staleBefore time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.accessTokens[id]
+	if !ok || (row.token.LastUsedAt != nil && !row.token.LastUsedAt.Before(staleBefore)) {
+		return nil
+	}
+	row.token.LastUsedAt = &at
+	st.accessTokens[id] = row
+	return nil
+}
+
+func (r *sessionRepository) IsUserSuspended(ctx context.Context, userID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.state.users[userID]
+	return !ok || row.suspendedAt != nil, nil
+}
@@ -0,0 +1,487 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Store is an in-memory repository.Store for tests. It mirrors the
+// constraints and cascades of the Postgres schema. Transactions are
+// serialized and rolled back by restoring a snapshot taken when they began.
+type Store struct {
+	db   *db
+	inTx bool
+}
+
+type db struct {
+	mu    sync.Mutex
+	txMu  sync.Mutex
+	state *state
+}
+
+type state struct {
+	seq          int64
+	users        map[string]userRow
+	projects     map[string]projectRow
+	teams        map[string]teamRow
+	members      map[string]memberRow
+	tasks        map[string]taskRow
+	joinRequests map[string]joinRequestRow
+	sessions     map[string]sessionRow
+	// Refresh and password reset tokens are keyed by their hash
+	refreshTokens map[string]auth.RefreshToken
+	// Revoked access tokens are keyed by jti and hold their expiry
+	revokedTokens map[string]time.Time
+	resetTokens   map[string]resetTokenRow
+	accessTokens  map[string]accessTokenRow
+	totp          map[string]repository.TwoFactorSecret
+	recoveryCodes map[string]recoveryCodeRow
+	throttle      map[repository.ThrottleKey]repository.ThrottleState
+	reports       map[string]reportRow
+	audit         []auditRow
+}
+
+// Rows are stored by value and their slices are never modified in place, so
+// copying the maps is enough to snapshot the state
+
+type userRow struct {
+	user         model.User
+	passwordHash []byte
+	role         model.UserRole
+	suspendedAt  *string
+	seq          int64
+}
+
+type projectRow struct {
+	project  model.Project
+	ownerID  string
+	hiddenAt *string
+	seq      int64
+}
+
+type teamRow struct {
+	team      model.Team
+	projectID string
+	seq       int64
+}
+
+type memberRow struct {
+	id       string
+	teamID   string
+	userID   string
+	role     string
+	joinedAt string
+	seq      int64
+}
+
+type taskRow struct {
+	task       model.Task
+	projectID  string
+	assigneeID *string
+	seq        int64
+}
+
+type joinRequestRow struct {
+	id        string
+	projectID string
+	userID    string
+	status    model.JoinRequestStatus
+	createdAt string
+	seq       int64
+}
+
+type sessionRow struct {
+	session   auth.Session
+	userID    string
+	revokedAt *time.Time
+}
+
+type accessTokenRow struct {
+	token     repository.AccessToken
+	tokenHash string
+	revoked   bool
+	seq       int64
+}
+
+type recoveryCodeRow struct {
+	userID   string
+	codeHash string
+	used     bool
+}
+
+type resetTokenRow struct {
+	userID    string
+	expiresAt time.Time
+	used      bool
+}
+
+type auditRow struct {
+	event     audit.Event
+	id        string
+	createdAt time.Time
+}
+
+type reportRow struct {
+	report     model.ModerationReport
+	reporterID string
+	resolvedBy *string
+	seq        int64
+}
+
+var _ repository.Store = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{db: &db{state: &state{
+		users:         map[string]userRow{},
+		projects:      map[string]projectRow{},
+		teams:         map[string]teamRow{},
+		members:       map[string]memberRow{},
+		tasks:         map[string]taskRow{},
+		joinRequests:  map[string]joinRequestRow{},
+		sessions:      map[string]sessionRow{},
+		refreshTokens: map[string]auth.RefreshToken{},
+		revokedTokens: map[string]time.Time{},
+		accessTokens:  map[string]accessTokenRow{},
+		totp:          map[string]repository.TwoFactorSecret{},
+		recoveryCodes: map[string]recoveryCodeRow{},
+		resetTokens:   map[string]resetTokenRow{},
+		throttle:      map[repository.ThrottleKey]repository.ThrottleState{},
+		reports:       map[string]reportRow{},
+	}}}
+}
+
+func (s *Store) Projects() repository.ProjectRepository {
+	return &projectRepository{db: s.db}
+}
+
+func (s *Store) Users() repository.UserRepository {
+	return &userRepository{db: s.db}
+}
+
+func (s *Store) Teams() repository.TeamRepository {
+	return &teamRepository{db: s.db}
+}
+
+func (s *Store) Tasks() repository.TaskRepository {
+	return &taskRepository{db: s.db}
+}
+
+func (s *Store) JoinRequests() repository.JoinRequestRepository {
+	return &joinRequestRepository{db: s.db}
+}
+
+func (s *Store) Sessions() auth.SessionStore {
+	return &sessionRepository{db: s.db}
+}
+
+func (s *Store) AccessTokens() repository.AccessTokenRepository {
+	return &accessTokenRepository{db: s.db}
+}
+
+func (s *Store) TwoFactor() repository.TwoFactorRepository {
+	return &twoFactorRepository{db: s.db}
+}
+
+func (s *Store) PasswordResets() repository.PasswordResetRepository {
+	return &passwordResetRepository{db: s.db}
+}
+
+func (s *Store) LoginThrottle() repository.LoginThrottleRepository {
+	return &loginThrottleRepository{db: s.db}
+}
+
+func (s *Store) ModerationReports() repository.ModerationReportRepository {
+	return &moderationReportRepository{db: s.db}
+}
+
+// RecordAudit keeps the event in memory, filling in the actor and IP address
+// from the request context the same way audit.Record does
+func (s *Store) RecordAudit(ctx context.Context, event audit.Event) error {
+	if event.ActorID == "" {
+		event.ActorID, _ = auth.GetUserIDFromContext(ctx)
+	}
+	if event.IPAddress == "" {
+		event.IPAddress = auth.GetClientIPFromContext(ctx)
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.state.audit = append(s.db.state.audit, auditRow{event: event, id: uuid.New().String(), createdAt: time.Now().UTC()})
+	return nil
+}
+
+// AuditEvents returns every recorded audit event, oldest first
+func (s *Store) AuditEvents() []audit.Event {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	events := make([]audit.Event, len(s.db.state.audit))
+	for i, row := range s.db.state.audit {
+		events[i] = row.event
+	}
+	return events
+}
+
+func (s *Store) AuditLog(ctx context.Context, filter audit.Filter) ([]*audit.StoredEvent, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var matched []*audit.StoredEvent
+	rows := s.db.state.audit
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		e := row.event
+		if (filter.ProjectID != "" && e.ProjectID != filter.ProjectID) ||
+			(filter.ActorID != "" && e.ActorID != filter.ActorID) ||
+			(!filter.From.IsZero() && row.createdAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && !row.createdAt.Before(filter.To)) {
+			continue
+		}
+
+		before, err := snapshot(e.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := snapshot(e.After)
+		if err != nil {
+			return nil, err
+		}
+		matched = append(matched, &audit.StoredEvent{
+			ID:         row.id,
+			Action:     e.Action,
+			ActorID:    e.ActorID,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			ProjectID:  e.ProjectID,
+			IPAddress:  e.IPAddress,
+			Before:     before,
+			After:      after,
+			CreatedAt:  row.createdAt,
+		})
+	}
+	return paginate(matched, filter.Limit, filter.Offset), nil
+}
+
+// snapshot encodes an audit snapshot the way audit.Record stores it
+func snapshot(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return b, nil
+}
+
+func (s *Store) WithTx(ctx context.Context, fn func(repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.db.txMu.Lock()
+	defer s.db.txMu.Unlock()
+
+	s.db.mu.Lock()
+	snapshot := s.db.state.clone()
+	s.db.mu.Unlock()
+
+	if err := fn(&Store{db: s.db, inTx: true}); err != nil {
+		s.db.mu.Lock()
+		s.db.state = snapshot
+		s.db.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (st *state) clone() *state {
+	c := &state{
+		seq:           st.seq,
+		users:         make(map[string]userRow, len(st.users)),
+		projects:      make(map[string]projectRow, len(st.projects)),
+		teams:         make(map[string]teamRow, len(st.teams)),
+		members:       make(map[string]memberRow, len(st.members)),
+		tasks:         make(map[string]taskRow, len(st.tasks)),
+		joinRequests:  make(map[string]joinRequestRow, len(st.joinRequests)),
+		sessions:      make(map[string]sessionRow, len(st.sessions)),
+		refreshTokens: make(map[string]auth.RefreshToken, len(st.refreshTokens)),
+		revokedTokens: make(map[string]time.Time, len(st.revokedTokens)),
+		accessTokens:  make(map[string]accessTokenRow, len(st.accessTokens)),
+		totp:          make(map[string]repository.TwoFactorSecret, len(st.totp)),
+		recoveryCodes: make(map[string]recoveryCodeRow, len(st.recoveryCodes)),
+		resetTokens:   make(map[string]resetTokenRow, len(st.resetTokens)),
+		throttle:      make(map[repository.ThrottleKey]repository.ThrottleState, len(st.throttle)),
+		reports:       make(map[string]reportRow, len(st.reports)),
+		audit:         append([]auditRow(nil), st.audit...),
+	}
+	for k, v := range st.users {
+		c.users[k] = v
+	}
+	for k, v := range st.projects {
+		c.projects[k] = v
+	}
+	for k, v := range st.teams {
+		c.teams[k] = v
+	}
+	for k, v := range st.members {
+		c.members[k] = v
+	}
+	for k, v := range st.tasks {
+		c.tasks[k] = v
+	}
+	for k, v := range st.joinRequests {
+		c.joinRequests[k] = v
+	}
+	for k, v := range st.sessions {
+		c.sessions[k] = v
+	}
+	for k, v := range st.refreshTokens {
+		c.refreshTokens[k] = v
+	}
+	for k, v := range st.revokedTokens {
+		c.revokedTokens[k] = v
+	}
+	for k, v := range st.accessTokens {
+		c.accessTokens[k] = v
+	}
+	for k, v := range st.totp {
+		c.totp[k] = v
+	}
+	for k, v := range st.recoveryCodes {
+		c.recoveryCodes[k] = v
+	}
+	for k, v := range st.resetTokens {
+		c.resetTokens[k] = v
+	}
+	for k, v := range st.throttle {
+		c.throttle[k] = v
+	}
+	for k, v := range st.reports {
+		c.reports[k] = v
+	}
+	return c
+}
+
+func (st *state) nextSeq() int64 {
+	st.seq++
+	return st.seq
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether any of the fields contains query, ignoring case
+func containsFold(query string, fields ...string) bool {
+	query = strings.ToLower(query)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate applies a LIMIT and OFFSET to an already sorted slice
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// keysetPage sorts items by (created_at, id) in the direction of the page
+// and returns up to page.Limit of them past page.After
+func keysetPage[T any](items []T, key func(T) repository.Cursor, page repository.Page) []T {
+	sort.SliceStable(items, func(i, j int) bool {
+		c := compareCursors(key(items[i]), key(items[j]))
+		if page.Ascending {
+			return c < 0
+		}
+		return c > 0
+	})
+
+	selected := items[:0]
+	for _, item := range items {
+		if len(selected) == page.Limit {
+			break
+		}
+		if page.After != nil {
+			c := compareCursors(key(item), *page.After)
+			if (page.Ascending && c <= 0) || (!page.Ascending && c >= 0) {
+				continue
+			}
+		}
+		selected = append(selected, item)
+	}
+	return selected
+}
+
+// compareCursors orders positions by time, then by ID
+func compareCursors(a, b repository.Cursor) int {
+	if c := compareTimes(a.CreatedAt, b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// compareTimes compares two RFC 3339 timestamps. They are parsed because
+// strings of different precision or offset do not sort as text.
+func compareTimes(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return ta.Compare(tb)
+}
+
+// sortBySeq orders rows oldest first, or newest first when desc is set
+func sortBySeq[T any](items []T, seq func(T) int64, desc bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return seq(items[i]) > seq(items[j])
+		}
+		return seq(items[i]) < seq(items[j])
+	})
+}
+
+// foreignKeyError mirrors the error Postgres returns for a missing reference
+func foreignKeyError(table, column, value string) error {
+	return fmt.Errorf("insert or update on table %q violates foreign key constraint: %s %s does not exist", table, column, value)
+}
@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type taskRepository struct {
+	db *db
+}
+
+// toTask returns a copy of the task joined with its project title and
+// assignee username
+func (st *state) toTask(row taskRow) *model.Task {
+	task := row.task
+	task.Project = &model.Project{ID: row.projectID, Title: st.projects[row.projectID].project.Title}
+	task.Assignee = nil
+	if row.assigneeID != nil {
+		task.Assignee = &model.User{ID: *row.assigneeID, Username: st.users[*row.assigneeID].user.Username}
+	}
+	return &task
+}
+
+func (st *state) selectTasks(match func(taskRow) bool, less func(a, b taskRow) bool) []*model.Task {
+	var rows []taskRow
+	for _, row := range st.tasks {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+
+	tasks := []*model.Task{}
+	for _, row := range rows {
+		tasks = append(tasks, st.toTask(row))
+	}
+	return tasks
+}
+
+func newestTaskFirst(a, b taskRow) bool {
+	return a.seq > b.seq
+}
+
+// checkTaskReferences mirrors the foreign keys of the tasks table
+func (st *state) checkTaskReferences(projectID string, assigneeID *string) error {
+	if _, ok := st.projects[projectID]; !ok {
+		return foreignKeyError("tasks", "project_id", projectID)
+	}
+	if assigneeID != nil {
+		if _, ok := st.users[*assigneeID]; !ok {
+			return foreignKeyError("tasks", "assignee_id", *assigneeID)
+		}
+	}
+	return nil
+}
+
+func taskAssigneeID(task *model.Task) *string {
+	if task.Assignee == nil {
+		return nil
+	}
+	id := task.Assignee.ID
+	return &id
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *model.Task) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	assigneeID := taskAssigneeID(task)
+	if err := st.checkTaskReferences(task.Project.ID, assigneeID); err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+
+	task.ID = uuid.New().String()
+	row := *task
+	row.Project = nil
+	row.Assignee = nil
+	st.tasks[task.ID] = taskRow{task: row, projectID: task.Project.ID, assigneeID: assigneeID, seq: st.nextSeq()}
+	return nil
+}
+
+func (r *taskRepository) GetByID(ctx context.Context, id string) (*model.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.tasks[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return st.toTask(row), nil
+}
+
+// withTask passes the stored row of a task to fn and saves any changes
+func (r *taskRepository) withTask(id string, fn func(*taskRow) error) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.tasks[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if err := fn(&row); err != nil {
+		return err
+	}
+	st.tasks[id] = row
+	return nil
+}
+
+func (r *taskRepository) Update(ctx context.Context, task *model.Task) error {
+	return r.withTask(task.ID, func(row *taskRow) error {
+		assigneeID := taskAssigneeID(task)
+		if err := r.db.state.checkTaskReferences(row.projectID, assigneeID); err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		row.task.Title = task.Title
+		row.task.Description = task.Description
+		row.task.Status = task.Status
+		row.task.Priority = task.Priority
+		row.task.DueDate = task.DueDate
+		row.task.UpdatedAt = task.UpdatedAt
+		row.assigneeID = assigneeID
+		return nil
+	})
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.tasks[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(st.tasks, id)
+	return nil
+}
+
+func (r *taskRepository) ByProject(ctx context.Context, projectID string, status *model.TaskStatus, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	tasks := st.selectTasks(st.taskFilter(repository.TaskFilter{ProjectID: &projectID, Status: status, Viewer: &viewer}), newestTaskFirst)
+	return paginate(tasks, limit, offset), nil
+}
+
+// ByAssignee orders tasks by due date with undated tasks last, then by
+// priority, like the Postgres query
+func (r *taskRepository) ByAssignee(ctx context.Context, userID string, status *model.TaskStatus, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	tasks := st.selectTasks(st.taskFilter(repository.TaskFilter{AssigneeID: &userID, Status: status, Viewer: &viewer}), func(a, b taskRow) bool {
+		da, db := a.task.DueDate, b.task.DueDate
+		switch {
+		case da == nil && db != nil:
+			return false
+		case da != nil && db == nil:
+			return true
+		case da != nil && db != nil && *da != *db:
+			return *da < *db
+		}
+		return a.task.Priority > b.task.Priority
+	})
+	return paginate(tasks, limit, offset), nil
+}
+
+func (r *taskRepository) Search(ctx context.Context, query string, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	visible := st.taskFilter(repository.TaskFilter{Viewer: &viewer})
+	tasks := st.selectTasks(func(row taskRow) bool {
+		if !visible(row) {
+			return false
+		}
+		description := ""
+		if row.task.Description != nil {
+			description = *row.task.Description
+		}
+		return containsFold(query, row.task.Title, description)
+	}, newestTaskFirst)
+	return paginate(tasks, limit, offset), nil
+}
+
+func (st *state) taskFilter(filter repository.TaskFilter) func(taskRow) bool {
+	return func(row taskRow) bool {
+		if filter.ProjectID != nil && row.projectID != *filter.ProjectID {
+			return false
+		}
+		if filter.AssigneeID != nil && (row.assigneeID == nil || *row.assigneeID != *filter.AssigneeID) {
+			return false
+		}
+		if filter.Viewer != nil && !visibleTo(st.projects[row.projectID], *filter.Viewer) {
+			return false
+		}
+		return filter.Status == nil || row.task.Status == *filter.Status
+	}
+}
+
+func (r *taskRepository) ListPage(ctx context.Context, filter repository.TaskFilter, page repository.Page) ([]*model.Task, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	st := r.db.state
+
+	tasks := st.selectTasks(st.taskFilter(filter), newestTaskFirst)
+	return keysetPage(tasks, func(t *model.Task) repository.Cursor {
+		return repository.Cursor{CreatedAt: t.CreatedAt, ID: t.ID}
+	}, page), nil
+}
+
+func (r *taskRepository) Count(ctx context.Context, filter repository.TaskFilter) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	st := r.db.state
+	return len(st.selectTasks(st.taskFilter(filter), newestTaskFirst)), nil
+}
+
+func (r *taskRepository) SetAssignee(ctx context.Context, id string, userID *string) error {
+	return r.withTask(id, func(row *taskRow) error {
+		if err := r.db.state.checkTaskReferences(row.projectID, userID); err != nil {
+			return fmt.Errorf("failed to assign task: %w", err)
+		}
+		if userID != nil {
+			assignee := *userID
+			userID = &assignee
+		}
+		row.assigneeID = userID
+		row.task.UpdatedAt = now()
+		return nil
+	})
+}
+
+func (r *taskRepository) UpdateStatus(ctx context.Context, id string, status model.TaskStatus) error {
+	return r.withTask(id, func(row *taskRow) error {
+		row.task.Status = status
+		row.task.UpdatedAt = now()
+		return nil
+	})
+}
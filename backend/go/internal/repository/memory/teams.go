@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type teamRepository struct {
+	db *db
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *model.Team, projectID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, exists := st.teams[team.ID]; exists {
+		return fmt.Errorf("failed to insert team: duplicate id %s", team.ID)
+	}
+	if _, ok := st.projects[projectID]; !ok {
+		return fmt.Errorf("failed to insert team: %w", foreignKeyError("teams", "project_id", projectID))
+	}
+
+	row := *team
+	row.Project = nil
+	row.Members = nil
+	st.teams[team.ID] = teamRow{team: row, projectID: projectID, seq: st.nextSeq()}
+	return nil
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id string) (*model.Team, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.teams[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	project, ok := st.projects[row.projectID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	team := row.team
+	team.Project = &model.Project{ID: project.project.ID, Title: project.project.Title}
+	return &team, nil
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *model.Team) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.teams[team.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	row.team.Name = team.Name
+	row.team.Description = team.Description
+	row.team.UpdatedAt = team.UpdatedAt
+	st.teams[team.ID] = row
+	return nil
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.state.deleteTeam(id)
+	return nil
+}
+
+// deleteTeam removes a team and its members
+func (st *state) deleteTeam(id string) {
+	for memberID, m := range st.members {
+		if m.teamID == id {
+			delete(st.members, memberID)
+		}
+	}
+	delete(st.teams, id)
+}
+
+func (r *teamRepository) ByProject(ctx context.Context, projectID string) ([]*model.Team, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var rows []teamRow
+	for _, row := range r.db.state.teams {
+		if row.projectID == projectID {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row teamRow) int64 { return row.seq }, false)
+
+	teams := []*model.Team{}
+	for _, row := range rows {
+		team := row.team
+		teams = append(teams, &team)
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) Members(ctx context.Context, teamID string) ([]*model.TeamMember, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.state.selectMembers(func(m memberRow) bool { return m.teamID == teamID }), nil
+}
+
+func (r *teamRepository) ProjectMembers(ctx context.Context, projectID string) ([]*model.TeamMember, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	return st.selectMembers(func(m memberRow) bool { return st.teams[m.teamID].projectID == projectID }), nil
+}
+
+func (r *teamRepository) DefaultByProjects(ctx context.Context, projectIDs []string) (map[string]*model.Team, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// The default team is the first one created for the project
+	first := map[string]teamRow{}
+	for _, row := range r.db.state.teams {
+		if !containsString(projectIDs, row.projectID) {
+			continue
+		}
+		if current, ok := first[row.projectID]; !ok || row.seq < current.seq {
+			first[row.projectID] = row
+		}
+	}
+
+	teams := map[string]*model.Team{}
+	for projectID, row := range first {
+		team := row.team
+		team.Project = &model.Project{ID: projectID}
+		teams[projectID] = &team
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) MembersByTeams(ctx context.Context, teamIDs []string) (map[string][]*model.TeamMember, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	return st.groupMembers(teamIDs, func(m memberRow) string { return m.teamID }), nil
+}
+
+func (r *teamRepository) MembersByProjects(ctx context.Context, projectIDs []string) (map[string][]*model.TeamMember, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	return st.groupMembers(projectIDs, func(m memberRow) string { return st.teams[m.teamID].projectID }), nil
+}
+
+// groupMembers returns the members whose key is one of keys, grouped by key
+func (st *state) groupMembers(keys []string, key func(memberRow) string) map[string][]*model.TeamMember {
+	members := map[string][]*model.TeamMember{}
+	for _, k := range keys {
+		if found := st.selectMembers(func(m memberRow) bool { return key(m) == k }); len(found) > 0 {
+			members[k] = found
+		}
+	}
+	return members
+}
+
+// selectMembers returns the matching members joined with their users, in the
+// order they joined
+func (st *state) selectMembers(match func(memberRow) bool) []*model.TeamMember {
+	var rows []memberRow
+	for _, m := range st.members {
+		if match(m) {
+			rows = append(rows, m)
+		}
+	}
+	sortBySeq(rows, func(m memberRow) int64 { return m.seq }, false)
+
+	members := []*model.TeamMember{}
+	for _, m := range rows {
+		user, ok := st.users[m.userID]
+		if !ok {
+			continue
+		}
+		full := toUser(user)
+		members = append(members, &model.TeamMember{
+			ID:       m.id,
+			Role:     m.role,
+			JoinedAt: m.joinedAt,
+			User: &model.User{
+				ID:          full.ID,
+				Username:    full.Username,
+				Email:       full.Email,
+				FirstName:   full.FirstName,
+				LastName:    full.LastName,
+				Role:        full.Role,
+				SuspendedAt: full.SuspendedAt,
+			},
+		})
+	}
+	return members
+}
+
+func (st *state) findMember(teamID, userID string) (memberRow, bool) {
+	for _, m := range st.members {
+		if m.teamID == teamID && m.userID == userID {
+			return m, true
+		}
+	}
+	return memberRow{}, false
+}
+
+func (r *teamRepository) IsMember(ctx context.Context, teamID, userID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	_, ok := r.db.state.findMember(teamID, userID)
+	return ok, nil
+}
+
+func (r *teamRepository) IsProjectMember(ctx context.Context, projectID, userID string) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	for _, m := range st.members {
+		if m.userID == userID && st.teams[m.teamID].projectID == projectID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *teamRepository) AddMember(ctx context.Context, teamID string, member *model.TeamMember) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.teams[teamID]; !ok {
+		return fmt.Errorf("failed to add team member: %w", foreignKeyError("team_members", "team_id", teamID))
+	}
+	if _, ok := st.users[member.User.ID]; !ok {
+		return fmt.Errorf("failed to add team member: %w", foreignKeyError("team_members", "user_id", member.User.ID))
+	}
+	if _, ok := st.findMember(teamID, member.User.ID); ok {
+		return fmt.Errorf("failed to add team member: duplicate key value violates unique constraint \"team_members_team_id_user_id_key\"")
+	}
+
+	joinedAt := member.JoinedAt
+	if joinedAt == "" {
+		joinedAt = now()
+	}
+	st.members[member.ID] = memberRow{
+		id:       member.ID,
+		teamID:   teamID,
+		userID:   member.User.ID,
+		role:     member.Role,
+		joinedAt: joinedAt,
+		seq:      st.nextSeq(),
+	}
+	return nil
+}
+
+func (r *teamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	m, ok := st.findMember(teamID, userID)
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delete(st.members, m.id)
+	return nil
+}
+
+func (r *teamRepository) UpdateMemberRole(ctx context.Context, teamID, userID, role string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	m, ok := st.findMember(teamID, userID)
+	if !ok {
+		return repository.ErrNotFound
+	}
+	m.role = role
+	st.members[m.id] = m
+	return nil
+}
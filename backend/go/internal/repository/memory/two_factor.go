@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type twoFactorRepository struct {
+	db *db
+}
+
+func (r *twoFactorRepository) SetSecret(ctx context.Context, userID, secret string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[userID]; !ok {
+		return fmt.Errorf("failed to store TOTP secret: %w", foreignKeyError("user_totp", "user_id", userID))
+	}
+	if existing, ok := st.totp[userID]; ok && existing.Confirmed {
+		return nil
+	}
+	st.totp[userID] = repository.TwoFactorSecret{Secret: secret}
+	return nil
+}
+
+func (r *twoFactorRepository) Secret(ctx context.Context, userID string) (*repository.TwoFactorSecret, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	secret, ok := r.db.state.totp[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &secret, nil
+}
+
+func (r *twoFactorRepository) Confirm(ctx context.Context, userID string, step int64) error {
+	return r.modify(userID, func(secret *repository.TwoFactorSecret) {
+		secret.Confirmed = true
+		secret.LastUsedStep = &step
+	})
+}
+
+func (r *twoFactorRepository) SetLastUsedStep(ctx context.Context, userID string, step int64) error {
+	return ignoreNotFound(r.modify(userID, func(secret *repository.TwoFactorSecret) {
+		secret.LastUsedStep = &step
+	}))
+}
+
+func (r *twoFactorRepository) modify(userID string, fn func(*repository.TwoFactorSecret)) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	secret, ok := st.totp[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	fn(&secret)
+	st.totp[userID] = secret
+	return nil
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, userID string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	st.deleteRecoveryCodes(userID)
+	delete(st.totp, userID)
+	return nil
+}
+
+func (st *state) deleteRecoveryCodes(userID string) {
+	for id, code := range st.recoveryCodes {
+		if code.userID == userID {
+			delete(st.recoveryCodes, id)
+		}
+	}
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	st.deleteRecoveryCodes(userID)
+	for _, hash := range codeHashes {
+		st.recoveryCodes[uuid.New().String()] = recoveryCodeRow{userID: userID, codeHash: hash}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	for id, code := range st.recoveryCodes {
+		if code.userID == userID && code.codeHash == codeHash && !code.used {
+			code.used = true
+			st.recoveryCodes[id] = code
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
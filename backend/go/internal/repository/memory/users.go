@@ -0,0 +1,348 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type userRepository struct {
+	db *db
+}
+
+func toUser(row userRow) *model.User {
+	user := row.user
+	user.Role = row.role
+	if row.suspendedAt != nil {
+		suspendedAt := *row.suspendedAt
+		user.SuspendedAt = &suspendedAt
+	}
+	user.Skills = cloneStrings(user.Skills)
+	user.Certifications = cloneStrings(user.Certifications)
+	user.Languages = cloneStrings(user.Languages)
+	user.ProjectPreferences = cloneStrings(user.ProjectPreferences)
+	return &user
+}
+
+// storedUser copies the columns of the users table out of a model.User
+func storedUser(user *model.User) model.User {
+	u := *user
+	u.Role = ""
+	u.SuspendedAt = nil
+	u.Projects = nil
+	u.OwnedProjects = nil
+	u.Skills = cloneStrings(user.Skills)
+	u.Certifications = cloneStrings(user.Certifications)
+	u.Languages = cloneStrings(user.Languages)
+	u.ProjectPreferences = cloneStrings(user.ProjectPreferences)
+	return u
+}
+
+// checkUnique mirrors the unique constraints on username and email
+func (st *state) checkUnique(user *model.User) error {
+	for id, row := range st.users {
+		if id == user.ID {
+			continue
+		}
+		if row.user.Username == user.Username {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_username_key\"")
+		}
+		if row.user.Email == user.Email {
+			return fmt.Errorf("duplicate key value violates unique constraint \"users_email_key\"")
+		}
+	}
+	return nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *model.User, passwordHash []byte) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if err := st.checkUnique(user); err != nil {
+		return err
+	}
+
+	user.ID = uuid.New().String()
+	st.users[user.ID] = userRow{
+		user:         storedUser(user),
+		passwordHash: append([]byte(nil), passwordHash...),
+		role:         model.UserRoleUser,
+		seq:          st.nextSeq(),
+	}
+	return nil
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.ID == id })
+}
+
+func (r *userRepository) ListByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	return r.selectUsers(func(u model.User) bool { return containsString(ids, u.ID) }, -1, 0), nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.Email == email })
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.Username == username })
+}
+
+func (r *userRepository) find(match func(model.User) bool) (*model.User, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, row := range r.db.state.users {
+		if match(row.user) {
+			return toUser(row), nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// withUser passes the stored row of a user to fn and saves any changes
+func (r *userRepository) withUser(id string, fn func(*userRow) error) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	row, ok := st.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if err := fn(&row); err != nil {
+		return err
+	}
+	st.users[id] = row
+	return nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	return r.withUser(user.ID, func(row *userRow) error {
+		if err := r.db.state.checkUnique(user); err != nil {
+			return err
+		}
+
+		updated := storedUser(user)
+		// Columns the UPDATE statement does not touch
+		updated.LastActive = row.user.LastActive
+		updated.JoinedAt = row.user.JoinedAt
+		updated.CreatedAt = row.user.CreatedAt
+		row.user = updated
+		return nil
+	})
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	st := r.db.state
+
+	if _, ok := st.users[id]; !ok {
+		return nil
+	}
+
+	// Follow the ON DELETE rules of the schema
+	for projectID, project := range st.projects {
+		if project.ownerID == id {
+			project.ownerID = ""
+			st.projects[projectID] = project
+		}
+	}
+	for memberID, m := range st.members {
+		if m.userID == id {
+			delete(st.members, memberID)
+		}
+	}
+	for jrID, jr := range st.joinRequests {
+		if jr.userID == id {
+			delete(st.joinRequests, jrID)
+		}
+	}
+	for taskID, task := range st.tasks {
+		if task.assigneeID != nil && *task.assigneeID == id {
+			task.assigneeID = nil
+			st.tasks[taskID] = task
+		}
+	}
+	for sessionID, session := range st.sessions {
+		if session.userID == id {
+			delete(st.sessions, sessionID)
+		}
+	}
+	for hash, token := range st.refreshTokens {
+		if _, ok := st.sessions[token.SessionID]; !ok {
+			delete(st.refreshTokens, hash)
+		}
+	}
+	for tokenID, token := range st.accessTokens {
+		if token.token.UserID == id {
+			delete(st.accessTokens, tokenID)
+		}
+	}
+	for hash, token := range st.resetTokens {
+		if token.userID == id {
+			delete(st.resetTokens, hash)
+		}
+	}
+	delete(st.totp, id)
+	st.deleteRecoveryCodes(id)
+	for reportID, report := range st.reports {
+		if report.reporterID == id {
+			delete(st.reports, reportID)
+		} else if report.resolvedBy != nil && *report.resolvedBy == id {
+			report.resolvedBy = nil
+			st.reports[reportID] = report
+		}
+	}
+	delete(st.users, id)
+	return nil
+}
+
+func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	return r.selectUsers(func(model.User) bool { return true }, limit, offset), nil
+}
+
+func (r *userRepository) ListPage(ctx context.Context, page repository.Page) ([]*model.User, error) {
+	users := r.selectUsers(func(model.User) bool { return true }, -1, 0)
+	return keysetPage(users, func(u *model.User) repository.Cursor {
+		return repository.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+	}, page), nil
+}
+
+func (r *userRepository) Count(ctx context.Context) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+	return len(r.db.state.users), nil
+}
+
+func (r *userRepository) Search(ctx context.Context, query string) ([]*model.User, error) {
+	return r.selectUsers(func(u model.User) bool {
+		return containsFold(query, u.Username, u.Email, u.FirstName, u.LastName)
+	}, -1, 0), nil
+}
+
+// selectUsers returns the matching users newest first
+func (r *userRepository) selectUsers(match func(model.User) bool, limit, offset int) []*model.User {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var rows []userRow
+	for _, row := range r.db.state.users {
+		if match(row.user) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row userRow) int64 { return row.seq }, true)
+
+	users := []*model.User{}
+	for _, row := range paginate(rows, limit, offset) {
+		users = append(users, toUser(row))
+	}
+	return users
+}
+
+func (r *userRepository) PasswordHash(ctx context.Context, id string) ([]byte, error) {
+	var hash []byte
+	err := r.withUser(id, func(row *userRow) error {
+		hash = append([]byte(nil), row.passwordHash...)
+		return nil
+	})
+	return hash, err
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id string, hash []byte) error {
+	return r.withUser(id, func(row *userRow) error {
+		row.passwordHash = append([]byte(nil), hash...)
+		row.user.UpdatedAt = now()
+		return nil
+	})
+}
+
+// The statements below succeed without touching anything when the user does
+// not exist, as the UPDATEs they mirror do
+
+func (r *userRepository) SetEmailVerified(ctx context.Context, id string) error {
+	return ignoreNotFound(r.withUser(id, func(row *userRow) error {
+		row.user.EmailVerified = true
+		return nil
+	}))
+}
+
+func (r *userRepository) UpdateLastActive(ctx context.Context, id string) error {
+	return ignoreNotFound(r.withUser(id, func(row *userRow) error {
+		row.user.LastActive = now()
+		return nil
+	}))
+}
+
+func (r *userRepository) AddSkill(ctx context.Context, id, skill string) error {
+	return ignoreNotFound(r.withUser(id, func(row *userRow) error {
+		row.user.Skills = append(cloneStrings(row.user.Skills), skill)
+		return nil
+	}))
+}
+
+func (r *userRepository) RemoveSkill(ctx context.Context, id, skill string) error {
+	return ignoreNotFound(r.withUser(id, func(row *userRow) error {
+		remaining := []string{}
+		for _, s := range row.user.Skills {
+			if s != skill {
+				remaining = append(remaining, s)
+			}
+		}
+		row.user.Skills = remaining
+		return nil
+	}))
+}
+
+func (r *userRepository) Role(ctx context.Context, id string) (model.UserRole, error) {
+	var role model.UserRole
+	err := r.withUser(id, func(row *userRow) error {
+		role = row.role
+		return nil
+	})
+	return role, err
+}
+
+func (r *userRepository) SuspendedAt(ctx context.Context, id string) (*string, error) {
+	var suspendedAt *string
+	err := r.withUser(id, func(row *userRow) error {
+		suspendedAt = row.suspendedAt
+		return nil
+	})
+	return suspendedAt, err
+}
+
+func (r *userRepository) SetRole(ctx context.Context, id string, role model.UserRole) error {
+	return r.withUser(id, func(row *userRow) error {
+		row.role = role
+		return nil
+	})
+}
+
+func (r *userRepository) Suspend(ctx context.Context, id, reason string) error {
+	return r.withUser(id, func(row *userRow) error {
+		suspendedAt := now()
+		row.suspendedAt = &suspendedAt
+		return nil
+	})
+}
+
+func (r *userRepository) Unsuspend(ctx context.Context, id string) error {
+	return r.withUser(id, func(row *userRow) error {
+		row.suspendedAt = nil
+		return nil
+	})
+}
+
+func ignoreNotFound(err error) error {
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	return err
+}
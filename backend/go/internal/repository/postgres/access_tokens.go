@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type accessTokenRepository struct {
+	q querier
+}
+
+const accessTokenColumns = `id, user_id, name, scopes, expires_at, last_used_at, created_at`
+
+func scanAccessToken(row rowScanner) (*repository.AccessToken, error) {
+	var token repository.AccessToken
+	var expiresAt, lastUsedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, pq.Array(&token.Scopes), &expiresAt, &lastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *repository.AccessToken, tokenHash string) error {
+	token.ID = uuid.New().String()
+	token.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO personal_access_tokens (id, user_id, name, token_hash, scopes, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.q.ExecContext(ctx, query,
+		token.ID, token.UserID, token.Name, tokenHash, pq.Array(token.Scopes), token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create personal access token: %w", err)
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) ListByUser(ctx context.Context, userID string) ([]*repository.AccessToken, error) {
+	query := `SELECT ` + accessTokenColumns + ` FROM personal_access_tokens
+			  WHERE user_id = $1 AND revoked_at IS NULL
+			  ORDER BY created_at DESC`
+	rows, err := r.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*repository.AccessToken{}
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan personal access token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) Revoke(ctx context.Context, userID, id string) error {
+	query := `UPDATE personal_access_tokens SET revoked_at = $1
+			  WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+	result, err := r.q.ExecContext(ctx, query, time.Now().UTC(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *accessTokenRepository) RevokeAll(ctx context.Context, userID string) error {
+	query := `UPDATE personal_access_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	if _, err := r.q.ExecContext(ctx, query, time.Now().UTC(), userID); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+	return nil
+}
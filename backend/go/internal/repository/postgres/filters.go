@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/lib/pq"
+)
+
+// conditions accumulates the WHERE clause of a query. Clauses are fixed
+// strings written in this file; values only ever travel as parameters.
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// add appends a clause whose placeholders for values are written as %s
+func (c *conditions) add(clause string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		c.args = append(c.args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(c.args))
+	}
+	c.clauses = append(c.clauses, fmt.Sprintf(clause, placeholders...))
+}
+
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// projectConditions returns the conditions selecting the visible projects
+// that match the filter. Queries using them join project_owners as po.
+func projectConditions(filter repository.ProjectFilter) *conditions {
+	c := &conditions{clauses: []string{"p.hidden_at IS NULL"}}
+
+	if len(filter.Categories) > 0 {
+		c.add("p.category = ANY(%s)", pq.Array(filter.Categories))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		c.add("p.status = ANY(%s)", pq.Array(statuses))
+	}
+	if len(filter.Technologies) > 0 {
+		if filter.AllTechnologies {
+			c.add("p.technologies @> %s::text[]", pq.Array(filter.Technologies))
+		} else {
+			c.add("p.technologies && %s::text[]", pq.Array(filter.Technologies))
+		}
+	}
+	if filter.MinOpenPositions != nil {
+		c.add("p.open_positions >= %s", *filter.MinOpenPositions)
+	}
+	if filter.MaxOpenPositions != nil {
+		c.add("p.open_positions <= %s", *filter.MaxOpenPositions)
+	}
+	if filter.TimeCommitment != nil {
+		c.add("p.time_commitment = %s", *filter.TimeCommitment)
+	}
+	if filter.CreatedAfter != nil {
+		c.add("p.created_at >= %s::timestamptz", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		c.add("p.created_at < %s::timestamptz", *filter.CreatedBefore)
+	}
+	if filter.OwnerID != nil {
+		c.add("po.user_id = %s::uuid", *filter.OwnerID)
+	}
+	if filter.LearningObjective != nil {
+		c.add(`EXISTS (
+			SELECT 1 FROM unnest(p.learning_objectives) AS objective
+			WHERE objective ILIKE %s
+		)`, "%"+escapeLike(*filter.LearningObjective)+"%")
+	}
+	if filter.Query != "" {
+		c.add(searchMatch, prefixQuery(filter.Query), filter.Query)
+	}
+	return c
+}
+
+// projectOrders lists the ORDER BY clause of every project sort
+var projectOrders = map[model.ProjectSort]string{
+	model.ProjectSortNewest:        "p.created_at DESC, p.id DESC",
+	model.ProjectSortPopularity:    "p.popularity DESC, p.created_at DESC, p.id DESC",
+	model.ProjectSortOpenPositions: "p.open_positions DESC, p.created_at DESC, p.id DESC",
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
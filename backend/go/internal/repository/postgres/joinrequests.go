@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type joinRequestRepository struct {
+	q querier
+}
+
+const joinRequestQuery = `
+	SELECT jr.id, jr.status, jr.created_at,
+		   u.id, u.username,
+		   p.id, p.title
+	FROM join_requests jr
+	JOIN users u ON jr.user_id = u.id
+	JOIN projects p ON jr.project_id = p.id`
+
+func scanJoinRequest(row rowScanner) (*model.JoinRequest, error) {
+	jr := &model.JoinRequest{User: &model.User{}, Project: &model.Project{}}
+	err := row.Scan(
+		&jr.ID, &jr.Status, &jr.CreatedAt,
+		&jr.User.ID, &jr.User.Username,
+		&jr.Project.ID, &jr.Project.Title,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return jr, nil
+}
+
+func (r *joinRequestRepository) Create(ctx context.Context, projectID, userID string) error {
+	_, err := r.q.ExecContext(ctx, `INSERT INTO join_requests (project_id, user_id, status) VALUES ($1, $2, $3)`,
+		projectID, userID, model.JoinRequestStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to create join request: %w", err)
+	}
+	return nil
+}
+
+func (r *joinRequestRepository) GetByID(ctx context.Context, id string) (*model.JoinRequest, error) {
+	jr, err := scanJoinRequest(r.q.QueryRowContext(ctx, joinRequestQuery+` WHERE jr.id = $1`, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return jr, nil
+}
+
+func (r *joinRequestRepository) GetByUserAndProject(ctx context.Context, userID, projectID string) (*model.JoinRequest, error) {
+	query := joinRequestQuery + ` WHERE jr.user_id = $1 AND jr.project_id = $2`
+	jr, err := scanJoinRequest(r.q.QueryRowContext(ctx, query, userID, projectID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return jr, nil
+}
+
+func (r *joinRequestRepository) ByProject(ctx context.Context, projectID string) ([]*model.JoinRequest, error) {
+	return r.queryJoinRequests(ctx, joinRequestQuery+` WHERE jr.project_id = $1 ORDER BY jr.created_at`, projectID)
+}
+
+// joinRequestFilters returns the WHERE clause selecting a project's
+// requests, optionally with one status, with its args
+func joinRequestFilters(projectID string, status *model.JoinRequestStatus) (string, []interface{}) {
+	if status == nil {
+		return ` WHERE jr.project_id = $1`, []interface{}{projectID}
+	}
+	return ` WHERE jr.project_id = $1 AND jr.status = $2`, []interface{}{projectID, *status}
+}
+
+func (r *joinRequestRepository) ListPage(ctx context.Context, projectID string, status *model.JoinRequestStatus, page repository.Page) ([]*model.JoinRequest, error) {
+	conditions, args := joinRequestFilters(projectID, status)
+	after, order, args := keyset("jr.created_at", "jr.id", page, args)
+	return r.queryJoinRequests(ctx, joinRequestQuery+conditions+after+order, args...)
+}
+
+func (r *joinRequestRepository) Count(ctx context.Context, projectID string, status *model.JoinRequestStatus) (int, error) {
+	conditions, args := joinRequestFilters(projectID, status)
+	return count(ctx, r.q, "join requests", `
+		SELECT count(*)
+		FROM join_requests jr
+		JOIN users u ON jr.user_id = u.id
+		JOIN projects p ON jr.project_id = p.id`+conditions, args...)
+}
+
+func (r *joinRequestRepository) queryJoinRequests(ctx context.Context, query string, args ...interface{}) ([]*model.JoinRequest, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query join requests: %w", err)
+	}
+	defer rows.Close()
+
+	joinRequests := []*model.JoinRequest{}
+	for rows.Next() {
+		jr, err := scanJoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan join request: %w", err)
+		}
+		joinRequests = append(joinRequests, jr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating join requests: %w", err)
+	}
+	return joinRequests, nil
+}
+
+func (r *joinRequestRepository) UpdateStatus(ctx context.Context, id string, status model.JoinRequestStatus) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE join_requests SET status = $1 WHERE id = $2`, status, id)
+	if err != nil {
+		return fmt.Errorf("failed to update join request status: %w", err)
+	}
+	return checkAffected(result)
+}
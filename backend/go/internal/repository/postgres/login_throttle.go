@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type loginThrottleRepository struct {
+	q querier
+}
+
+func (r *loginThrottleRepository) Get(ctx context.Context, keys ...repository.ThrottleKey) ([]*repository.ThrottleState, error) {
+	states := []*repository.ThrottleState{}
+	for _, key := range keys {
+		state := &repository.ThrottleState{ThrottleKey: key}
+		var lockedUntil sql.NullTime
+		err := r.q.QueryRowContext(ctx,
+			`SELECT failures, last_failure_at, locked_until FROM login_throttle WHERE scope = $1 AND key = $2`,
+			key.Scope, key.Key,
+		).Scan(&state.Failures, &state.LastFailureAt, &lockedUntil)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to check login throttle: %w", err)
+		}
+		if lockedUntil.Valid {
+			state.LockedUntil = &lockedUntil.Time
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key repository.ThrottleKey, at, resetBefore time.Time) (int, error) {
+	query := `
+		INSERT INTO login_throttle (scope, key, failures, last_failure_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN login_throttle.last_failure_at < $4 THEN 1
+				ELSE login_throttle.failures + 1
+			END,
+			last_failure_at = $3
+		RETURNING failures
+	`
+	var failures int
+	if err := r.q.QueryRowContext(ctx, query, key.Scope, key.Key, at, resetBefore).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, key repository.ThrottleKey, until time.Time) error {
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE login_throttle SET locked_until = $1, failures = 0 WHERE scope = $2 AND key = $3`,
+		until, key.Scope, key.Key)
+	if err != nil {
+		return fmt.Errorf("failed to lock out login: %w", err)
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) Clear(ctx context.Context, key repository.ThrottleKey) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM login_throttle WHERE scope = $1 AND key = $2`, key.Scope, key.Key)
+	if err != nil {
+		return fmt.Errorf("failed to clear login throttle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/google/uuid"
+)
+
+type moderationReportRepository struct {
+	q querier
+}
+
+const moderationReportColumns = `
+	id, target_type, target_id, reason, status, reporter_id, resolved_by, resolution_note, created_at, resolved_at`
+
+func scanModerationReport(row rowScanner) (*model.ModerationReport, error) {
+	report := &model.ModerationReport{Reporter: &model.User{}}
+	var resolvedBy, resolutionNote sql.NullString
+	var createdAt time.Time
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(&report.ID, &report.TargetType, &report.TargetID, &report.Reason, &report.Status,
+		&report.Reporter.ID, &resolvedBy, &resolutionNote, &createdAt, &resolvedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	report.CreatedAt = createdAt.Format(time.RFC3339)
+	if resolvedBy.Valid {
+		report.ResolvedBy = &model.User{ID: resolvedBy.String}
+	}
+	if resolutionNote.Valid {
+		report.ResolutionNote = &resolutionNote.String
+	}
+	if resolvedAt.Valid {
+		t := resolvedAt.Time.Format(time.RFC3339)
+		report.ResolvedAt = &t
+	}
+	return report, nil
+}
+
+func (r *moderationReportRepository) Create(ctx context.Context, report *model.ModerationReport, reporterID string) error {
+	report.ID = uuid.New().String()
+	report.Status = model.ModerationReportStatusOpen
+	createdAt := time.Now().UTC()
+	report.CreatedAt = createdAt.Format(time.RFC3339)
+
+	query := `INSERT INTO moderation_reports (id, reporter_id, target_type, target_id, reason, status, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.q.ExecContext(ctx, query,
+		report.ID, reporterID, report.TargetType, report.TargetID, report.Reason, report.Status, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to create moderation report: %w", err)
+	}
+	return nil
+}
+
+func (r *moderationReportRepository) GetByID(ctx context.Context, id string) (*model.ModerationReport, error) {
+	query := `SELECT ` + moderationReportColumns + ` FROM moderation_reports WHERE id = $1`
+	report, err := scanModerationReport(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return report, nil
+}
+
+func (r *moderationReportRepository) List(ctx context.Context, status *model.ModerationReportStatus, limit, offset int) ([]*model.ModerationReport, error) {
+	query := `
+		SELECT ` + moderationReportColumns + `
+		FROM moderation_reports
+		WHERE ($1::text IS NULL OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	var statusFilter *string
+	if status != nil {
+		v := string(*status)
+		statusFilter = &v
+	}
+
+	rows, err := r.q.QueryContext(ctx, query, statusFilter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query moderation reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*model.ModerationReport{}
+	for rows.Next() {
+		report, err := scanModerationReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating moderation reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (r *moderationReportRepository) Resolve(ctx context.Context, id, adminID string, status model.ModerationReportStatus, note *string) error {
+	query := `UPDATE moderation_reports SET status = $1, resolved_by = $2, resolution_note = $3, resolved_at = $4
+			  WHERE id = $5`
+	result, err := r.q.ExecContext(ctx, query, status, adminID, note, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve moderation report: %w", err)
+	}
+	return checkAffected(result)
+}
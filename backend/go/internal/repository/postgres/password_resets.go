@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type passwordResetRepository struct {
+	q querier
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	now := time.Now().UTC()
+
+	// Only the most recent link should work
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`, now, userID)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+
+	query := `INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5)`
+	if _, err := r.q.ExecContext(ctx, query, uuid.New().String(), userID, tokenHash, expiresAt, now); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+	return nil
+}
+
+func (r *passwordResetRepository) Consume(ctx context.Context, tokenHash string) (string, error) {
+	query := `
+		UPDATE password_reset_tokens SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id
+	`
+	var userID string
+	if err := r.q.QueryRowContext(ctx, query, time.Now().UTC(), tokenHash).Scan(&userID); err != nil {
+		return "", notFound(err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,486 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/lib/pq"
+)
+
+type projectRepository struct {
+	q querier
+}
+
+// projectColumns is scanned by scanProject, followed by the owner's id,
+// username and email
+const projectColumns = `
+	p.id, p.title, p.description, p.category, p.status, p.technologies,
+	p.open_positions, p.time_commitment, p.learning_objectives, p.popularity,
+	p.timeline, p.created_at, p.updated_at,
+	u.id, u.username, u.email`
+
+const projectJoins = `
+	FROM projects p
+	JOIN project_owners po ON p.id = po.project_id
+	JOIN users u ON po.user_id = u.id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanProject(row rowScanner, extra ...interface{}) (*model.Project, error) {
+	project := &model.Project{
+		Owner:       &model.User{},
+		TeamMembers: []*model.TeamMember{},
+	}
+	var technologies, learningObjectives []string
+	var timeline sql.NullString
+
+	dest := []interface{}{
+		&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
+		pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
+		pq.Array(&learningObjectives), &project.Popularity, &timeline,
+		&project.CreatedAt, &project.UpdatedAt,
+		&project.Owner.ID, &project.Owner.Username, &project.Owner.Email,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	project.Technologies = technologies
+	project.LearningObjectives = learningObjectives
+	if timeline.Valid {
+		project.Timeline = &timeline.String
+	}
+	return project, nil
+}
+
+func (r *projectRepository) queryProjects(ctx context.Context, query string, args ...interface{}) ([]*model.Project, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*model.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project row: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating project rows: %w", err)
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) Create(ctx context.Context, project *model.Project, ownerID string) error {
+	query := `INSERT INTO projects (id, title, description, category, status, technologies, open_positions, time_commitment, learning_objectives, popularity, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.q.ExecContext(ctx, query,
+		project.ID, project.Title, project.Description, project.Category, project.Status,
+		pq.Array(project.Technologies), project.OpenPositions, project.TimeCommitment,
+		pq.Array(project.LearningObjectives), project.Popularity, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert project: %w", err)
+	}
+
+	_, err = r.q.ExecContext(ctx, `INSERT INTO project_owners (project_id, user_id) VALUES ($1, $2)`, project.ID, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to set project owner: %w", err)
+	}
+	return nil
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id string) (*model.Project, error) {
+	query := `SELECT ` + projectColumns + projectJoins + ` WHERE p.id = $1`
+
+	project, err := scanProject(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return project, nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *model.Project) error {
+	query := `
+		UPDATE projects
+		SET title = $1, description = $2, category = $3, status = $4,
+			open_positions = $5, time_commitment = $6,
+			learning_objectives = $7, updated_at = $8
+		WHERE id = $9
+	`
+	result, err := r.q.ExecContext(ctx, query,
+		project.Title, project.Description, project.Category, project.Status,
+		project.OpenPositions, project.TimeCommitment,
+		pq.Array(project.LearningObjectives), project.UpdatedAt, project.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM join_requests WHERE project_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete join requests: %w", err)
+	}
+
+	_, err = r.q.ExecContext(ctx, `
+		DELETE FROM team_members
+		WHERE team_id IN (SELECT id FROM teams WHERE project_id = $1)
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete team members: %w", err)
+	}
+
+	_, err = r.q.ExecContext(ctx, `DELETE FROM teams WHERE project_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete teams: %w", err)
+	}
+
+	_, err = r.q.ExecContext(ctx, `DELETE FROM project_owners WHERE project_id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project owners: %w", err)
+	}
+
+	result, err := r.q.ExecContext(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) List(ctx context.Context, filter repository.ProjectFilter, sort model.ProjectSort, limit, offset int) ([]*model.Project, error) {
+	order, ok := projectOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown project sort %q", sort)
+	}
+
+	c := projectConditions(filter)
+	query := `SELECT ` + projectColumns + projectJoins + c.where() +
+		fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", order, len(c.args)+1, len(c.args)+2)
+	return r.queryProjects(ctx, query, append(c.args, limit, offset)...)
+}
+
+func (r *projectRepository) ListPage(ctx context.Context, filter repository.ProjectFilter, page repository.Page) ([]*model.Project, error) {
+	c := projectConditions(filter)
+	after, order, args := keyset("p.created_at", "p.id", page, c.args)
+	return r.queryProjects(ctx, `SELECT `+projectColumns+projectJoins+c.where()+after+order, args...)
+}
+
+func (r *projectRepository) Count(ctx context.Context, filter repository.ProjectFilter) (int, error) {
+	c := projectConditions(filter)
+	return count(ctx, r.q, "projects", `SELECT count(*)`+projectJoins+c.where(), c.args...)
+}
+
+// Grouping IDs of the facet grouping sets. GROUPING sets the bit of every
+// column left out of a set, the first column being the highest bit.
+const (
+	facetCategory       = 0b0111
+	facetStatus         = 0b1011
+	facetTimeCommitment = 0b1101
+	facetTechnology     = 0b1110
+	facetTotal          = 0b1111
+)
+
+// Facets counts every breakdown in one scan of the matching projects. Each
+// project is joined to its technologies, so the counts are of distinct ids.
+func (r *projectRepository) Facets(ctx context.Context, filter repository.ProjectFilter) (*model.ProjectFacets, error) {
+	c := projectConditions(filter)
+	query := `
+		SELECT GROUPING(p.category, p.status, p.time_commitment, t.technology),
+			COALESCE(p.category, p.status, p.time_commitment, t.technology, ''),
+			count(DISTINCT p.id)
+		FROM projects p
+		JOIN project_owners po ON p.id = po.project_id
+		LEFT JOIN LATERAL unnest(p.technologies) AS t(technology) ON true` + c.where() + `
+		GROUP BY GROUPING SETS ((p.category), (p.status), (p.time_commitment), (t.technology), ())
+		ORDER BY 3 DESC, 2`
+
+	rows, err := r.q.QueryContext(ctx, query, c.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &model.ProjectFacets{
+		Categories:      []*model.FacetCount{},
+		Technologies:    []*model.FacetCount{},
+		Statuses:        []*model.FacetCount{},
+		TimeCommitments: []*model.FacetCount{},
+	}
+	for rows.Next() {
+		var grouping int
+		facet := &model.FacetCount{}
+		if err := rows.Scan(&grouping, &facet.Value, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan project facet row: %w", err)
+		}
+		if grouping == facetTotal {
+			facets.Total = facet.Count
+			continue
+		}
+		// Blank categories and time commitments, and projects without
+		// technologies, are not values a filter can select
+		if facet.Value == "" {
+			continue
+		}
+		switch grouping {
+		case facetCategory:
+			facets.Categories = append(facets.Categories, facet)
+		case facetStatus:
+			facets.Statuses = append(facets.Statuses, facet)
+		case facetTimeCommitment:
+			facets.TimeCommitments = append(facets.TimeCommitments, facet)
+		case facetTechnology:
+			facets.Technologies = append(facets.Technologies, facet)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating project facet rows: %w", err)
+	}
+	return facets, nil
+}
+
+// visibleToViewer keeps hidden projects out of listings unless the viewer,
+// given as $2 (admin) and $3 (user ID), is an admin or the owner. The user ID
+// is compared as text because anonymous viewers have none.
+const visibleToViewer = `(p.hidden_at IS NULL OR $2::boolean OR po.user_id::text = $3)`
+
+func (r *projectRepository) ListByOwner(ctx context.Context, ownerID string, viewer repository.Viewer) ([]*model.Project, error) {
+	query := `SELECT ` + projectColumns + projectJoins + `
+		WHERE po.user_id = $1 AND ` + visibleToViewer + `
+		ORDER BY p.created_at DESC
+	`
+	return r.queryProjects(ctx, query, ownerID, viewer.Admin, viewer.UserID)
+}
+
+func (r *projectRepository) ListByMember(ctx context.Context, userID string, viewer repository.Viewer) ([]*model.Project, error) {
+	query := `SELECT ` + projectColumns + projectJoins + `
+		WHERE p.id IN (
+			SELECT t.project_id
+			FROM teams t
+			JOIN team_members tm ON tm.team_id = t.id
+			WHERE tm.user_id = $1
+		) AND ` + visibleToViewer + `
+		ORDER BY p.created_at DESC
+	`
+	return r.queryProjects(ctx, query, userID, viewer.Admin, viewer.UserID)
+}
+
+func (r *projectRepository) ListByOwners(ctx context.Context, ownerIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	query := `SELECT ` + projectColumns + `, po.user_id` + projectJoins + `
+		WHERE po.user_id = ANY($1) AND ` + visibleToViewer + `
+		ORDER BY p.created_at DESC
+	`
+	return r.queryProjectsByUser(ctx, query, pq.Array(ownerIDs), viewer.Admin, viewer.UserID)
+}
+
+func (r *projectRepository) ListByMembers(ctx context.Context, userIDs []string, viewer repository.Viewer) (map[string][]*model.Project, error) {
+	query := `SELECT ` + projectColumns + `, m.user_id` + projectJoins + `
+		JOIN (
+			SELECT DISTINCT t.project_id, tm.user_id
+			FROM teams t
+			JOIN team_members tm ON tm.team_id = t.id
+			WHERE tm.user_id = ANY($1)
+		) m ON m.project_id = p.id
+		WHERE ` + visibleToViewer + `
+		ORDER BY p.created_at DESC
+	`
+	return r.queryProjectsByUser(ctx, query, pq.Array(userIDs), viewer.Admin, viewer.UserID)
+}
+
+// queryProjectsByUser groups projects by the user id selected after the
+// project columns
+func (r *projectRepository) queryProjectsByUser(ctx context.Context, query string, args ...interface{}) (map[string][]*model.Project, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := map[string][]*model.Project{}
+	for rows.Next() {
+		var userID string
+		project, err := scanProject(rows, &userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project row: %w", err)
+		}
+		projects[userID] = append(projects[userID], project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating project rows: %w", err)
+	}
+	return projects, nil
+}
+
+func (r *projectRepository) Related(ctx context.Context, project *model.Project, limit int) ([]*model.Project, error) {
+	query := `
+		SELECT ` + projectColumns + `,
+			   COUNT(DISTINCT t) AS matching_technologies
+		` + projectJoins + `
+		LEFT JOIN LATERAL unnest(p.technologies) t ON TRUE
+		WHERE p.id != $1
+		  AND p.hidden_at IS NULL
+		  AND (p.category = $2 OR t = ANY($3))
+		GROUP BY p.id, u.id
+		ORDER BY matching_technologies DESC, p.popularity DESC
+		LIMIT $4
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, project.ID, project.Category, pq.Array(project.Technologies), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query related projects: %w", err)
+	}
+	defer rows.Close()
+
+	related := []*model.Project{}
+	for rows.Next() {
+		var matchingTechnologies int
+		p, err := scanProject(rows, &matchingTechnologies)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan related project row: %w", err)
+		}
+		related = append(related, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating related project rows: %w", err)
+	}
+	return related, nil
+}
+
+func (r *projectRepository) UpdateStatus(ctx context.Context, id string, status model.ProjectStatus) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE projects SET status = $1, updated_at = $2 WHERE id = $3`,
+		status, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update project status: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) AdjustPopularity(ctx context.Context, id string, delta int) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE projects SET popularity = GREATEST(popularity + $1, 0), updated_at = $2 WHERE id = $3`,
+		delta, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update project popularity: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) AddTechnology(ctx context.Context, id, technology string) (bool, error) {
+	if err := r.checkExists(ctx, id); err != nil {
+		return false, err
+	}
+
+	result, err := r.q.ExecContext(ctx, `
+		UPDATE projects
+		SET technologies = array_append(technologies, $1),
+			updated_at = $2
+		WHERE id = $3 AND NOT ($1 = ANY(technologies))
+	`, technology, time.Now().UTC(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to add technology: %w", err)
+	}
+	return changed(result)
+}
+
+func (r *projectRepository) RemoveTechnology(ctx context.Context, id, technology string) (bool, error) {
+	if err := r.checkExists(ctx, id); err != nil {
+		return false, err
+	}
+
+	result, err := r.q.ExecContext(ctx, `
+		UPDATE projects
+		SET technologies = array_remove(technologies, $1),
+			updated_at = $2
+		WHERE id = $3 AND $1 = ANY(technologies)
+	`, technology, time.Now().UTC(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove technology: %w", err)
+	}
+	return changed(result)
+}
+
+func (r *projectRepository) DecrementOpenPositions(ctx context.Context, id string) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE projects SET open_positions = open_positions - 1 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update open positions: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) IsOwner(ctx context.Context, projectID, userID string) (bool, error) {
+	var isOwner bool
+	err := r.q.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM project_owners WHERE project_id = $1 AND user_id = $2)`,
+		projectID, userID).Scan(&isOwner)
+	if err != nil {
+		return false, fmt.Errorf("failed to check project ownership: %w", err)
+	}
+	return isOwner, nil
+}
+
+func (r *projectRepository) Visible(ctx context.Context, id string, viewer repository.Viewer) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1` + projectJoins + ` WHERE p.id = $1 AND ` + visibleToViewer + `)`
+	var visible bool
+	if err := r.q.QueryRowContext(ctx, query, id, viewer.Admin, viewer.UserID).Scan(&visible); err != nil {
+		return false, fmt.Errorf("failed to check project visibility: %w", err)
+	}
+	return visible, nil
+}
+
+func (r *projectRepository) SetOwner(ctx context.Context, projectID, userID string) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE project_owners SET user_id = $1 WHERE project_id = $2`, userID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to update project owner: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) Hide(ctx context.Context, id, reason string) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE projects SET hidden_at = $1, hidden_reason = $2 WHERE id = $3`, time.Now().UTC(), reason, id)
+	if err != nil {
+		return fmt.Errorf("failed to hide project: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) Unhide(ctx context.Context, id string) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE projects SET hidden_at = NULL, hidden_reason = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to unhide project: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *projectRepository) checkExists(ctx context.Context, id string) error {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error checking project existence: %w", err)
+	}
+	if !exists {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// changed reports whether a statement changed any rows
+func changed(result sql.Result) (bool, error) {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+)
+
+// searchMatch selects the projects whose search document matches a prefix
+// query, or whose search text contains a word similar to the raw query. Its
+// placeholders are for the prefix query and the raw query.
+const searchMatch = `(p.search_vector @@ to_tsquery('english', %s) OR %s <%% p.search_text)`
+
+// ts_headline marks matches with these delimiters, which highlight turns
+// into <mark> tags once the rest of the text is escaped
+const (
+	highlightStart = "[["
+	highlightStop  = "]]"
+)
+
+const (
+	titleHeadline   = `'HighlightAll=true, StartSel=` + highlightStart + `, StopSel=` + highlightStop + `'`
+	snippetHeadline = `'MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=" ... ", StartSel=` +
+		highlightStart + `, StopSel=` + highlightStop + `'`
+)
+
+// prefixQuery turns the words of a search into a to_tsquery expression that
+// matches every word as a prefix. Everything but letters and digits is
+// dropped, so the result is always a valid expression.
+func prefixQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// highlight escapes a ts_headline result for HTML and marks its matches
+func highlight(headline string) string {
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(html.EscapeString(headline))
+}
+
+// Search ranks full-text matches by ts_rank_cd and puts them before the
+// projects only matched by trigram similarity, which rank by how similar
+// their closest word is.
+func (r *projectRepository) Search(ctx context.Context, query string, limit, offset int) ([]*model.SearchHit, error) {
+	tsquery := prefixQuery(query)
+	if tsquery == "" {
+		return []*model.SearchHit{}, nil
+	}
+
+	searchQuery := `SELECT ` + projectColumns + `,
+			ts_headline('english', p.title, to_tsquery('english', $1), ` + titleHeadline + `),
+			ts_headline('english', p.description, to_tsquery('english', $1), ` + snippetHeadline + `),
+			ts_rank_cd(p.search_vector, to_tsquery('english', $1)) + word_similarity($2, p.search_text) AS score` +
+		projectJoins + `
+		WHERE p.hidden_at IS NULL AND ` + fmt.Sprintf(searchMatch, "$1", "$2") + `
+		ORDER BY p.search_vector @@ to_tsquery('english', $1) DESC, score DESC, p.created_at DESC, p.id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.q.QueryContext(ctx, searchQuery, tsquery, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search projects: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []*model.SearchHit{}
+	for rows.Next() {
+		hit := &model.SearchHit{}
+		var title, snippet string
+		hit.Project, err = scanProject(rows, &title, &snippet, &hit.Score)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+		hit.TitleHighlight = highlight(title)
+		hit.Snippet = highlight(snippet)
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search hits: %w", err)
+	}
+	return hits, nil
+}
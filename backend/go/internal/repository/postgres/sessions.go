@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/lib/pq"
+)
+
+// sessionRepository implements auth.SessionStore. It keeps the Store rather
+// than a querier because rotating a refresh token takes a transaction of its
+// own when the caller has not started one.
+type sessionRepository struct {
+	store *Store
+}
+
+func (r *sessionRepository) Create(ctx context.Context, userID string, session *auth.Session, token *auth.RefreshToken) error {
+	return r.store.WithTx(ctx, func(tx repository.Store) error {
+		q := tx.(*Store).q
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_used_at)
+			 VALUES ($1, $2, $3, $4, $5, $6)`,
+			session.ID, userID, session.UserAgent, session.IPAddress, session.CreatedAt, session.LastUsedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert session: %w", err)
+		}
+		return insertRefreshToken(ctx, q, token)
+	})
+}
+
+func insertRefreshToken(ctx context.Context, q querier, token *auth.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, session_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5)`
+	_, err := q.ExecContext(ctx, query, token.ID, token.SessionID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) RefreshToken(ctx context.Context, tokenHash string) (*auth.RefreshToken, error) {
+	query := `
+		SELECT rt.id, rt.session_id, s.user_id, rt.token_hash, rt.expires_at, rt.created_at, rt.used_at,
+			   s.revoked_at IS NOT NULL
+		FROM refresh_tokens rt
+		JOIN sessions s ON rt.session_id = s.id
+		WHERE rt.token_hash = $1
+	`
+	var token auth.RefreshToken
+	var usedAt sql.NullTime
+	err := r.store.q.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID, &token.SessionID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &usedAt,
+		&token.SessionRevoked,
+	)
+	if err == sql.ErrNoRows {
+		return nil, auth.ErrInvalidRefreshToken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up refresh token: %w", err)
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return &token, nil
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, used, next *auth.RefreshToken, ip string) error {
+	return r.store.WithTx(ctx, func(tx repository.Store) error {
+		q := tx.(*Store).q
+		now := time.Now().UTC()
+
+		// Only one of two concurrent exchanges of the same token gets here
+		result, err := q.ExecContext(ctx,
+			`UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`, now, used.ID)
+		if err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		if err := checkAffected(result); err == repository.ErrNotFound {
+			return auth.ErrRefreshTokenReused
+		} else if err != nil {
+			return err
+		}
+
+		result, err = q.ExecContext(ctx,
+			`UPDATE sessions SET last_used_at = $1, ip_address = COALESCE(NULLIF($2, ''), ip_address)
+			 WHERE id = $3 AND revoked_at IS NULL`,
+			now, ip, used.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+		if err := checkAffected(result); err == repository.ErrNotFound {
+			return auth.ErrInvalidRefreshToken
+		} else if err != nil {
+			return err
+		}
+
+		return insertRefreshToken(ctx, q, next)
+	})
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, userID, sessionID string) error {
+	result, err := r.store.q.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`,
+		time.Now().UTC(), sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := checkAffected(result); err == repository.ErrNotFound {
+		return auth.ErrSessionNotFound
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID, keepSessionID string) error {
+	query := `UPDATE sessions SET revoked_at = $1
+			  WHERE user_id = $2 AND revoked_at IS NULL AND ($3 = '' OR id::text <> $3)`
+	if _, err := r.store.q.ExecContext(ctx, query, time.Now().UTC(), userID, keepSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) List(ctx context.Context, userID string) ([]*auth.Session, error) {
+	query := `
+		SELECT id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, COALESCE(last_used_at, created_at)
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY COALESCE(last_used_at, created_at) DESC
+	`
+	rows, err := r.store.q.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*auth.Session
+	for rows.Next() {
+		var session auth.Session
+		err := rows.Scan(&session.ID, &session.UserAgent, &session.IPAddress, &session.CreatedAt, &session.LastUsedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, sessionID, ip string, at, staleBefore time.Time) error {
+	query := `
+		UPDATE sessions SET last_used_at = $1, ip_address = COALESCE(NULLIF($2, ''), ip_address)
+		WHERE id = $3 AND (last_used_at IS NULL OR last_used_at < $4)
+	`
+	if _, err := r.store.q.ExecContext(ctx, query, at, ip, sessionID, staleBefore); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	var active bool
+	query := `SELECT EXISTS(SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL)`
+	if err := r.store.q.QueryRowContext(ctx, query, sessionID).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return active, nil
+}
+
+func (r *sessionRepository) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	if _, err := r.store.q.ExecContext(ctx, query, tokenID, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	// Entries are only useful until the token would have expired anyway
+	cleanupQuery := `DELETE FROM revoked_tokens WHERE expires_at < $1`
+	if _, err := r.store.q.ExecContext(ctx, cleanupQuery, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to clean up revoked tokens: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	if err := r.store.q.QueryRowContext(ctx, query, tokenID).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (r *sessionRepository) PersonalAccessToken(ctx context.Context, tokenHash string) (*auth.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, scopes, expires_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`
+	var token auth.PersonalAccessToken
+	var expiresAt sql.NullTime
+	err := r.store.q.QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, pq.Array(&token.Scopes), &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, auth.ErrInvalidAccessToken
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up personal access token: %w", err)
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return &token, nil
+}
+
+func (r *sessionRepository) TouchPersonalAccessToken(ctx context.Context, id string, at, staleBefore time.Time) error {
+	query := `
+		UPDATE personal_access_tokens SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+	if _, err := r.store.q.ExecContext(ctx, query, at, id, staleBefore); err != nil {
+		return fmt.Errorf("failed to update personal access token: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) IsUserSuspended(ctx context.Context, userID string) (bool, error) {
+	var suspended bool
+	err := r.store.q.QueryRowContext(ctx, `SELECT suspended_at IS NOT NULL FROM users WHERE id = $1`, userID).Scan(&suspended)
+	if err == sql.ErrNoRows {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return suspended, nil
+}
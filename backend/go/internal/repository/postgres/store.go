@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+// querier is satisfied by *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Store implements repository.Store on top of Postgres
+type Store struct {
+	db *sql.DB
+	q  querier
+	tx *sql.Tx
+}
+
+var _ repository.Store = (*Store)(nil)
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, q: db}
+}
+
+func (s *Store) Projects() repository.ProjectRepository {
+	return &projectRepository{q: s.q}
+}
+
+func (s *Store) Users() repository.UserRepository {
+	return &userRepository{q: s.q}
+}
+
+func (s *Store) Teams() repository.TeamRepository {
+	return &teamRepository{q: s.q}
+}
+
+func (s *Store) Tasks() repository.TaskRepository {
+	return &taskRepository{q: s.q}
+}
+
+func (s *Store) JoinRequests() repository.JoinRequestRepository {
+	return &joinRequestRepository{q: s.q}
+}
+
+func (s *Store) Sessions() auth.SessionStore {
+	return &sessionRepository{store: s}
+}
+
+func (s *Store) AccessTokens() repository.AccessTokenRepository {
+	return &accessTokenRepository{q: s.q}
+}
+
+func (s *Store) TwoFactor() repository.TwoFactorRepository {
+	return &twoFactorRepository{q: s.q}
+}
+
+func (s *Store) PasswordResets() repository.PasswordResetRepository {
+	return &passwordResetRepository{q: s.q}
+}
+
+func (s *Store) LoginThrottle() repository.LoginThrottleRepository {
+	return &loginThrottleRepository{q: s.q}
+}
+
+func (s *Store) ModerationReports() repository.ModerationReportRepository {
+	return &moderationReportRepository{q: s.q}
+}
+
+func (s *Store) RecordAudit(ctx context.Context, event audit.Event) error {
+	return audit.Record(ctx, s.q, event)
+}
+
+func (s *Store) AuditLog(ctx context.Context, filter audit.Filter) ([]*audit.StoredEvent, error) {
+	return audit.List(ctx, s.q, filter)
+}
+
+func (s *Store) WithTx(ctx context.Context, fn func(repository.Store) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&Store{db: s.db, q: tx, tx: tx}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// checkAffected returns repository.ErrNotFound if a statement changed no rows
+func checkAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// notFound maps sql.ErrNoRows to repository.ErrNotFound
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// keyset returns the condition selecting the rows past page.After and the
+// ORDER BY and LIMIT clauses of the page, on the given created_at and id
+// columns. Placeholders are numbered after the args already in use.
+func keyset(createdAt, id string, page repository.Page, args []interface{}) (string, string, []interface{}) {
+	direction, comparison := "DESC", "<"
+	if page.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+
+	condition := ""
+	if page.After != nil {
+		condition = fmt.Sprintf(" AND (%s, %s) %s ($%d::timestamptz, $%d::uuid)",
+			createdAt, id, comparison, len(args)+1, len(args)+2)
+		args = append(args, page.After.CreatedAt, page.After.ID)
+	}
+
+	order := fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT $%d", createdAt, direction, id, direction, len(args)+1)
+	return condition, order, append(args, page.Limit)
+}
+
+// count runs a SELECT count(*) query
+func count(ctx context.Context, q querier, table, query string, args ...interface{}) (int, error) {
+	var n int
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+type taskRepository struct {
+	q querier
+}
+
+const taskColumns = `
+	t.id, t.title, t.description, t.status, t.priority, t.due_date,
+	t.project_id, t.assignee_id, t.created_at, t.updated_at,
+	p.title, u.username`
+
+const taskJoins = `
+	FROM tasks t
+	LEFT JOIN projects p ON t.project_id = p.id
+	LEFT JOIN users u ON t.assignee_id = u.id`
+
+func scanTask(row rowScanner) (*model.Task, error) {
+	task := &model.Task{}
+	var projectID string
+	var assigneeID, projectTitle, assigneeUsername sql.NullString
+
+	err := row.Scan(
+		&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority, &task.DueDate,
+		&projectID, &assigneeID, &task.CreatedAt, &task.UpdatedAt,
+		&projectTitle, &assigneeUsername,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Project = &model.Project{ID: projectID, Title: projectTitle.String}
+	if assigneeID.Valid {
+		task.Assignee = &model.User{ID: assigneeID.String, Username: assigneeUsername.String}
+	}
+	return task, nil
+}
+
+func (r *taskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*model.Task, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*model.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task row: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task rows: %w", err)
+	}
+	return tasks, nil
+}
+
+// assigneeID returns the id to store in tasks.assignee_id
+func assigneeID(task *model.Task) *string {
+	if task.Assignee == nil {
+		return nil
+	}
+	return &task.Assignee.ID
+}
+
+func (r *taskRepository) Create(ctx context.Context, task *model.Task) error {
+	query := `
+		INSERT INTO tasks (title, description, status, priority, due_date, project_id, assignee_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	err := r.q.QueryRowContext(ctx, query,
+		task.Title, task.Description, task.Status, task.Priority, task.DueDate,
+		task.Project.ID, assigneeID(task), task.CreatedAt, task.UpdatedAt,
+	).Scan(&task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	return nil
+}
+
+func (r *taskRepository) GetByID(ctx context.Context, id string) (*model.Task, error) {
+	query := `SELECT ` + taskColumns + taskJoins + ` WHERE t.id = $1`
+
+	task, err := scanTask(r.q.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return task, nil
+}
+
+func (r *taskRepository) Update(ctx context.Context, task *model.Task) error {
+	query := `
+		UPDATE tasks
+		SET title = $1, description = $2, status = $3, priority = $4,
+			due_date = $5, assignee_id = $6, updated_at = $7
+		WHERE id = $8`
+
+	result, err := r.q.ExecContext(ctx, query,
+		task.Title, task.Description, task.Status, task.Priority,
+		task.DueDate, assigneeID(task), task.UpdatedAt, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.q.ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *taskRepository) ByProject(ctx context.Context, projectID string, status *model.TaskStatus, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	conditions, args := taskFilters(repository.TaskFilter{ProjectID: &projectID, Status: status, Viewer: &viewer})
+	query := `SELECT ` + taskColumns + taskJoins + conditions +
+		fmt.Sprintf(` ORDER BY t.created_at DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	return r.queryTasks(ctx, query, append(args, limit, offset)...)
+}
+
+func (r *taskRepository) ByAssignee(ctx context.Context, userID string, status *model.TaskStatus, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	conditions, args := taskFilters(repository.TaskFilter{AssigneeID: &userID, Status: status, Viewer: &viewer})
+	query := `SELECT ` + taskColumns + taskJoins + conditions +
+		fmt.Sprintf(` ORDER BY t.due_date ASC, t.priority DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	return r.queryTasks(ctx, query, append(args, limit, offset)...)
+}
+
+func (r *taskRepository) Search(ctx context.Context, query string, viewer repository.Viewer, limit, offset int) ([]*model.Task, error) {
+	conditions, args := taskFilters(repository.TaskFilter{Viewer: &viewer})
+	args = append(args, "%"+query+"%", limit, offset)
+	sqlQuery := `SELECT ` + taskColumns + taskJoins + conditions +
+		fmt.Sprintf(` AND (t.title ILIKE $%d OR t.description ILIKE $%d)
+		ORDER BY t.created_at DESC
+		LIMIT $%d OFFSET $%d`, len(args)-2, len(args)-2, len(args)-1, len(args))
+	return r.queryTasks(ctx, sqlQuery, args...)
+}
+
+// taskFilters returns the WHERE clause for a filter with its args
+func taskFilters(filter repository.TaskFilter) (string, []interface{}) {
+	conditions := ` WHERE TRUE`
+	var args []interface{}
+	if filter.ProjectID != nil {
+		args = append(args, *filter.ProjectID)
+		conditions += fmt.Sprintf(" AND t.project_id = $%d", len(args))
+	}
+	if filter.AssigneeID != nil {
+		args = append(args, *filter.AssigneeID)
+		conditions += fmt.Sprintf(" AND t.assignee_id = $%d", len(args))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		conditions += fmt.Sprintf(" AND t.status = $%d", len(args))
+	}
+	if filter.Viewer != nil {
+		// Tasks of hidden projects are only shown to the owner and admins
+		args = append(args, filter.Viewer.Admin, filter.Viewer.UserID)
+		conditions += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM projects vp
+			JOIN project_owners vpo ON vp.id = vpo.project_id
+			WHERE vp.id = t.project_id
+			  AND (vp.hidden_at IS NULL OR $%d::boolean OR vpo.user_id::text = $%d))`, len(args)-1, len(args))
+	}
+	return conditions, args
+}
+
+func (r *taskRepository) ListPage(ctx context.Context, filter repository.TaskFilter, page repository.Page) ([]*model.Task, error) {
+	conditions, args := taskFilters(filter)
+	after, order, args := keyset("t.created_at", "t.id", page, args)
+	return r.queryTasks(ctx, `SELECT `+taskColumns+taskJoins+conditions+after+order, args...)
+}
+
+func (r *taskRepository) Count(ctx context.Context, filter repository.TaskFilter) (int, error) {
+	conditions, args := taskFilters(filter)
+	return count(ctx, r.q, "tasks", `SELECT count(*) FROM tasks t`+conditions, args...)
+}
+
+func (r *taskRepository) SetAssignee(ctx context.Context, id string, userID *string) error {
+	result, err := r.q.ExecContext(ctx, `
+		UPDATE tasks
+		SET assignee_id = $1, updated_at = $2
+		WHERE id = $3`, userID, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to assign task: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *taskRepository) UpdateStatus(ctx context.Context, id string, status model.TaskStatus) error {
+	result, err := r.q.ExecContext(ctx, `
+		UPDATE tasks
+		SET status = $1, updated_at = $2
+		WHERE id = $3`, status, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+	return checkAffected(result)
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/lib/pq"
+)
+
+type teamRepository struct {
+	q querier
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *model.Team, projectID string) error {
+	query := `INSERT INTO teams (id, name, description, project_id, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.q.ExecContext(ctx, query,
+		team.ID, team.Name, team.Description, projectID, team.CreatedAt, team.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert team: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id string) (*model.Team, error) {
+	query := `
+		SELECT t.id, t.name, t.description, t.created_at, t.updated_at,
+			   p.id, p.title
+		FROM teams t
+		JOIN projects p ON t.project_id = p.id
+		WHERE t.id = $1
+	`
+
+	team := &model.Team{Project: &model.Project{}}
+	err := r.q.QueryRowContext(ctx, query, id).Scan(
+		&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt,
+		&team.Project.ID, &team.Project.Title,
+	)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return team, nil
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *model.Team) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE teams SET name = $1, description = $2, updated_at = $3 WHERE id = $4`,
+		team.Name, team.Description, team.UpdatedAt, team.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM teams WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) ByProject(ctx context.Context, projectID string) ([]*model.Team, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM teams
+		WHERE project_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []*model.Team{}
+	for rows.Next() {
+		team := &model.Team{}
+		err := rows.Scan(&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team row: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) Members(ctx context.Context, teamID string) ([]*model.TeamMember, error) {
+	return r.queryMembers(ctx, `
+		SELECT tm.id, tm.user_id, tm.role, tm.joined_at,
+			   u.username, u.email, u.first_name, u.last_name, u.role, u.suspended_at
+		FROM team_members tm
+		JOIN users u ON tm.user_id = u.id
+		WHERE tm.team_id = $1
+		ORDER BY tm.joined_at
+	`, teamID)
+}
+
+func (r *teamRepository) ProjectMembers(ctx context.Context, projectID string) ([]*model.TeamMember, error) {
+	return r.queryMembers(ctx, `
+		SELECT tm.id, tm.user_id, tm.role, tm.joined_at,
+			   u.username, u.email, u.first_name, u.last_name, u.role, u.suspended_at
+		FROM team_members tm
+		JOIN users u ON tm.user_id = u.id
+		JOIN teams t ON tm.team_id = t.id
+		WHERE t.project_id = $1
+		ORDER BY tm.joined_at
+	`, projectID)
+}
+
+func (r *teamRepository) DefaultByProjects(ctx context.Context, projectIDs []string) (map[string]*model.Team, error) {
+	query := `
+		SELECT DISTINCT ON (project_id) id, name, description, created_at, updated_at, project_id
+		FROM teams
+		WHERE project_id = ANY($1)
+		ORDER BY project_id, created_at
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, pq.Array(projectIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := map[string]*model.Team{}
+	for rows.Next() {
+		team := &model.Team{Project: &model.Project{}}
+		err := rows.Scan(&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt, &team.Project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team row: %w", err)
+		}
+		teams[team.Project.ID] = team
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+	return teams, nil
+}
+
+func (r *teamRepository) MembersByTeams(ctx context.Context, teamIDs []string) (map[string][]*model.TeamMember, error) {
+	return r.queryMembersBy(ctx, `
+		SELECT tm.id, tm.user_id, tm.role, tm.joined_at,
+			   u.username, u.email, u.first_name, u.last_name, u.role, u.suspended_at,
+			   tm.team_id
+		FROM team_members tm
+		JOIN users u ON tm.user_id = u.id
+		WHERE tm.team_id = ANY($1)
+		ORDER BY tm.joined_at
+	`, pq.Array(teamIDs))
+}
+
+func (r *teamRepository) MembersByProjects(ctx context.Context, projectIDs []string) (map[string][]*model.TeamMember, error) {
+	return r.queryMembersBy(ctx, `
+		SELECT tm.id, tm.user_id, tm.role, tm.joined_at,
+			   u.username, u.email, u.first_name, u.last_name, u.role, u.suspended_at,
+			   t.project_id
+		FROM team_members tm
+		JOIN users u ON tm.user_id = u.id
+		JOIN teams t ON tm.team_id = t.id
+		WHERE t.project_id = ANY($1)
+		ORDER BY tm.joined_at
+	`, pq.Array(projectIDs))
+}
+
+// queryMembersBy groups members by the id selected after the member columns
+func (r *teamRepository) queryMembersBy(ctx context.Context, query string, args ...interface{}) (map[string][]*model.TeamMember, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := map[string][]*model.TeamMember{}
+	for rows.Next() {
+		var key string
+		var suspendedAt sql.NullTime
+		tm := &model.TeamMember{User: &model.User{}}
+		err := rows.Scan(
+			&tm.ID, &tm.User.ID, &tm.Role, &tm.JoinedAt,
+			&tm.User.Username, &tm.User.Email, &tm.User.FirstName, &tm.User.LastName,
+			&tm.User.Role, &suspendedAt, &key,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team member row: %w", err)
+		}
+		tm.User.SuspendedAt = formatSuspendedAt(suspendedAt)
+		members[key] = append(members[key], tm)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team member rows: %w", err)
+	}
+	return members, nil
+}
+
+func (r *teamRepository) queryMembers(ctx context.Context, query string, args ...interface{}) ([]*model.TeamMember, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*model.TeamMember{}
+	for rows.Next() {
+		var suspendedAt sql.NullTime
+		tm := &model.TeamMember{User: &model.User{}}
+		err := rows.Scan(
+			&tm.ID, &tm.User.ID, &tm.Role, &tm.JoinedAt,
+			&tm.User.Username, &tm.User.Email, &tm.User.FirstName, &tm.User.LastName,
+			&tm.User.Role, &suspendedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team member row: %w", err)
+		}
+		tm.User.SuspendedAt = formatSuspendedAt(suspendedAt)
+		members = append(members, tm)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team member rows: %w", err)
+	}
+	return members, nil
+}
+
+func (r *teamRepository) IsMember(ctx context.Context, teamID, userID string) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM team_members WHERE team_id = $1 AND user_id = $2)`,
+		teamID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking existing team membership: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *teamRepository) IsProjectMember(ctx context.Context, projectID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM team_members tm
+			JOIN teams t ON tm.team_id = t.id
+			WHERE t.project_id = $1 AND tm.user_id = $2
+		)
+	`
+	var exists bool
+	if err := r.q.QueryRowContext(ctx, query, projectID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking project membership: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *teamRepository) AddMember(ctx context.Context, teamID string, member *model.TeamMember) error {
+	query := `
+		INSERT INTO team_members (id, team_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.q.ExecContext(ctx, query, member.ID, teamID, member.User.ID, member.Role, member.JoinedAt)
+	if err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepository) RemoveMember(ctx context.Context, teamID, userID string) error {
+	result, err := r.q.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *teamRepository) UpdateMemberRole(ctx context.Context, teamID, userID, role string) error {
+	result, err := r.q.ExecContext(ctx, `
+		UPDATE team_members
+		SET role = $1
+		WHERE team_id = $2 AND user_id = $3
+	`, role, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update team member role: %w", err)
+	}
+	return checkAffected(result)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+type twoFactorRepository struct {
+	q querier
+}
+
+func (r *twoFactorRepository) SetSecret(ctx context.Context, userID, secret string) error {
+	query := `INSERT INTO user_totp (user_id, secret, created_at)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, created_at = EXCLUDED.created_at
+			  WHERE user_totp.confirmed_at IS NULL`
+	if _, err := r.q.ExecContext(ctx, query, userID, secret, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) Secret(ctx context.Context, userID string) (*repository.TwoFactorSecret, error) {
+	var secret repository.TwoFactorSecret
+	var lastUsedStep sql.NullInt64
+	err := r.q.QueryRowContext(ctx,
+		`SELECT secret, confirmed_at IS NOT NULL, last_used_step FROM user_totp WHERE user_id = $1 FOR UPDATE`, userID,
+	).Scan(&secret.Secret, &secret.Confirmed, &lastUsedStep)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if lastUsedStep.Valid {
+		secret.LastUsedStep = &lastUsedStep.Int64
+	}
+	return &secret, nil
+}
+
+func (r *twoFactorRepository) Confirm(ctx context.Context, userID string, step int64) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE user_totp SET confirmed_at = $1, last_used_step = $2 WHERE user_id = $3`,
+		time.Now().UTC(), step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *twoFactorRepository) SetLastUsedStep(ctx context.Context, userID string, step int64) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE user_totp SET last_used_step = $1 WHERE user_id = $2`, step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, userID string) error {
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP secret: %w", err)
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	now := time.Now().UTC()
+	query := `INSERT INTO totp_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, $4)`
+	for _, hash := range codeHashes {
+		if _, err := r.q.ExecContext(ctx, query, uuid.New().String(), userID, hash, now); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE totp_recovery_codes SET used_at = $1
+		 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL`,
+		time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return checkAffected(result)
+}
@@ -0,0 +1,258 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/lib/pq"
+)
+
+type userRepository struct {
+	q querier
+}
+
+const userColumns = `
+	id, username, email, first_name, last_name, bio, profile_image_url, skills,
+	education_level, years_experience, preferred_role, github_url, linkedin_url,
+	portfolio_url, email_verified, last_active, time_zone, available_hours,
+	certifications, languages, project_preferences, created_at, updated_at,
+	role, suspended_at`
+
+func scanUser(row rowScanner) (*model.User, error) {
+	user := &model.User{}
+	var skills, certifications, languages, projectPreferences []string
+	var suspendedAt sql.NullTime
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
+		pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
+		&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
+		&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
+		&user.CreatedAt, &user.UpdatedAt, &user.Role, &suspendedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.SuspendedAt = formatSuspendedAt(suspendedAt)
+
+	user.Skills = skills
+	user.Certifications = certifications
+	user.Languages = languages
+	user.ProjectPreferences = projectPreferences
+	return user, nil
+}
+
+func (r *userRepository) getBy(ctx context.Context, column, value string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + column + ` = $1`
+	user, err := scanUser(r.q.QueryRowContext(ctx, query, value))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return user, nil
+}
+
+func (r *userRepository) ListByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ANY($1)`
+	return r.queryUsers(ctx, query, pq.Array(ids))
+}
+
+func (r *userRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]*model.User, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+	return users, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *model.User, passwordHash []byte) error {
+	return r.q.QueryRowContext(ctx, `
+		INSERT INTO users (
+			username, email, password_hash, first_name, last_name,
+			bio, skills, education_level, years_experience, preferred_role,
+			github_url, linkedin_url, portfolio_url, email_verified,
+			time_zone, available_hours, certifications, languages,
+			project_preferences, last_active, joined_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		RETURNING id`,
+		user.Username, user.Email, passwordHash, user.FirstName, user.LastName,
+		user.Bio, pq.Array(user.Skills), user.EducationLevel, user.YearsExperience, user.PreferredRole,
+		user.GithubURL, user.LinkedInURL, user.PortfolioURL, user.EmailVerified,
+		user.TimeZone, user.AvailableHours, pq.Array(user.Certifications), pq.Array(user.Languages),
+		pq.Array(user.ProjectPreferences), user.LastActive, user.JoinedAt, user.CreatedAt, user.UpdatedAt,
+	).Scan(&user.ID)
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.getBy(ctx, "email", email)
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.getBy(ctx, "username", username)
+}
+
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	query := `
+		UPDATE users
+		SET username = $1, email = $2, first_name = $3, last_name = $4,
+			bio = $5, profile_image_url = $6, skills = $7, education_level = $8,
+			years_experience = $9, preferred_role = $10, github_url = $11,
+			linkedin_url = $12, portfolio_url = $13, time_zone = $14,
+			available_hours = $15, certifications = $16, languages = $17,
+			project_preferences = $18, email_verified = $19, updated_at = $20
+		WHERE id = $21
+	`
+	result, err := r.q.ExecContext(ctx, query,
+		user.Username, user.Email, user.FirstName, user.LastName,
+		user.Bio, user.ProfileImageURL, pq.Array(user.Skills), user.EducationLevel,
+		user.YearsExperience, user.PreferredRole, user.GithubURL,
+		user.LinkedInURL, user.PortfolioURL, user.TimeZone,
+		user.AvailableHours, pq.Array(user.Certifications), pq.Array(user.Languages),
+		pq.Array(user.ProjectPreferences), user.EmailVerified, user.UpdatedAt, user.ID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	return err
+}
+
+func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + `
+		FROM users
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	return r.queryUsers(ctx, query, limit, offset)
+}
+
+func (r *userRepository) ListPage(ctx context.Context, page repository.Page) ([]*model.User, error) {
+	after, order, args := keyset("created_at", "id", page, nil)
+	return r.queryUsers(ctx, `SELECT `+userColumns+` FROM users WHERE TRUE`+after+order, args...)
+}
+
+func (r *userRepository) Count(ctx context.Context) (int, error) {
+	return count(ctx, r.q, "users", `SELECT count(*) FROM users`)
+}
+
+func (r *userRepository) Search(ctx context.Context, query string) ([]*model.User, error) {
+	sqlQuery := `SELECT ` + userColumns + `
+		FROM users
+		WHERE username ILIKE $1 OR email ILIKE $1 OR first_name ILIKE $1 OR last_name ILIKE $1 OR skills @> ARRAY[$1] OR project_preferences @> ARRAY[$1]
+	`
+	return r.queryUsers(ctx, sqlQuery, "%"+query+"%")
+}
+
+func (r *userRepository) PasswordHash(ctx context.Context, id string) ([]byte, error) {
+	var hash []byte
+	err := r.q.QueryRowContext(ctx, `SELECT password_hash FROM users WHERE id = $1`, id).Scan(&hash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return hash, nil
+}
+
+func (r *userRepository) SetPasswordHash(ctx context.Context, id string, hash []byte) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`,
+		hash, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result)
+}
+
+func (r *userRepository) SetEmailVerified(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE users SET email_verified = true WHERE id = $1`, id)
+	return err
+}
+
+func (r *userRepository) UpdateLastActive(ctx context.Context, id string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE users SET last_active = $1 WHERE id = $2`, time.Now().Format(time.RFC3339), id)
+	return err
+}
+
+func (r *userRepository) AddSkill(ctx context.Context, id, skill string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE users SET skills = array_append(skills, $1) WHERE id = $2`, skill, id)
+	return err
+}
+
+func (r *userRepository) RemoveSkill(ctx context.Context, id, skill string) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE users SET skills = array_remove(skills, $1) WHERE id = $2`, skill, id)
+	return err
+}
+
+func (r *userRepository) Role(ctx context.Context, id string) (model.UserRole, error) {
+	var role string
+	err := r.q.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1`, id).Scan(&role)
+	if err != nil {
+		return "", notFound(err)
+	}
+	return model.UserRole(role), nil
+}
+
+func (r *userRepository) SuspendedAt(ctx context.Context, id string) (*string, error) {
+	var suspendedAt sql.NullTime
+	err := r.q.QueryRowContext(ctx, `SELECT suspended_at FROM users WHERE id = $1`, id).Scan(&suspendedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return formatSuspendedAt(suspendedAt), nil
+}
+
+func formatSuspendedAt(suspendedAt sql.NullTime) *string {
+	if !suspendedAt.Valid {
+		return nil
+	}
+	t := suspendedAt.Time.Format(time.RFC3339)
+	return &t
+}
+
+func (r *userRepository) SetRole(ctx context.Context, id string, role model.UserRole) error {
+	result, err := r.q.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, id)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *userRepository) Suspend(ctx context.Context, id, reason string) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE users SET suspended_at = $1, suspension_reason = $2 WHERE id = $3`,
+		time.Now().UTC(), reason, id)
+	if err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+	return checkAffected(result)
+}
+
+func (r *userRepository) Unsuspend(ctx context.Context, id string) error {
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE users SET suspended_at = NULL, suspension_reason = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+	return checkAffected(result)
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+// ErrNotFound is returned when the requested row does not exist
+var ErrNotFound = errors.New("not found")
+
+// Cursor is a position in a listing ordered by creation time, with the ID
+// breaking ties between rows created at the same instant
+type Cursor struct {
+	CreatedAt string
+	ID        string
+}
+
+// Page selects up to Limit rows strictly past After in (created_at, id)
+// order, newest first unless Ascending is set. A nil After starts at the
+// first row.
+type Page struct {
+	After     *Cursor
+	Limit     int
+	Ascending bool
+}
+
+// ProjectFilter selects projects. Unset fields match every project; list
+// fields match any of their values.
+type ProjectFilter struct {
+	Categories   []string
+	Statuses     []model.ProjectStatus
+	Technologies []string
+	// AllTechnologies requires every technology instead of any of them
+	AllTechnologies  bool
+	MinOpenPositions *int
+	MaxOpenPositions *int
+	TimeCommitment   *string
+	CreatedAfter     *string
+	CreatedBefore    *string
+	OwnerID          *string
+	// LearningObjective matches projects with an objective containing it,
+	// ignoring case
+	LearningObjective *string
+	// Query restricts the projects to those matching a full-text search
+	Query string
+}
+
+// Viewer is the user a listing is for. Projects hidden by moderators are
+// only listed for their owner and for admins.
+type Viewer struct {
+	UserID string
+	Admin  bool
+}
+
+// TaskFilter selects tasks. Nil fields match every task.
+type TaskFilter struct {
+	ProjectID  *string
+	AssigneeID *string
+	Status     *model.TaskStatus
+	// Viewer leaves out the tasks of projects hidden from them
+	Viewer *Viewer
+}
+
+// Store gives access to every repository. Repositories returned by the Store
+// passed to WithTx share a single transaction.
+type Store interface {
+	Projects() ProjectRepository
+	Users() UserRepository
+	Teams() TeamRepository
+	Tasks() TaskRepository
+	JoinRequests() JoinRequestRepository
+	Sessions() auth.SessionStore
+	AccessTokens() AccessTokenRepository
+	TwoFactor() TwoFactorRepository
+	PasswordResets() PasswordResetRepository
+	LoginThrottle() LoginThrottleRepository
+	ModerationReports() ModerationReportRepository
+
+	// RecordAudit appends an event to the audit log. Inside WithTx the event
+	// is only kept if the transaction commits.
+	RecordAudit(ctx context.Context, event audit.Event) error
+	// AuditLog returns the audit events matching the filter, newest first
+	AuditLog(ctx context.Context, filter audit.Filter) ([]*audit.StoredEvent, error)
+
+	// WithTx runs fn in a transaction and rolls it back if fn returns an
+	// error. Calling WithTx on a Store that is already in a transaction
+	// reuses that transaction.
+	WithTx(ctx context.Context, fn func(Store) error) error
+}
+
+// ProjectRepository stores projects and their owners. Listings leave out
+// projects hidden by moderators.
+type ProjectRepository interface {
+	// Create inserts the project and makes ownerID its owner
+	Create(ctx context.Context, project *model.Project, ownerID string) error
+	// GetByID returns the project with the owner's id and username
+	GetByID(ctx context.Context, id string) (*model.Project, error)
+	// Update saves the editable fields of a project
+	Update(ctx context.Context, project *model.Project) error
+	// Delete removes a project together with its teams, members, owner and
+	// join requests
+	Delete(ctx context.Context, id string) error
+	// List returns the projects matching the filter in the given order
+	List(ctx context.Context, filter ProjectFilter, sort model.ProjectSort, limit, offset int) ([]*model.Project, error)
+	// ListPage returns a page of the projects matching the filter
+	ListPage(ctx context.Context, filter ProjectFilter, page Page) ([]*model.Project, error)
+	// Count returns how many projects match the filter
+	Count(ctx context.Context, filter ProjectFilter) (int, error)
+	// Facets counts the projects matching the filter per category,
+	// technology, status and time commitment
+	Facets(ctx context.Context, filter ProjectFilter) (*model.ProjectFacets, error)
+	// Search returns the projects matching a search query, best matches
+	// first, with the matched terms highlighted
+	Search(ctx context.Context, query string, limit, offset int) ([]*model.SearchHit, error)
+	// ListByOwner returns the owner's projects that viewer may see, newest
+	// first
+	ListByOwner(ctx context.Context, ownerID string, viewer Viewer) ([]*model.Project, error)
+	// ListByMember returns the projects whose teams include the user and
+	// that viewer may see
+	ListByMember(ctx context.Context, userID string, viewer Viewer) ([]*model.Project, error)
+	// ListByOwners returns the projects of each owner that viewer may see,
+	// newest first
+	ListByOwners(ctx context.Context, ownerIDs []string, viewer Viewer) (map[string][]*model.Project, error)
+	// ListByMembers returns, for each user, the projects whose teams include
+	// them and that viewer may see, newest first
+	ListByMembers(ctx context.Context, userIDs []string, viewer Viewer) (map[string][]*model.Project, error)
+	// Related returns other projects in the same category or sharing a
+	// technology, best matches first
+	Related(ctx context.Context, project *model.Project, limit int) ([]*model.Project, error)
+	UpdateStatus(ctx context.Context, id string, status model.ProjectStatus) error
+	// AdjustPopularity adds delta to the popularity without going below zero
+	AdjustPopularity(ctx context.Context, id string, delta int) error
+	// AddTechnology reports false if the project already lists the technology
+	AddTechnology(ctx context.Context, id, technology string) (bool, error)
+	// RemoveTechnology reports false if the project does not list the technology
+	RemoveTechnology(ctx context.Context, id, technology string) (bool, error)
+	DecrementOpenPositions(ctx context.Context, id string) error
+	IsOwner(ctx context.Context, projectID, userID string) (bool, error)
+	// Visible reports whether the project exists and viewer may see it
+	Visible(ctx context.Context, id string, viewer Viewer) (bool, error)
+	// SetOwner makes userID the owner of the project
+	SetOwner(ctx context.Context, projectID, userID string) error
+	// Hide takes the project out of listings and search
+	Hide(ctx context.Context, id, reason string) error
+	Unhide(ctx context.Context, id string) error
+}
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// Create inserts the user and sets its ID
+	Create(ctx context.Context, user *model.User, passwordHash []byte) error
+	GetByID(ctx context.Context, id string) (*model.User, error)
+	// ListByIDs returns the users that exist, in no particular order
+	ListByIDs(ctx context.Context, ids []string) ([]*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	// Update saves the profile fields and email verification state
+	Update(ctx context.Context, user *model.User) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, limit, offset int) ([]*model.User, error)
+	ListPage(ctx context.Context, page Page) ([]*model.User, error)
+	Count(ctx context.Context) (int, error)
+	Search(ctx context.Context, query string) ([]*model.User, error)
+	PasswordHash(ctx context.Context, id string) ([]byte, error)
+	SetPasswordHash(ctx context.Context, id string, hash []byte) error
+	SetEmailVerified(ctx context.Context, id string) error
+	UpdateLastActive(ctx context.Context, id string) error
+	AddSkill(ctx context.Context, id, skill string) error
+	RemoveSkill(ctx context.Context, id, skill string) error
+	Role(ctx context.Context, id string) (model.UserRole, error)
+	// SuspendedAt returns when the user was suspended, or nil
+	SuspendedAt(ctx context.Context, id string) (*string, error)
+	SetRole(ctx context.Context, id string, role model.UserRole) error
+	Suspend(ctx context.Context, id, reason string) error
+	Unsuspend(ctx context.Context, id string) error
+}
+
+// TeamRepository stores teams and their members. Every project has a default
+// team, which is the first one created for it.
+type TeamRepository interface {
+	// Create inserts the team for a project. The team ID is set by the caller.
+	Create(ctx context.Context, team *model.Team, projectID string) error
+	// GetByID returns the team with its project's id and title
+	GetByID(ctx context.Context, id string) (*model.Team, error)
+	Update(ctx context.Context, team *model.Team) error
+	Delete(ctx context.Context, id string) error
+	// ByProject returns the teams of a project, oldest first
+	ByProject(ctx context.Context, projectID string) ([]*model.Team, error)
+	// Members returns the members of a team with their user details
+	Members(ctx context.Context, teamID string) ([]*model.TeamMember, error)
+	// ProjectMembers returns the members of every team of a project
+	ProjectMembers(ctx context.Context, projectID string) ([]*model.TeamMember, error)
+	// DefaultByProjects returns the default team of each project that has one
+	DefaultByProjects(ctx context.Context, projectIDs []string) (map[string]*model.Team, error)
+	// MembersByTeams returns the members of each team with their user details
+	MembersByTeams(ctx context.Context, teamIDs []string) (map[string][]*model.TeamMember, error)
+	// MembersByProjects returns the members of every team of each project
+	MembersByProjects(ctx context.Context, projectIDs []string) (map[string][]*model.TeamMember, error)
+	IsMember(ctx context.Context, teamID, userID string) (bool, error)
+	// IsProjectMember reports whether the user is on any team of the project
+	IsProjectMember(ctx context.Context, projectID, userID string) (bool, error)
+	// AddMember inserts the member. The member ID is set by the caller.
+	AddMember(ctx context.Context, teamID string, member *model.TeamMember) error
+	RemoveMember(ctx context.Context, teamID, userID string) error
+	UpdateMemberRole(ctx context.Context, teamID, userID, role string) error
+}
+
+// TaskRepository stores project tasks
+type TaskRepository interface {
+	// Create inserts the task and sets its ID
+	Create(ctx context.Context, task *model.Task) error
+	// GetByID returns the task with its project title and assignee username
+	GetByID(ctx context.Context, id string) (*model.Task, error)
+	// Update saves every editable field of the task, including its assignee
+	Update(ctx context.Context, task *model.Task) error
+	Delete(ctx context.Context, id string) error
+	// ByProject and ByAssignee list the tasks of a project or assignee that
+	// viewer may see, optionally only those with the given status
+	ByProject(ctx context.Context, projectID string, status *model.TaskStatus, viewer Viewer, limit, offset int) ([]*model.Task, error)
+	ByAssignee(ctx context.Context, userID string, status *model.TaskStatus, viewer Viewer, limit, offset int) ([]*model.Task, error)
+	// Search returns the matching tasks that viewer may see
+	Search(ctx context.Context, query string, viewer Viewer, limit, offset int) ([]*model.Task, error)
+	ListPage(ctx context.Context, filter TaskFilter, page Page) ([]*model.Task, error)
+	Count(ctx context.Context, filter TaskFilter) (int, error)
+	// SetAssignee assigns the task, or unassigns it when userID is nil
+	SetAssignee(ctx context.Context, id string, userID *string) error
+	UpdateStatus(ctx context.Context, id string, status model.TaskStatus) error
+}
+
+// JoinRequestRepository stores requests to join a project. Requests are
+// returned with the user's id and username and the project's id and title.
+type JoinRequestRepository interface {
+	// Create inserts a pending request
+	Create(ctx context.Context, projectID, userID string) error
+	GetByID(ctx context.Context, id string) (*model.JoinRequest, error)
+	GetByUserAndProject(ctx context.Context, userID, projectID string) (*model.JoinRequest, error)
+	ByProject(ctx context.Context, projectID string) ([]*model.JoinRequest, error)
+	// ListPage returns a page of a project's requests, optionally only those
+	// with the given status
+	ListPage(ctx context.Context, projectID string, status *model.JoinRequestStatus, page Page) ([]*model.JoinRequest, error)
+	Count(ctx context.Context, projectID string, status *model.JoinRequestStatus) (int, error)
+	UpdateStatus(ctx context.Context, id string, status model.JoinRequestStatus) error
+}
+
+// AccessToken is a stored personal access token. Only the hash of the token
+// itself is kept.
+type AccessToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// AccessTokenRepository stores personal access tokens
+type AccessTokenRepository interface {
+	// Create inserts the token and sets its ID and creation time
+	Create(ctx context.Context, token *AccessToken, tokenHash string) error
+	// ListByUser returns the user's tokens that have not been revoked, newest
+	// first
+	ListByUser(ctx context.Context, userID string) ([]*AccessToken, error)
+	// Revoke revokes one of the user's active tokens
+	Revoke(ctx context.Context, userID, id string) error
+	// RevokeAll revokes every active token of the user
+	RevokeAll(ctx context.Context, userID string) error
+}
+
+// TwoFactorSecret is the TOTP secret of a user
+type TwoFactorSecret struct {
+	Secret    string
+	Confirmed bool
+	// LastUsedStep is the time step of the last accepted code, so a code
+	// cannot be replayed
+	LastUsedStep *int64
+}
+
+// TwoFactorRepository stores TOTP secrets and hashed recovery codes
+type TwoFactorRepository interface {
+	// SetSecret stores a new secret for the user unless they already
+	// confirmed one
+	SetSecret(ctx context.Context, userID, secret string) error
+	// Secret returns the user's secret. Inside WithTx the secret stays
+	// locked until the transaction ends.
+	Secret(ctx context.Context, userID string) (*TwoFactorSecret, error)
+	// Confirm turns the secret on, recording the step of the code that
+	// confirmed it
+	Confirm(ctx context.Context, userID string, step int64) error
+	SetLastUsedStep(ctx context.Context, userID string, step int64) error
+	// Delete removes the secret and recovery codes of the user
+	Delete(ctx context.Context, userID string) error
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores the
+	// given hashes instead
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UseRecoveryCode marks an unused recovery code as used, returning
+	// ErrNotFound if none matches
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) error
+}
+
+// PasswordResetRepository stores hashed password reset tokens
+type PasswordResetRepository interface {
+	// Create stores a token for the user, invalidating their earlier ones
+	Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	// Consume uses up an unused, unexpired token and returns its user
+	Consume(ctx context.Context, tokenHash string) (string, error)
+}
+
+// ThrottleKey names one failed login counter, such as the one of an account
+// or of a client IP
+type ThrottleKey struct {
+	Scope string
+	Key   string
+}
+
+// ThrottleState is the failed login count of one key
+type ThrottleState struct {
+	ThrottleKey
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// LoginThrottleRepository stores failed login counters
+type LoginThrottleRepository interface {
+	// Get returns the counters of those keys that have one
+	Get(ctx context.Context, keys ...ThrottleKey) ([]*ThrottleState, error)
+	// RecordFailure counts a failure at the given time and returns the new
+	// count. Counting starts over if the last failure was before
+	// resetBefore.
+	RecordFailure(ctx context.Context, key ThrottleKey, at, resetBefore time.Time) (int, error)
+	// Lock locks the key out until the given time and clears its failures
+	Lock(ctx context.Context, key ThrottleKey, until time.Time) error
+	Clear(ctx context.Context, key ThrottleKey) error
+}
+
+// ModerationReportRepository stores reports filed about users and projects.
+// Reports are returned with only the IDs of the reporter and resolving admin.
+type ModerationReportRepository interface {
+	// Create inserts an open report and sets its ID and creation time
+	Create(ctx context.Context, report *model.ModerationReport, reporterID string) error
+	GetByID(ctx context.Context, id string) (*model.ModerationReport, error)
+	// List returns reports newest first, optionally only those with the given
+	// status
+	List(ctx context.Context, status *model.ModerationReportStatus, limit, offset int) ([]*model.ModerationReport, error)
+	// Resolve records how an admin closed the report
+	Resolve(ctx context.Context, id, adminID string, status model.ModerationReportStatus, note *string) error
+}
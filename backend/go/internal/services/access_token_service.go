@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+var ErrAccessTokenNotFound = errors.New("personal access token not found")
+
+var tokenScopeValues = map[model.TokenScope]string{
+	model.TokenScopeProjectsRead:  auth.ScopeProjectsRead,
+	model.TokenScopeProjectsWrite: auth.ScopeProjectsWrite,
+	model.TokenScopeTasksRead:     auth.ScopeTasksRead,
+	model.TokenScopeTasksWrite:    auth.ScopeTasksWrite,
+	model.TokenScopeTeamsAdmin:    auth.ScopeTeamsAdmin,
+	model.TokenScopeUsersRead:     auth.ScopeUsersRead,
+}
+
+// TokenScopeValue maps a schema scope to the value stored on a token
+func TokenScopeValue(scope model.TokenScope) string {
+	return tokenScopeValues[scope]
+}
+
+type AccessTokenService struct {
+	Store repository.Store
+}
+
+func NewAccessTokenService(store repository.Store) *AccessTokenService {
+	return &AccessTokenService{
+		Store: store,
+	}
+}
+
+// CreateToken issues a personal access token for a user. The plain token is
+// only returned here; just its hash is stored.
+func (s *AccessTokenService) CreateToken(ctx context.Context, userID string, input model.CreatePersonalAccessTokenInput) (*model.CreatePersonalAccessTokenPayload, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("token name is required")
+	}
+	if len(input.Scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *input.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiresAt: %w", err)
+		}
+		if !t.After(time.Now()) {
+			return nil, fmt.Errorf("expiresAt must be in the future")
+		}
+		expiresAt = &t
+	}
+
+	scopes := make([]string, 0, len(input.Scopes))
+	seen := make(map[model.TokenScope]bool)
+	for _, scope := range input.Scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		scopes = append(scopes, TokenScopeValue(scope))
+	}
+
+	token, hash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+
+	stored := &repository.AccessToken{UserID: userID, Name: name, Scopes: scopes, ExpiresAt: expiresAt}
+	err = s.Store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.AccessTokens().Create(ctx, stored, hash); err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionAccessTokenCreate,
+			TargetType: audit.TargetAccessToken,
+			TargetID:   stored.ID,
+			After:      map[string]interface{}{"name": name, "scopes": scopes, "expiresAt": formatOptionalTime(stored.ExpiresAt)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CreatePersonalAccessTokenPayload{
+		Token:               token,
+		PersonalAccessToken: toPersonalAccessToken(stored),
+	}, nil
+}
+
+// ListTokens returns the active personal access tokens of a user
+func (s *AccessTokenService) ListTokens(ctx context.Context, userID string) ([]*model.PersonalAccessToken, error) {
+	stored, err := s.Store.AccessTokens().ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*model.PersonalAccessToken, 0, len(stored))
+	for _, token := range stored {
+		tokens = append(tokens, toPersonalAccessToken(token))
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes one of the user's personal access tokens
+func (s *AccessTokenService) RevokeToken(ctx context.Context, userID, tokenID string) error {
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.AccessTokens().Revoke(ctx, userID, tokenID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAccessTokenNotFound
+		} else if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionAccessTokenRevoke,
+			TargetType: audit.TargetAccessToken,
+			TargetID:   tokenID,
+		})
+	})
+}
+
+// toPersonalAccessToken converts a stored token for the schema, leaving out
+// scopes the schema no longer knows
+func toPersonalAccessToken(token *repository.AccessToken) *model.PersonalAccessToken {
+	pat := &model.PersonalAccessToken{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     []model.TokenScope{},
+		ExpiresAt:  formatOptionalTime(token.ExpiresAt),
+		LastUsedAt: formatOptionalTime(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt.Format(time.RFC3339),
+	}
+	for _, value := range token.Scopes {
+		for scope, v := range tokenScopeValues {
+			if v == value {
+				pat.Scopes = append(pat.Scopes, scope)
+			}
+		}
+	}
+	return pat
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
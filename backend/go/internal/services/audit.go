@@ -0,0 +1,18 @@
+package services
+
+import (
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+)
+
+// projectSnapshot captures the editable fields of a project for the audit log
+func projectSnapshot(p *model.Project) map[string]interface{} {
+	return map[string]interface{}{
+		"title":              p.Title,
+		"description":        p.Description,
+		"category":           p.Category,
+		"status":             p.Status,
+		"openPositions":      p.OpenPositions,
+		"timeCommitment":     p.TimeCommitment,
+		"learningObjectives": p.LearningObjectives,
+	}
+}
@@ -2,123 +2,116 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
-	"github.com/evan3v4n/Projectivity/backend/go/internal/database"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrJoinRequestNotPending = errors.New("join request has already been decided")
+	ErrAlreadyProjectMember  = errors.New("user is already a member of this project")
 )
 
 type JoinRequestService struct {
-	DB *sql.DB
+	Store repository.Store
 }
 
-func NewJoinRequestService(db *sql.DB) *JoinRequestService {
-	return &JoinRequestService{DB: db}
+func NewJoinRequestService(store repository.Store) *JoinRequestService {
+	return &JoinRequestService{Store: store}
 }
 
 func (s *JoinRequestService) CreateJoinRequest(ctx context.Context, projectID, userID string) (*model.JoinRequest, error) {
-	log.Printf("Creating join request for user %s to project %s", userID, projectID)
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		// Check if the project exists
+		if _, err := tx.Projects().GetByID(ctx, projectID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("project not found")
+			}
+			return fmt.Errorf("failed to check project existence: %w", err)
+		}
+
+		// Owners and members have nothing to ask for
+		isOwner, err := tx.Projects().IsOwner(ctx, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check project ownership: %w", err)
+		}
+		isMember, err := tx.Teams().IsProjectMember(ctx, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check project membership: %w", err)
+		}
+		if isOwner || isMember {
+			return ErrAlreadyProjectMember
+		}
 
-	err := database.RequestToJoinProject(ctx, projectID, userID)
+		// Check if a request already exists
+		_, err = tx.JoinRequests().GetByUserAndProject(ctx, userID, projectID)
+		if err == nil {
+			return fmt.Errorf("join request already exists")
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to check existing request: %w", err)
+		}
+
+		return tx.JoinRequests().Create(ctx, projectID, userID)
+	})
 	if err != nil {
-		log.Printf("Error in database.RequestToJoinProject: %v", err)
 		return nil, fmt.Errorf("failed to create join request: %w", err)
 	}
-
-	log.Printf("Join request created successfully in database")
+	slog.InfoContext(ctx, "Join request created", "project_id", projectID)
+	metrics.JoinRequestsSubmitted.Inc()
 
 	// Fetch and return the created join request
 	joinRequest, err := s.GetJoinRequestByUserAndProject(ctx, userID, projectID)
 	if err != nil {
-		log.Printf("Error fetching created join request: %v", err)
 		return nil, fmt.Errorf("failed to fetch created join request: %w", err)
 	}
 
-	log.Printf("Fetched join request: %+v", joinRequest)
-
 	return joinRequest, nil
 }
 
 func (s *JoinRequestService) GetJoinRequestByUserAndProject(ctx context.Context, userID, projectID string) (*model.JoinRequest, error) {
-	query := `
-		SELECT jr.id, jr.status, jr.created_at, 
-			   u.id as user_id, u.username, 
-			   p.id as project_id, p.title
-		FROM join_requests jr
-		JOIN users u ON jr.user_id = u.id
-		JOIN projects p ON jr.project_id = p.id
-		WHERE jr.user_id = $1 AND jr.project_id = $2
-	`
-
-	var jr model.JoinRequest
-	var user model.User
-	var project model.Project
-
-	err := s.DB.QueryRowContext(ctx, query, userID, projectID).Scan(
-		&jr.ID, &jr.Status, &jr.CreatedAt,
-		&user.ID, &user.Username,
-		&project.ID, &project.Title,
-	)
-
+	joinRequest, err := s.Store.JoinRequests().GetByUserAndProject(ctx, userID, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get join request: %w", err)
 	}
-
-	jr.User = &user
-	jr.Project = &project
-
-	return &jr, nil
+	return joinRequest, nil
 }
 
-// Add this new method to the JoinRequestService struct
-
 func (s *JoinRequestService) GetJoinRequestsByProject(ctx context.Context, projectID string) ([]*model.JoinRequest, error) {
-	query := `
-		SELECT jr.id, jr.status, jr.created_at, 
-			   u.id as user_id, u.username, 
-			   p.id as project_id, p.title
-		FROM join_requests jr
-		JOIN users u ON jr.user_id = u.id
-		JOIN projects p ON jr.project_id = p.id
-		WHERE jr.project_id = $1
-	`
+	return s.Store.JoinRequests().ByProject(ctx, projectID)
+}
 
-	rows, err := s.DB.QueryContext(ctx, query, projectID)
+// ListJoinRequestsConnection returns a page of a project's join requests,
+// optionally only those with the given status. The total is only counted
+// when withTotal is set.
+func (s *JoinRequestService) ListJoinRequestsConnection(ctx context.Context, projectID string, status *model.JoinRequestStatus, args pagination.Args, withTotal bool) (*model.JoinRequestConnection, error) {
+	page, err := pagination.Fetch(args, func(p repository.Page) ([]*model.JoinRequest, error) {
+		return s.Store.JoinRequests().ListPage(ctx, projectID, status, p)
+	}, func(jr *model.JoinRequest) repository.Cursor {
+		return repository.Cursor{CreatedAt: jr.CreatedAt, ID: jr.ID}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query join requests: %w", err)
 	}
-	defer rows.Close()
-
-	var joinRequests []*model.JoinRequest
-
-	for rows.Next() {
-		var jr model.JoinRequest
-		var user model.User
-		var project model.Project
-
-		err := rows.Scan(
-			&jr.ID, &jr.Status, &jr.CreatedAt,
-			&user.ID, &user.Username,
-			&project.ID, &project.Title,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan join request: %w", err)
-		}
-
-		jr.User = &user
-		jr.Project = &project
 
-		joinRequests = append(joinRequests, &jr)
+	conn := &model.JoinRequestConnection{Edges: []*model.JoinRequestEdge{}, PageInfo: page.PageInfo}
+	for i, jr := range page.Items {
+		conn.Edges = append(conn.Edges, &model.JoinRequestEdge{Cursor: page.Cursors[i], Node: jr})
 	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating join requests: %w", err)
+	if withTotal {
+		if conn.TotalCount, err = s.Store.JoinRequests().Count(ctx, projectID, status); err != nil {
+			return nil, err
+		}
 	}
-
-	return joinRequests, nil
+	return conn, nil
 }
 
 func (s *JoinRequestService) ApproveJoinRequest(ctx context.Context, requestID, approverID string) (*model.JoinRequest, error) {
@@ -126,207 +119,99 @@ func (s *JoinRequestService) ApproveJoinRequest(ctx context.Context, requestID,
 }
 
 func (s *JoinRequestService) DenyJoinRequest(ctx context.Context, requestID string, userID string) (*model.JoinRequest, error) {
-	// Start a transaction
-	tx, err := s.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	// Get the join request
-	joinRequest, err := s.getJoinRequestByID(ctx, tx, requestID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get join request: %w", err)
-	}
-
-	// Check if the user is the project owner
-	isOwner, err := s.isProjectOwner(ctx, tx, joinRequest.Project.ID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check project ownership: %w", err)
-	}
-	if !isOwner {
-		return nil, fmt.Errorf("unauthorized: only the project owner can deny join requests")
-	}
-
-	// Update the join request status to REJECTED
-	query := `UPDATE join_requests SET status = $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, query, model.JoinRequestStatusRejected, requestID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update join request status: %w", err)
-	}
-
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// Fetch and return the updated join request
-	updatedJoinRequest, err := s.GetJoinRequestByID(ctx, requestID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch updated join request: %w", err)
-	}
-
-	return updatedJoinRequest, nil
+	return s.updateJoinRequestStatus(ctx, requestID, userID, model.JoinRequestStatusRejected)
 }
 
 func (s *JoinRequestService) updateJoinRequestStatus(ctx context.Context, requestID, userID string, status model.JoinRequestStatus) (*model.JoinRequest, error) {
-	log.Printf("Updating join request status: requestID=%s, userID=%s, status=%s", requestID, userID, status)
-
-	// Start a transaction
-	tx, err := s.DB.BeginTx(ctx, nil)
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return nil, fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer tx.Rollback()
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		// Get the join request
+		joinRequest, err := tx.JoinRequests().GetByID(ctx, requestID)
+		if err != nil {
+			return fmt.Errorf("failed to get join request: %w", err)
+		}
 
-	// Get the join request
-	joinRequest, err := s.getJoinRequestByID(ctx, tx, requestID)
-	if err != nil {
-		log.Printf("Error getting join request: %v", err)
-		return nil, err
-	}
-	log.Printf("Retrieved join request: %+v", joinRequest)
+		// Check if the user is the project owner
+		isOwner, err := tx.Projects().IsOwner(ctx, joinRequest.Project.ID, userID)
+		if err != nil {
+			return err
+		}
+		if !isOwner {
+			slog.WarnContext(ctx, "Join request decision by non-owner refused", "join_request_id", requestID, "project_id", joinRequest.Project.ID)
+			return fmt.Errorf("unauthorized: user is not the project owner")
+		}
 
-	// Check if the user is the project owner
-	isOwner, err := s.isProjectOwner(ctx, tx, joinRequest.Project.ID, userID)
-	if err != nil {
-		log.Printf("Error checking project ownership: %v", err)
-		return nil, err
-	}
-	if !isOwner {
-		log.Printf("Unauthorized: user %s is not the owner of project %s", userID, joinRequest.Project.ID)
-		return nil, fmt.Errorf("unauthorized: user is not the project owner")
-	}
+		// A decision is final
+		if joinRequest.Status != model.JoinRequestStatusPending {
+			return fmt.Errorf("%w: it is %s", ErrJoinRequestNotPending, joinRequest.Status)
+		}
 
-	// Update the join request status
-	query := `UPDATE join_requests SET status = $1 WHERE id = $2`
-	_, err = tx.ExecContext(ctx, query, status, requestID)
-	if err != nil {
-		log.Printf("Error updating join request status: %v", err)
-		return nil, fmt.Errorf("failed to update join request status: %w", err)
-	}
-	log.Printf("Updated join request status to %s", status)
+		if err := tx.JoinRequests().UpdateStatus(ctx, requestID, status); err != nil {
+			return fmt.Errorf("failed to update join request status: %w", err)
+		}
 
-	// If approved, add the user to the project team
-	if status == model.JoinRequestStatusApproved {
-		err = s.addUserToProject(ctx, tx, joinRequest.Project.ID, joinRequest.User.ID)
-		if err != nil {
-			log.Printf("Error adding user to project: %v", err)
-			return nil, err
+		// If approved, add the user to the project team
+		action := audit.ActionJoinRequestDeny
+		if status == model.JoinRequestStatusApproved {
+			if err := addUserToProject(ctx, tx, joinRequest.Project.ID, joinRequest.User.ID); err != nil {
+				return err
+			}
+			action = audit.ActionJoinRequestApprove
 		}
-		log.Printf("Added user %s to project %s", joinRequest.User.ID, joinRequest.Project.ID)
-	}
 
-	// Commit the transaction
-	if err = tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return tx.RecordAudit(ctx, joinRequestAuditEvent(action, joinRequest, status))
+	})
+	if err != nil {
+		return nil, err
 	}
-	log.Printf("Transaction committed successfully")
+	slog.InfoContext(ctx, "Join request decided", "join_request_id", requestID, "status", status)
+	metrics.JoinRequestsDecided.WithLabelValues(strings.ToLower(string(status))).Inc()
 
 	// Return the updated join request
 	updatedJoinRequest, err := s.GetJoinRequestByID(ctx, requestID)
 	if err != nil {
-		log.Printf("Error fetching updated join request: %v", err)
 		return nil, fmt.Errorf("failed to fetch updated join request: %w", err)
 	}
-	log.Printf("Retrieved updated join request: %+v", updatedJoinRequest)
 
 	return updatedJoinRequest, nil
 }
 
-func (s *JoinRequestService) getJoinRequestByID(ctx context.Context, tx *sql.Tx, requestID string) (*model.JoinRequest, error) {
-	query := `
-		SELECT jr.id, jr.status, jr.created_at, 
-			   u.id as user_id, u.username, 
-			   p.id as project_id, p.title
-		FROM join_requests jr
-		JOIN users u ON jr.user_id = u.id
-		JOIN projects p ON jr.project_id = p.id
-		WHERE jr.id = $1
-	`
-
-	var jr model.JoinRequest
-	var user model.User
-	var project model.Project
-
-	err := tx.QueryRowContext(ctx, query, requestID).Scan(
-		&jr.ID, &jr.Status, &jr.CreatedAt,
-		&user.ID, &user.Username,
-		&project.ID, &project.Title,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get join request: %w", err)
+// joinRequestAuditEvent describes a decision on a join request
+func joinRequestAuditEvent(action string, joinRequest *model.JoinRequest, status model.JoinRequestStatus) audit.Event {
+	return audit.Event{
+		Action:     action,
+		TargetType: audit.TargetJoinRequest,
+		TargetID:   joinRequest.ID,
+		ProjectID:  joinRequest.Project.ID,
+		Before:     map[string]interface{}{"status": joinRequest.Status, "userId": joinRequest.User.ID},
+		After:      map[string]interface{}{"status": status, "userId": joinRequest.User.ID},
 	}
-
-	jr.User = &user
-	jr.Project = &project
-
-	return &jr, nil
 }
 
-func (s *JoinRequestService) isProjectOwner(ctx context.Context, tx *sql.Tx, projectID, userID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM project_owners WHERE project_id = $1 AND user_id = $2)`
-	var isOwner bool
-	err := tx.QueryRowContext(ctx, query, projectID, userID).Scan(&isOwner)
+// addUserToProject adds an approved user to the project's default team
+func addUserToProject(ctx context.Context, tx repository.Store, projectID, userID string) error {
+	team, err := defaultTeam(ctx, tx, projectID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check project ownership: %w", err)
-	}
-	return isOwner, nil
-}
-
-func (s *JoinRequestService) addUserToProject(ctx context.Context, tx *sql.Tx, projectID, userID string) error {
-	log.Printf("Attempting to add user %s to project %s", userID, projectID)
-
-	// First, get the team_id for the project
-	var teamID string
-	err := tx.QueryRowContext(ctx, "SELECT id FROM teams WHERE project_id = $1", projectID).Scan(&teamID)
-	if err != nil {
-		log.Printf("Error getting team_id for project: %v", err)
-		return fmt.Errorf("failed to get team_id for project: %w", err)
+		return err
 	}
 
-	// Insert the user into the team_members table
-	query := `INSERT INTO team_members (team_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)`
-	_, err = tx.ExecContext(ctx, query, teamID, userID, "Member", time.Now())
+	err = tx.Teams().AddMember(ctx, team.ID, &model.TeamMember{
+		ID:       uuid.New().String(),
+		User:     &model.User{ID: userID},
+		Role:     "Member",
+		JoinedAt: time.Now().Format(time.RFC3339),
+	})
 	if err != nil {
-		log.Printf("Error adding user to project team: %v", err)
 		return fmt.Errorf("failed to add user to project team: %w", err)
 	}
 
-	log.Printf("Successfully added user %s to project %s (team_id: %s)", userID, projectID, teamID)
+	slog.DebugContext(ctx, "Added user to project team", "member_user_id", userID, "project_id", projectID, "team_id", team.ID)
 	return nil
 }
 
 func (s *JoinRequestService) GetJoinRequestByID(ctx context.Context, requestID string) (*model.JoinRequest, error) {
-	query := `
-		SELECT jr.id, jr.status, jr.created_at, 
-			   u.id as user_id, u.username, 
-			   p.id as project_id, p.title
-		FROM join_requests jr
-		JOIN users u ON jr.user_id = u.id
-		JOIN projects p ON jr.project_id = p.id
-		WHERE jr.id = $1
-	`
-
-	var jr model.JoinRequest
-	var user model.User
-	var project model.Project
-
-	err := s.DB.QueryRowContext(ctx, query, requestID).Scan(
-		&jr.ID, &jr.Status, &jr.CreatedAt,
-		&user.ID, &user.Username,
-		&project.ID, &project.Title,
-	)
-
+	joinRequest, err := s.Store.JoinRequests().GetByID(ctx, requestID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get join request: %w", err)
 	}
-
-	jr.User = &user
-	jr.Project = &project
-
-	return &jr, nil
+	return joinRequest, nil
 }
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+)
+
+func TestCreateJoinRequest(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Status != model.JoinRequestStatusPending {
+		t.Errorf("status = %s, want %s", request.Status, model.JoinRequestStatusPending)
+	}
+	if request.User.Username != "applicant" || request.Project.Title != "Compiler" {
+		t.Errorf("request = %+v %+v", request.User, request.Project)
+	}
+
+	if _, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID); err == nil {
+		t.Error("duplicate join request was accepted")
+	}
+	if _, err := env.joinRequests.CreateJoinRequest(ctx, "missing", applicant.ID); err == nil {
+		t.Error("join request for a missing project was accepted")
+	}
+
+	requests, err := env.joinRequests.GetJoinRequestsByProject(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("requests = %d, want 1", len(requests))
+	}
+}
+
+func TestApproveJoinRequestAddsMember(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approved, err := env.joinRequests.ApproveJoinRequest(ctx, request.ID, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != model.JoinRequestStatusApproved {
+		t.Errorf("status = %s, want %s", approved.Status, model.JoinRequestStatusApproved)
+	}
+
+	members, err := env.projects.GetProjectTeamMembers(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 || members[1].User.ID != applicant.ID || members[1].Role != "Member" {
+		t.Fatalf("members = %+v, want the applicant added", members)
+	}
+	if !env.hasAudit(audit.ActionJoinRequestApprove) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionJoinRequestApprove)
+	}
+}
+
+func TestJoinRequestDecisionRequiresOwner(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.joinRequests.ApproveJoinRequest(ctx, request.ID, applicant.ID); err == nil {
+		t.Fatal("a non-owner approved the request")
+	}
+
+	got, err := env.joinRequests.GetJoinRequestByID(ctx, request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != model.JoinRequestStatusPending {
+		t.Errorf("status = %s, want it left pending", got.Status)
+	}
+	if len(env.store.AuditEvents()) != 0 {
+		t.Errorf("audit = %v, want nothing recorded", env.auditActions())
+	}
+}
+
+func TestDenyJoinRequest(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denied, err := env.joinRequests.DenyJoinRequest(ctx, request.ID, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if denied.Status != model.JoinRequestStatusRejected {
+		t.Errorf("status = %s, want %s", denied.Status, model.JoinRequestStatusRejected)
+	}
+
+	members, err := env.projects.GetProjectTeamMembers(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 {
+		t.Errorf("members = %d, want only the owner", len(members))
+	}
+	if !env.hasAudit(audit.ActionJoinRequestDeny) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionJoinRequestDeny)
+	}
+}
+
+func TestJoinRequestDecisionIsFinal(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.joinRequests.DenyJoinRequest(ctx, request.ID, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.joinRequests.ApproveJoinRequest(ctx, request.ID, owner.ID); !errors.Is(err, ErrJoinRequestNotPending) {
+		t.Errorf("approve denied request: err = %v, want %v", err, ErrJoinRequestNotPending)
+	}
+	if _, err := env.joinRequests.DenyJoinRequest(ctx, request.ID, owner.ID); !errors.Is(err, ErrJoinRequestNotPending) {
+		t.Errorf("deny denied request: err = %v, want %v", err, ErrJoinRequestNotPending)
+	}
+
+	members, err := env.projects.GetProjectTeamMembers(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 {
+		t.Errorf("members = %d, want only the owner", len(members))
+	}
+}
+
+func TestCreateJoinRequestForOwnProject(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	if _, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, owner.ID); !errors.Is(err, ErrAlreadyProjectMember) {
+		t.Errorf("owner: err = %v, want %v", err, ErrAlreadyProjectMember)
+	}
+
+	request, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.joinRequests.ApproveJoinRequest(ctx, request.ID, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID); !errors.Is(err, ErrAlreadyProjectMember) {
+		t.Errorf("member: err = %v, want %v", err, ErrAlreadyProjectMember)
+	}
+}
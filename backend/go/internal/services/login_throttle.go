@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+const (
+	throttleScopeAccount = "account"
+	throttleScopeIP      = "ip"
+)
+
+// throttlePolicy controls how quickly repeated failures for one key are
+// slowed down and eventually locked out
+type throttlePolicy struct {
+	FreeAttempts     int
+	LockoutThreshold int
+}
+
+// LoginLockedError is returned when a login attempt is refused because of
+// earlier failures for the same account or client IP
+type LoginLockedError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginLockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts; account is locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed login attempts; try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginThrottle tracks failed logins per account and per client IP in the
+// store so the limits hold across replicas
+type LoginThrottle struct {
+	Counters        repository.LoginThrottleRepository
+	Account         throttlePolicy
+	IP              throttlePolicy
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	// Failures older than this no longer count towards backoff or lockout
+	ResetAfter time.Duration
+}
+
+func NewLoginThrottle(counters repository.LoginThrottleRepository) *LoginThrottle {
+	return &LoginThrottle{
+		Counters:        counters,
+		Account:         throttlePolicy{FreeAttempts: 3, LockoutThreshold: 10},
+		IP:              throttlePolicy{FreeAttempts: 10, LockoutThreshold: 50},
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutDuration: 30 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+}
+
+// Check returns a *LoginLockedError if the account or IP must wait before
+// trying again
+func (t *LoginThrottle) Check(ctx context.Context, email, ip string) error {
+	states, err := t.Counters.Get(ctx, accountKey(email), ipKey(ip))
+	if err != nil {
+		return fmt.Errorf("failed to check login throttle: %w", err)
+	}
+
+	now := time.Now()
+	var retryAt time.Time
+	locked := false
+
+	for _, state := range states {
+		if state.LockedUntil != nil && state.LockedUntil.After(now) {
+			locked = true
+			if state.LockedUntil.After(retryAt) {
+				retryAt = *state.LockedUntil
+			}
+			continue
+		}
+
+		if now.Sub(state.LastFailureAt) > t.ResetAfter {
+			continue
+		}
+		if next := state.LastFailureAt.Add(t.backoff(t.policyFor(state.Scope), state.Failures)); next.After(retryAt) {
+			retryAt = next
+		}
+	}
+
+	if retryAt.After(now) {
+		return &LoginLockedError{RetryAfter: retryAt.Sub(now), Locked: locked}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against both the account and the IP
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email, ip string) error {
+	if err := t.recordFailure(ctx, accountKey(email), t.Account); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return t.recordFailure(ctx, ipKey(ip), t.IP)
+}
+
+// RecordSuccess clears the failure count for the account. The IP keeps its
+// count so one valid login does not reset a credential-stuffing client.
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	return t.Unlock(ctx, email)
+}
+
+// Unlock clears failures and any lockout for an account
+func (t *LoginThrottle) Unlock(ctx context.Context, email string) error {
+	return t.Counters.Clear(ctx, accountKey(email))
+}
+
+func (t *LoginThrottle) recordFailure(ctx context.Context, key repository.ThrottleKey, policy throttlePolicy) error {
+	now := time.Now().UTC()
+	failures, err := t.Counters.RecordFailure(ctx, key, now, now.Add(-t.ResetAfter))
+	if err != nil {
+		return err
+	}
+
+	if failures >= policy.LockoutThreshold {
+		return t.Counters.Lock(ctx, key, now.Add(t.LockoutDuration))
+	}
+	return nil
+}
+
+func accountKey(email string) repository.ThrottleKey {
+	return repository.ThrottleKey{Scope: throttleScopeAccount, Key: normalizeEmail(email)}
+}
+
+func ipKey(ip string) repository.ThrottleKey {
+	return repository.ThrottleKey{Scope: throttleScopeIP, Key: ip}
+}
+
+// backoff doubles the required wait for every failure past the free attempts
+func (t *LoginThrottle) backoff(policy throttlePolicy, failures int) time.Duration {
+	excess := failures - policy.FreeAttempts
+	if excess <= 0 {
+		return 0
+	}
+
+	delay := time.Duration(float64(t.BaseDelay) * math.Pow(2, float64(excess-1)))
+	if delay > t.MaxDelay || delay <= 0 {
+		return t.MaxDelay
+	}
+	return delay
+}
+
+func (t *LoginThrottle) policyFor(scope string) throttlePolicy {
+	if scope == throttleScopeIP {
+		return t.IP
+	}
+	return t.Account
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
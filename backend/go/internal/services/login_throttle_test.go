@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func TestLoginThrottleIgnoresSpoofedForwardedFor(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ada")
+
+	proxies, err := auth.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loginErr error
+	handler := proxies.RealIP(auth.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email := r.URL.Query().Get("email")
+		_, loginErr = env.users.LoginUser(r.Context(), email, "wrong password")
+	})))
+
+	// One client guessing passwords for many accounts, claiming a new
+	// address every time. Each account stays under its own limit, so only
+	// the per-IP counter can stop it.
+	attempts := env.users.Throttle.IP.FreeAttempts + 1
+	for i := 0; i <= attempts; i++ {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/?email=user%d@example.com", i), nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		req.Header.Set("X-Real-IP", fmt.Sprintf("198.51.100.%d", i))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var locked *LoginLockedError
+	if !errors.As(loginErr, &locked) {
+		t.Fatalf("err = %v after %d failures from one address, want a LoginLockedError", loginErr, attempts)
+	}
+}
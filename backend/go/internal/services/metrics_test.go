@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// counterDelta returns how much c grows while fn runs
+func counterDelta(c prometheus.Collector, fn func()) float64 {
+	before := testutil.ToFloat64(c)
+	fn()
+	return testutil.ToFloat64(c) - before
+}
+
+func TestBusinessCounters(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	dev := env.createUser(t, "dev")
+
+	var project *model.Project
+	if d := counterDelta(metrics.ProjectsCreated, func() { project = env.createProject(t, owner.ID, "Compiler") }); d != 1 {
+		t.Errorf("projects created grew by %v, want 1", d)
+	}
+
+	var request *model.JoinRequest
+	if d := counterDelta(metrics.JoinRequestsSubmitted, func() {
+		var err error
+		if request, err = env.joinRequests.CreateJoinRequest(ctx, project.ID, dev.ID); err != nil {
+			t.Fatal(err)
+		}
+	}); d != 1 {
+		t.Errorf("join requests submitted grew by %v, want 1", d)
+	}
+
+	approved := metrics.JoinRequestsDecided.WithLabelValues("approved")
+	if d := counterDelta(approved, func() {
+		if _, err := env.joinRequests.ApproveJoinRequest(ctx, request.ID, owner.ID); err != nil {
+			t.Fatal(err)
+		}
+	}); d != 1 {
+		t.Errorf("join requests approved grew by %v, want 1", d)
+	}
+
+	task, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{Title: "Lexer", Priority: model.TaskPriorityHigh, ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := model.TaskStatusDone
+	d := counterDelta(metrics.TasksCompleted, func() {
+		if err := env.tasks.UpdateTaskStatus(ctx, task.ID, done); err != nil {
+			t.Fatal(err)
+		}
+		// Already done, so not counted again
+		if _, err := env.tasks.UpdateTask(ctx, task.ID, model.UpdateTaskInput{Status: &done}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if d != 1 {
+		t.Errorf("tasks completed grew by %v, want 1", d)
+	}
+}
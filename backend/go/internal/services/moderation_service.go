@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/google/uuid"
+)
+
+var ErrModerationReportNotFound = errors.New("moderation report not found")
+
+// ModerationService holds the operations available to site administrators
+type ModerationService struct {
+	Store          repository.Store
+	UserService    *UserService
+	ProjectService *ProjectService
+}
+
+func NewModerationService(store repository.Store, userService *UserService, projectService *ProjectService) *ModerationService {
+	return &ModerationService{
+		Store:          store,
+		UserService:    userService,
+		ProjectService: projectService,
+	}
+}
+
+// SuspendUser blocks a user from logging in and signs them out everywhere,
+// including their personal access tokens
+func (s *ModerationService) SuspendUser(ctx context.Context, adminID, userID, reason string) (*model.User, error) {
+	if adminID == userID {
+		return nil, errors.New("you cannot suspend your own account")
+	}
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Users().Suspend(ctx, userID, reason)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("user not found")
+		} else if err != nil {
+			return err
+		}
+
+		if err := tx.Sessions().RevokeAll(ctx, userID, ""); err != nil {
+			return err
+		}
+		if err := tx.AccessTokens().RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionUserSuspend,
+			ActorID:    adminID,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+			After:      map[string]interface{}{"reason": reason},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserService.GetUserByID(ctx, userID)
+}
+
+// UnsuspendUser lets a suspended user log in again
+func (s *ModerationService) UnsuspendUser(ctx context.Context, adminID, userID string) (*model.User, error) {
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Users().Unsuspend(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("user not found")
+		} else if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionUserUnsuspend,
+			ActorID:    adminID,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserService.GetUserByID(ctx, userID)
+}
+
+// SetUserRole grants or removes the site administrator role. Admins cannot
+// demote themselves, so there is always at least one left.
+func (s *ModerationService) SetUserRole(ctx context.Context, adminID, userID string, role model.UserRole) (*model.User, error) {
+	if adminID == userID && role != model.UserRoleAdmin {
+		return nil, errors.New("you cannot remove your own admin role")
+	}
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		previous, err := tx.Users().Role(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("user not found")
+		} else if err != nil {
+			return fmt.Errorf("failed to look up user role: %w", err)
+		}
+
+		if err := tx.Users().SetRole(ctx, userID, role); err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionUserRoleChange,
+			ActorID:    adminID,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+			Before:     map[string]interface{}{"role": previous},
+			After:      map[string]interface{}{"role": role},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UserService.GetUserByID(ctx, userID)
+}
+
+// HideProject takes a project out of listings and search. Its owner and
+// admins can still see it.
+func (s *ModerationService) HideProject(ctx context.Context, adminID, projectID, reason string) (*model.Project, error) {
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Projects().Hide(ctx, projectID, reason)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("project not found")
+		} else if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionProjectHide,
+			ActorID:    adminID,
+			TargetType: audit.TargetProject,
+			TargetID:   projectID,
+			ProjectID:  projectID,
+			After:      map[string]interface{}{"reason": reason},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ProjectService.GetProjectByID(ctx, projectID)
+}
+
+// UnhideProject makes a hidden project public again
+func (s *ModerationService) UnhideProject(ctx context.Context, adminID, projectID string) (*model.Project, error) {
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.Projects().Unhide(ctx, projectID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("project not found")
+		} else if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionProjectUnhide,
+			ActorID:    adminID,
+			TargetType: audit.TargetProject,
+			TargetID:   projectID,
+			ProjectID:  projectID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ProjectService.GetProjectByID(ctx, projectID)
+}
+
+// ForceDeleteProject deletes a project regardless of who owns it
+func (s *ModerationService) ForceDeleteProject(ctx context.Context, adminID, projectID string) error {
+	if err := s.ProjectService.deleteProject(ctx, adminID, projectID); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Project force-deleted by admin", "project_id", projectID, "admin_id", adminID)
+	return nil
+}
+
+// TransferProjectOwnership makes another user the owner of a project. The
+// previous owner stays on the project team as a regular member.
+func (s *ModerationService) TransferProjectOwnership(ctx context.Context, adminID, projectID, newOwnerID string) (*model.Project, error) {
+	if err := s.UserService.checkUserActive(ctx, newOwnerID); err != nil {
+		return nil, fmt.Errorf("cannot transfer project to this user: %w", err)
+	}
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		project, err := tx.Projects().GetByID(ctx, projectID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errors.New("project not found")
+		} else if err != nil {
+			return fmt.Errorf("failed to look up project owner: %w", err)
+		}
+		previousOwnerID := project.Owner.ID
+		if previousOwnerID == newOwnerID {
+			return nil
+		}
+
+		if err := tx.Projects().SetOwner(ctx, projectID, newOwnerID); err != nil {
+			return err
+		}
+
+		teams, err := tx.Teams().DefaultByProjects(ctx, []string{projectID})
+		if err != nil {
+			return fmt.Errorf("failed to get default team: %w", err)
+		}
+		team, ok := teams[projectID]
+		if !ok {
+			return errors.New("project has no team")
+		}
+
+		err = tx.Teams().UpdateMemberRole(ctx, team.ID, previousOwnerID, "Member")
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to update previous owner: %w", err)
+		}
+
+		err = tx.Teams().UpdateMemberRole(ctx, team.ID, newOwnerID, "Owner")
+		if errors.Is(err, repository.ErrNotFound) {
+			err = tx.Teams().AddMember(ctx, team.ID, &model.TeamMember{
+				ID:       uuid.New().String(),
+				User:     &model.User{ID: newOwnerID},
+				Role:     "Owner",
+				JoinedAt: time.Now().Format(time.RFC3339),
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to make the new owner a team owner: %w", err)
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionProjectTransfer,
+			ActorID:    adminID,
+			TargetType: audit.TargetProject,
+			TargetID:   projectID,
+			ProjectID:  projectID,
+			Before:     map[string]interface{}{"ownerId": previousOwnerID},
+			After:      map[string]interface{}{"ownerId": newOwnerID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ProjectService.GetProjectByID(ctx, projectID)
+}
+
+// ReportContent files a report about a user or project for admins to review
+func (s *ModerationService) ReportContent(ctx context.Context, reporterID string, input model.ReportContentInput) (*model.ModerationReport, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required")
+	}
+
+	var err error
+	switch input.TargetType {
+	case model.ModerationTargetTypeUser:
+		_, err = s.Store.Users().GetByID(ctx, input.TargetID)
+	case model.ModerationTargetTypeProject:
+		_, err = s.Store.Projects().GetByID(ctx, input.TargetID)
+	default:
+		return nil, fmt.Errorf("unsupported report target %s", input.TargetType)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%s not found", strings.ToLower(string(input.TargetType)))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up report target: %w", err)
+	}
+
+	report := &model.ModerationReport{
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Reason:     reason,
+	}
+	if err := s.Store.ModerationReports().Create(ctx, report, reporterID); err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(ctx, report.ID)
+}
+
+// ListReports returns moderation reports, newest first
+func (s *ModerationService) ListReports(ctx context.Context, status *model.ModerationReportStatus, limit, offset int) ([]*model.ModerationReport, error) {
+	reports, err := s.Store.ModerationReports().List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadReportUsers(ctx, reports...); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetReport returns a single moderation report
+func (s *ModerationService) GetReport(ctx context.Context, id string) (*model.ModerationReport, error) {
+	report, err := s.Store.ModerationReports().GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrModerationReportNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get moderation report: %w", err)
+	}
+
+	if err := s.loadReportUsers(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ResolveReport closes a report as resolved or dismissed
+func (s *ModerationService) ResolveReport(ctx context.Context, adminID, id string, status model.ModerationReportStatus, note *string) (*model.ModerationReport, error) {
+	if status == model.ModerationReportStatusOpen {
+		return nil, errors.New("a report can only be resolved or dismissed")
+	}
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		err := tx.ModerationReports().Resolve(ctx, id, adminID, status, note)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrModerationReportNotFound
+		} else if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionModerationResolve,
+			ActorID:    adminID,
+			TargetType: audit.TargetModerationReport,
+			TargetID:   id,
+			After:      map[string]interface{}{"status": status, "note": note},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReport(ctx, id)
+}
+
+// loadReportUsers replaces the reporters and resolving admins, which the
+// store only fills in with their IDs, with the full users. The users of all
+// reports are fetched at once.
+func (s *ModerationService) loadReportUsers(ctx context.Context, reports ...*model.ModerationReport) error {
+	var ids []string
+	for _, report := range reports {
+		ids = append(ids, report.Reporter.ID)
+		if report.ResolvedBy != nil {
+			ids = append(ids, report.ResolvedBy.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	users, err := s.UserService.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to fetch report users: %w", err)
+	}
+	byID := make(map[string]*model.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	for _, report := range reports {
+		reporter, ok := byID[report.Reporter.ID]
+		if !ok {
+			return errors.New("failed to fetch reporter: user not found")
+		}
+		report.Reporter = reporter
+		if report.ResolvedBy != nil {
+			resolvedBy, ok := byID[report.ResolvedBy.ID]
+			if !ok {
+				return errors.New("failed to fetch resolving admin: user not found")
+			}
+			report.ResolvedBy = resolvedBy
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/memory"
+)
+
+func TestModerationActionsRecordTheAdmin(t *testing.T) {
+	env := newTestEnv(t)
+	moderation := NewModerationService(env.store, env.users, env.projects)
+	// The context carries no user, so the actor can only come from adminID
+	ctx := context.Background()
+	admin := env.createUser(t, "admin")
+	owner := env.createUser(t, "owner")
+	other := env.createUser(t, "other")
+	project := env.createProject(t, owner.ID, "Moderated")
+
+	steps := []struct {
+		action string
+		run    func() error
+	}{
+		{audit.ActionUserSuspend, func() error { _, err := moderation.SuspendUser(ctx, admin.ID, other.ID, "spam"); return err }},
+		{audit.ActionUserUnsuspend, func() error { _, err := moderation.UnsuspendUser(ctx, admin.ID, other.ID); return err }},
+		{audit.ActionProjectHide, func() error { _, err := moderation.HideProject(ctx, admin.ID, project.ID, "spam"); return err }},
+		{audit.ActionProjectUnhide, func() error { _, err := moderation.UnhideProject(ctx, admin.ID, project.ID); return err }},
+		{audit.ActionProjectTransfer, func() error {
+			_, err := moderation.TransferProjectOwnership(ctx, admin.ID, project.ID, other.ID)
+			return err
+		}},
+		{audit.ActionProjectDelete, func() error { return moderation.ForceDeleteProject(ctx, admin.ID, project.ID) }},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.action, err)
+		}
+		events := env.store.AuditEvents()
+		last := events[len(events)-1]
+		if last.Action != step.action || last.ActorID != admin.ID {
+			t.Errorf("recorded %s by %q, want %s by the admin", last.Action, last.ActorID, step.action)
+		}
+	}
+}
+
+// userLookups counts how users are fetched
+type userLookups struct {
+	repository.UserRepository
+	getByID, listByIDs int
+}
+
+func (u *userLookups) GetByID(ctx context.Context, id string) (*model.User, error) {
+	u.getByID++
+	return u.UserRepository.GetByID(ctx, id)
+}
+
+func (u *userLookups) ListByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	u.listByIDs++
+	return u.UserRepository.ListByIDs(ctx, ids)
+}
+
+type countingUserStore struct {
+	*memory.Store
+	users *userLookups
+}
+
+func (s countingUserStore) Users() repository.UserRepository { return s.users }
+
+func TestListReportsFetchesUsersOnce(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	admin := env.createUser(t, "admin")
+	target := env.createUser(t, "target")
+
+	lookups := &userLookups{UserRepository: env.store.Users()}
+	store := countingUserStore{env.store, lookups}
+	moderation := NewModerationService(store, NewUserService(store, env.mailer, "http://app.test/"), env.projects)
+
+	var reporters []string
+	for _, name := range []string{"ada", "grace", "linus"} {
+		reporter := env.createUser(t, name)
+		reporters = append(reporters, reporter.ID)
+		report, err := moderation.ReportContent(ctx, reporter.ID, model.ReportContentInput{
+			TargetType: model.ModerationTargetTypeUser, TargetID: target.ID, Reason: "spam",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "ada" {
+			if _, err := moderation.ResolveReport(ctx, admin.ID, report.ID, model.ModerationReportStatusDismissed, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	*lookups = userLookups{UserRepository: lookups.UserRepository}
+	reports, err := moderation.ListReports(ctx, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookups.getByID != 0 || lookups.listByIDs != 1 {
+		t.Errorf("fetched users with %d GetByID and %d ListByIDs calls, want one ListByIDs", lookups.getByID, lookups.listByIDs)
+	}
+
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports, want 3", len(reports))
+	}
+	for i, report := range reports {
+		// Newest first
+		if want := reporters[len(reporters)-1-i]; report.Reporter.ID != want || report.Reporter.Username == "" {
+			t.Errorf("report %d reporter = %+v, want user %s", i, report.Reporter, want)
+		}
+	}
+	if resolved := reports[2].ResolvedBy; resolved == nil || resolved.Username != "admin" {
+		t.Errorf("resolved by = %+v, want the admin", resolved)
+	}
+}
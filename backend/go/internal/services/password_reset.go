@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordResetTTL is how long a password reset link stays valid
+const PasswordResetTTL = time.Hour
+
+// Reset requests are limited per email address and per client IP within
+// passwordResetWindow
+const (
+	passwordResetsPerEmail = 3
+	passwordResetsPerIP    = 20
+	passwordResetWindow    = time.Hour
+
+	throttleScopeResetEmail = "reset_email"
+	throttleScopeResetIP    = "reset_ip"
+)
+
+// passwordResetSendTimeout bounds the background work of one reset request
+const passwordResetSendTimeout = 30 * time.Second
+
+var (
+	ErrInvalidResetToken    = errors.New("invalid or expired password reset token")
+	ErrTooManyResetRequests = errors.New("too many password reset requests; try again later")
+)
+
+// RequestPasswordReset emails a single-use reset link to the account with the
+// given email. The account is looked up and the email sent in the background,
+// so neither the response time nor a mail failure reveals whether the address
+// is registered.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	if err := s.throttlePasswordReset(ctx, email, auth.GetClientIPFromContext(ctx)); err != nil {
+		return err
+	}
+
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordResetSendTimeout)
+		defer cancel()
+
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			slog.ErrorContext(ctx, "Failed to send password reset email", "error", err)
+		}
+	}()
+	return nil
+}
+
+// throttlePasswordReset counts a reset request against the email address and
+// the client IP and refuses it once either is over its limit
+func (s *UserService) throttlePasswordReset(ctx context.Context, email, ip string) error {
+	limits := map[repository.ThrottleKey]int{
+		{Scope: throttleScopeResetEmail, Key: normalizeEmail(email)}: passwordResetsPerEmail,
+	}
+	if ip != "" {
+		limits[repository.ThrottleKey{Scope: throttleScopeResetIP, Key: ip}] = passwordResetsPerIP
+	}
+
+	now := time.Now().UTC()
+	throttled := false
+	for key, limit := range limits {
+		requests, err := s.Store.LoginThrottle().RecordFailure(ctx, key, now, now.Add(-passwordResetWindow))
+		if err != nil {
+			return fmt.Errorf("failed to count password reset request: %w", err)
+		}
+		if requests > limit {
+			throttled = true
+		}
+	}
+	if throttled {
+		return ErrTooManyResetRequests
+	}
+	return nil
+}
+
+func (s *UserService) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.GetUserByEmail(ctx, email)
+	if err != nil {
+		slog.InfoContext(ctx, "Password reset requested for unknown email")
+		return nil
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	// Storing the token also invalidates any earlier link
+	err = s.Store.WithTx(ctx, func(tx repository.Store) error {
+		return tx.PasswordResets().Create(ctx, user.ID, tokenHash, time.Now().UTC().Add(PasswordResetTTL))
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.AppURL + "/reset-password?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Someone asked to reset the password for your Projectivity account. Open the link below to choose a new one:\n\n"+
+		"%s\n\n"+
+		"The link expires in %d minutes and can only be used once. If you did not ask for a reset, you can ignore this email.\n",
+		user.FirstName, link, int(PasswordResetTTL.Minutes()))
+
+	err = s.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Projectivity password",
+		Body:    body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out everywhere
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	newPasswordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash new password: %w", err)
+	}
+
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
+		userID, err := tx.PasswordResets().Consume(ctx, auth.HashToken(token))
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		} else if err != nil {
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+
+		if err := tx.Users().SetPasswordHash(ctx, userID, newPasswordHash); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		// The old password can no longer be trusted, so neither can sessions
+		// that were started with it
+		if err := tx.Sessions().RevokeAll(ctx, userID, ""); err != nil {
+			return fmt.Errorf("failed to revoke existing sessions: %w", err)
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionPasswordReset,
+			ActorID:    userID,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+		})
+	})
+}
+
+func validatePassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters long")
+	}
+	return nil
+}
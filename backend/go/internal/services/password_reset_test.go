@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func TestResetPassword(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.createUser(t, "ada")
+
+	login, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.users.RequestPasswordReset(ctx, "ada@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	env.users.background.Wait()
+	sent := env.mailer.sent()
+	if len(sent) != 2 || sent[1].To != "ada@example.com" {
+		t.Fatalf("sent = %+v, want a reset email after the verification email", sent)
+	}
+	token := verificationToken(t, sent[1].Body)
+
+	if err := env.users.ResetPassword(ctx, token, "short"); err == nil {
+		t.Error("ResetPassword accepted a short password")
+	}
+	if err := env.users.ResetPassword(ctx, token, "a brand new password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The link only works once
+	if err := env.users.ResetPassword(ctx, token, "yet another password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("reused token: err = %v, want %v", err, ErrInvalidResetToken)
+	}
+
+	// Sessions started with the old password are signed out
+	active, err := auth.IsSessionActive(ctx, login.Tokens.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Error("session from before the reset is still active")
+	}
+
+	if _, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple"); err == nil {
+		t.Error("old password still works")
+	}
+	if _, err := env.users.LoginUser(ctx, "ada@example.com", "a brand new password"); err != nil {
+		t.Errorf("LoginUser with the new password: %v", err)
+	}
+
+	if !env.hasAudit(audit.ActionPasswordReset) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionPasswordReset)
+	}
+}
+
+func TestRequestPasswordResetInvalidatesEarlierLinks(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.createUser(t, "ada")
+
+	for i := 0; i < 2; i++ {
+		if err := env.users.RequestPasswordReset(ctx, "ada@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env.users.background.Wait()
+	sent := env.mailer.sent()
+	first, second := verificationToken(t, sent[1].Body), verificationToken(t, sent[2].Body)
+
+	if err := env.users.ResetPassword(ctx, first, "a brand new password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("earlier link: err = %v, want %v", err, ErrInvalidResetToken)
+	}
+	if err := env.users.ResetPassword(ctx, second, "a brand new password"); err != nil {
+		t.Errorf("latest link: %v", err)
+	}
+
+	// Unknown addresses get no email but no error either
+	if err := env.users.RequestPasswordReset(ctx, "nobody@example.com"); err != nil {
+		t.Errorf("RequestPasswordReset for an unknown email: %v", err)
+	}
+	env.users.background.Wait()
+	if n := len(env.mailer.sent()); n != 3 {
+		t.Errorf("sent %d emails, want 3", n)
+	}
+}
+
+func TestRequestPasswordResetHidesMailFailures(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.createUser(t, "ada")
+	env.mailer.fail(errors.New("smtp: connection refused"))
+
+	// A cancelled request must not stop the email either
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := env.users.RequestPasswordReset(cancelled, "ada@example.com"); err != nil {
+		t.Errorf("RequestPasswordReset with a failing mailer: %v", err)
+	}
+	env.users.background.Wait()
+
+	env.mailer.fail(nil)
+	if err := env.users.RequestPasswordReset(cancelled, "ada@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	env.users.background.Wait()
+	if n := len(env.mailer.sent()); n != 2 {
+		t.Errorf("sent %d emails, want the verification and one reset email", n)
+	}
+}
+
+func TestRequestPasswordResetRateLimit(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := auth.SetClientIP(context.Background(), "203.0.113.7")
+
+	for i := 0; i < passwordResetsPerEmail; i++ {
+		if err := env.users.RequestPasswordReset(ctx, "ada@example.com"); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	// The limit is the same whether or not the address has an account
+	if err := env.users.RequestPasswordReset(ctx, "Ada@Example.com"); !errors.Is(err, ErrTooManyResetRequests) {
+		t.Errorf("request over the email limit: err = %v, want %v", err, ErrTooManyResetRequests)
+	}
+
+	// One client cycling through addresses hits the IP limit
+	var err error
+	for i := 0; i < passwordResetsPerIP && err == nil; i++ {
+		err = env.users.RequestPasswordReset(ctx, fmt.Sprintf("user%d@example.com", i))
+	}
+	if !errors.Is(err, ErrTooManyResetRequests) {
+		t.Errorf("requests over the IP limit: err = %v, want %v", err, ErrTooManyResetRequests)
+	}
+
+	other := auth.SetClientIP(context.Background(), "198.51.100.1")
+	if err := env.users.RequestPasswordReset(other, "grace@example.com"); err != nil {
+		t.Errorf("request from another IP: %v", err)
+	}
+	env.users.background.Wait()
+}
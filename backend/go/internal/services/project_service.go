@@ -2,24 +2,28 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
-	"github.com/evan3v4n/Projectivity/backend/go/internal/database"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 type ProjectService struct {
-	DB          *sql.DB
+	Store       repository.Store
 	UserService *UserService
 }
 
-func NewProjectService(db *sql.DB, userService *UserService) *ProjectService {
+func NewProjectService(store repository.Store, userService *UserService) *ProjectService {
 	return &ProjectService{
-		DB:          db,
+		Store:       store,
 		UserService: userService,
 	}
 }
@@ -42,42 +46,32 @@ func (s *ProjectService) CreateProject(ctx context.Context, input model.CreatePr
 		UpdatedAt:          time.Now().Format(time.RFC3339),
 	}
 
-	err := database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Save the project to the database
-		query := `INSERT INTO projects (id, title, description, category, status, technologies, open_positions, time_commitment, learning_objectives, popularity, created_at, updated_at)
-				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
-		_, err := tx.ExecContext(ctx, query,
-			project.ID, project.Title, project.Description, project.Category, project.Status,
-			pq.Array(project.Technologies), project.OpenPositions, project.TimeCommitment,
-			pq.Array(project.LearningObjectives), project.Popularity, project.CreatedAt, project.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to insert project: %w", err)
-		}
-
-		// Set the owner
-		ownerQuery := `INSERT INTO project_owners (project_id, user_id) VALUES ($1, $2)`
-		_, err = tx.ExecContext(ctx, ownerQuery, project.ID, ownerID)
-		if err != nil {
-			return fmt.Errorf("failed to set project owner: %w", err)
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		// Save the project and its owner
+		if err := tx.Projects().Create(ctx, project, ownerID); err != nil {
+			return err
 		}
 
 		// Create a default team for the project
-		teamQuery := `INSERT INTO teams (id, name, description, project_id, created_at, updated_at)
-					  VALUES ($1, $2, $3, $4, $5, $6)`
-		teamID := uuid.New().String()
-		teamName := project.Title + " Team"
-		teamDescription := "Default team for " + project.Title
-		_, err = tx.ExecContext(ctx, teamQuery,
-			teamID, teamName, teamDescription, project.ID, time.Now(), time.Now())
-		if err != nil {
+		description := "Default team for " + project.Title
+		team := &model.Team{
+			ID:          uuid.New().String(),
+			Name:        project.Title + " Team",
+			Description: &description,
+			CreatedAt:   time.Now().Format(time.RFC3339),
+			UpdatedAt:   time.Now().Format(time.RFC3339),
+		}
+		if err := tx.Teams().Create(ctx, team, project.ID); err != nil {
 			return fmt.Errorf("failed to create default team: %w", err)
 		}
 
 		// Add the owner as a team member
-		teamMemberQuery := `INSERT INTO team_members (user_id, team_id, role, joined_at)
-							VALUES ($1, $2, $3, $4)`
-		_, err = tx.ExecContext(ctx, teamMemberQuery,
-			ownerID, teamID, "Owner", time.Now())
+		err := tx.Teams().AddMember(ctx, team.ID, &model.TeamMember{
+			ID:       uuid.New().String(),
+			User:     &model.User{ID: ownerID},
+			Role:     "Owner",
+			JoinedAt: time.Now().Format(time.RFC3339),
+		})
 		if err != nil {
 			return fmt.Errorf("failed to add owner as team member: %w", err)
 		}
@@ -88,6 +82,7 @@ func (s *ProjectService) CreateProject(ctx context.Context, input model.CreatePr
 	if err != nil {
 		return nil, err
 	}
+	metrics.ProjectsCreated.Inc()
 
 	// Fetch the owner details
 	owner, err := s.UserService.GetUserByID(ctx, ownerID)
@@ -100,46 +95,13 @@ func (s *ProjectService) CreateProject(ctx context.Context, input model.CreatePr
 }
 
 func (s *ProjectService) GetProjectByID(ctx context.Context, id string) (*model.Project, error) {
-	query := `
-		SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, 
-			   p.open_positions, p.time_commitment, p.popularity, p.timeline, 
-			   p.learning_objectives, p.created_at, p.updated_at,
-			   u.id, u.username
-		FROM projects p
-		JOIN project_owners po ON p.id = po.project_id
-		JOIN users u ON po.user_id = u.id
-		WHERE p.id = $1
-	`
-
-	project := &model.Project{}
-	owner := &model.User{}
-
-	var technologies, learningObjectives []string
-	var timeline sql.NullString
-
-	err := database.QueryRow(ctx, query, id).Scan(
-		&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-		pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
-		&project.Popularity, &timeline, pq.Array(&learningObjectives),
-		&project.CreatedAt, &project.UpdatedAt,
-		&owner.ID, &owner.Username,
-	)
-
+	project, err := s.Store.Projects().GetByID(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, fmt.Errorf("project not found")
 		}
 		return nil, fmt.Errorf("error fetching project: %w", err)
 	}
-
-	project.Technologies = technologies
-	project.LearningObjectives = learningObjectives
-	project.Owner = owner
-
-	if timeline.Valid {
-		project.Timeline = &timeline.String
-	}
-
 	return project, nil
 }
 
@@ -150,6 +112,8 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id string, input mod
 		return nil, fmt.Errorf("failed to retrieve project: %w", err)
 	}
 
+	before := projectSnapshot(project)
+
 	// Update fields if provided in input
 	if input.Title != nil {
 		project.Title = *input.Title
@@ -175,24 +139,19 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id string, input mod
 
 	project.UpdatedAt = time.Now().Format(time.RFC3339)
 
-	// Update the project in the database
-	err = database.Transaction(ctx, func(tx *sql.Tx) error {
-		query := `
-			UPDATE projects
-			SET title = $1, description = $2, category = $3, status = $4, 
-				open_positions = $5, time_commitment = $6, 
-				learning_objectives = $7, updated_at = $8
-			WHERE id = $9
-		`
-		_, err := tx.ExecContext(ctx, query,
-			project.Title, project.Description, project.Category, project.Status,
-			project.OpenPositions, project.TimeCommitment,
-			pq.Array(project.LearningObjectives), project.UpdatedAt, project.ID)
-
-		if err != nil {
+	err = s.Store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Projects().Update(ctx, project); err != nil {
 			return fmt.Errorf("failed to update project: %w", err)
 		}
-		return nil
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionProjectUpdate,
+			TargetType: audit.TargetProject,
+			TargetID:   project.ID,
+			ProjectID:  project.ID,
+			Before:     before,
+			After:      projectSnapshot(project),
+		})
 	})
 
 	if err != nil {
@@ -203,443 +162,247 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id string, input mod
 }
 
 func (s *ProjectService) DeleteProject(ctx context.Context, id string) error {
-	return database.Transaction(ctx, func(tx *sql.Tx) error {
-		// 1. Delete join requests for the project
-		_, err := tx.ExecContext(ctx, `DELETE FROM join_requests WHERE project_id = $1`, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete join requests: %w", err)
-		}
-
-		// 2. Delete team members
-		_, err = tx.ExecContext(ctx, `
-			DELETE FROM team_members 
-			WHERE team_id IN (SELECT id FROM teams WHERE project_id = $1)
-		`, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete team members: %w", err)
-		}
+	return s.deleteProject(ctx, "", id)
+}
 
-		// 3. Delete teams
-		_, err = tx.ExecContext(ctx, `DELETE FROM teams WHERE project_id = $1`, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete teams: %w", err)
-		}
+// deleteProject deletes a project on behalf of actorID, or of the caller when
+// actorID is empty
+func (s *ProjectService) deleteProject(ctx context.Context, actorID, id string) error {
+	// Keep a copy of what was deleted in the audit log
+	var before map[string]interface{}
+	if project, err := s.GetProjectByID(ctx, id); err == nil {
+		before = projectSnapshot(project)
+		before["ownerId"] = project.Owner.ID
+	}
+
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Projects().Delete(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("project not found")
+			}
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionProjectDelete,
+			ActorID:    actorID,
+			TargetType: audit.TargetProject,
+			TargetID:   id,
+			ProjectID:  id,
+			Before:     before,
+		})
+	})
+}
 
-		// 4. Delete project owners
-		_, err = tx.ExecContext(ctx, `DELETE FROM project_owners WHERE project_id = $1`, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete project owners: %w", err)
+// validateProjectFilter rejects filters that cannot match anything or would
+// fail in the database
+func validateProjectFilter(filter repository.ProjectFilter) error {
+	if filter.MinOpenPositions != nil && filter.MaxOpenPositions != nil && *filter.MinOpenPositions > *filter.MaxOpenPositions {
+		return errors.New("minOpenPositions cannot be greater than maxOpenPositions")
+	}
+	if filter.CreatedAfter != nil {
+		if _, err := time.Parse(time.RFC3339, *filter.CreatedAfter); err != nil {
+			return errors.New("createdAfter must be an RFC 3339 timestamp")
 		}
-
-		// 5. Finally, delete the project
-		result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = $1`, id)
-		if err != nil {
-			return fmt.Errorf("failed to delete project: %w", err)
+	}
+	if filter.CreatedBefore != nil {
+		if _, err := time.Parse(time.RFC3339, *filter.CreatedBefore); err != nil {
+			return errors.New("createdBefore must be an RFC 3339 timestamp")
 		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if filter.OwnerID != nil {
+		if _, err := uuid.Parse(*filter.OwnerID); err != nil {
+			return errors.New("invalid ownerId")
 		}
+	}
+	return nil
+}
 
-		if rowsAffected == 0 {
-			return fmt.Errorf("project not found")
-		}
+func (s *ProjectService) ListProjects(ctx context.Context, filter repository.ProjectFilter, sort model.ProjectSort, limit, offset int) ([]*model.Project, error) {
+	if err := validateProjectFilter(filter); err != nil {
+		return nil, err
+	}
 
-		return nil
-	})
+	projects, err := s.Store.Projects().List(ctx, filter, sort, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	return projects, nil
 }
 
-func (s *ProjectService) ListProjects(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*model.Project, error) {
-	query := `
-		SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, 
-			p.open_positions, p.time_commitment, p.learning_objectives, p.popularity, 
-			p.timeline, p.created_at, p.updated_at,
-			u.id, u.username, u.email
-		FROM projects p
-		JOIN project_owners po ON p.id = po.project_id
-		JOIN users u ON po.user_id = u.id
-		WHERE 1=1
-	`
-
-	var args []interface{}
-	argIndex := 1
-
-	// Add filters to the query
-	for key, value := range filters {
-		query += fmt.Sprintf(" AND p.%s = $%d", key, argIndex)
-		args = append(args, value)
-		argIndex++
-	}
-
-	// Add ORDER BY, LIMIT, and OFFSET
-	query += fmt.Sprintf(" ORDER BY p.created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	args = append(args, limit, offset)
-
-	rows, err := database.Query(ctx, query, args...)
+// ListProjectsConnection returns a page of the projects matching the
+// filter. The total is only counted when withTotal is set.
+func (s *ProjectService) ListProjectsConnection(ctx context.Context, filter repository.ProjectFilter, args pagination.Args, withTotal bool) (*model.ProjectConnection, error) {
+	if err := validateProjectFilter(filter); err != nil {
+		return nil, err
+	}
+
+	page, err := pagination.Fetch(args, func(p repository.Page) ([]*model.Project, error) {
+		return s.Store.Projects().ListPage(ctx, filter, p)
+	}, func(p *model.Project) repository.Cursor {
+		return repository.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query projects: %w", err)
 	}
-	defer rows.Close()
-
-	var projects []*model.Project
-	for rows.Next() {
-		project := &model.Project{}
-		owner := &model.User{}
-		var technologies, learningObjectives []string
-		var timeline sql.NullString
-
-		err := rows.Scan(
-			&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-			pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
-			pq.Array(&learningObjectives), &project.Popularity, &timeline,
-			&project.CreatedAt, &project.UpdatedAt,
-			&owner.ID, &owner.Username, &owner.Email,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan project row: %w", err)
-		}
-
-		project.Technologies = technologies
-		project.LearningObjectives = learningObjectives
-		project.Owner = owner
 
-		if timeline.Valid {
-			project.Timeline = &timeline.String
+	conn := &model.ProjectConnection{Edges: []*model.ProjectEdge{}, PageInfo: page.PageInfo}
+	for i, project := range page.Items {
+		conn.Edges = append(conn.Edges, &model.ProjectEdge{Cursor: page.Cursors[i], Node: project})
+	}
+	if withTotal {
+		if conn.TotalCount, err = s.Store.Projects().Count(ctx, filter); err != nil {
+			return nil, err
 		}
+	}
+	return conn, nil
+}
 
-		projects = append(projects, project)
+// ProjectFacets counts the projects matching the filter per category,
+// technology, status and time commitment
+func (s *ProjectService) ProjectFacets(ctx context.Context, filter repository.ProjectFilter) (*model.ProjectFacets, error) {
+	if err := validateProjectFilter(filter); err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating project rows: %w", err)
+	facets, err := s.Store.Projects().Facets(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count project facets: %w", err)
 	}
+	return facets, nil
+}
 
-	return projects, nil
+// defaultTeam returns the team that members joining a project are added to
+func defaultTeam(ctx context.Context, store repository.Store, projectID string) (*model.Team, error) {
+	teams, err := store.Teams().ByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching team for project: %w", err)
+	}
+	if len(teams) == 0 {
+		return nil, fmt.Errorf("error fetching team for project: project %s has no team", projectID)
+	}
+	return teams[0], nil
 }
 
 func (s *ProjectService) AddTeamMember(ctx context.Context, projectID, userID string, role string) error {
-	return database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Get the team ID for the project
-		teamQuery := "SELECT id FROM teams WHERE project_id = $1"
-		var teamID string
-		err := tx.QueryRowContext(ctx, teamQuery, projectID).Scan(&teamID)
-		if err != nil {
-			return fmt.Errorf("error fetching team for project: %w", err)
-		}
-
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
 		// Check if the project exists
-		projectExistsQuery := "SELECT id FROM projects WHERE id = $1"
-		var projectIDCheck string
-		err = tx.QueryRowContext(ctx, projectExistsQuery, projectID).Scan(&projectIDCheck)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("project with ID %s does not exist", projectID)
-		} else if err != nil {
+		if _, err := tx.Projects().GetByID(ctx, projectID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("project with ID %s does not exist", projectID)
+			}
 			return fmt.Errorf("error checking project existence: %w", err)
 		}
 
 		// Check if the user exists
-		userExistsQuery := "SELECT id FROM users WHERE id = $1"
-		var userIDCheck string
-		err = tx.QueryRowContext(ctx, userExistsQuery, userID).Scan(&userIDCheck)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("user with ID %s does not exist", userID)
-		} else if err != nil {
+		if _, err := tx.Users().GetByID(ctx, userID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("user with ID %s does not exist", userID)
+			}
 			return fmt.Errorf("error checking user existence: %w", err)
 		}
 
-		// Check if the user is already a team member
-		checkMemberQuery := "SELECT id FROM team_members WHERE team_id = $1 AND user_id = $2"
-		var memberID string
-		err = tx.QueryRowContext(ctx, checkMemberQuery, teamID, userID).Scan(&memberID)
-		if err == nil {
-			return fmt.Errorf("user is already a team member of this project")
-		} else if err != sql.ErrNoRows {
-			return fmt.Errorf("error checking existing team membership: %w", err)
+		team, err := defaultTeam(ctx, tx, projectID)
+		if err != nil {
+			return err
 		}
 
-		// Add the new team member
-		insertQuery := `
-			INSERT INTO team_members (team_id, user_id, role, joined_at)
-			VALUES ($1, $2, $3, $4)
-		`
-		_, err = tx.ExecContext(ctx, insertQuery, teamID, userID, role, time.Now().UTC())
+		// Check if the user is already a team member
+		isMember, err := tx.Teams().IsMember(ctx, team.ID, userID)
 		if err != nil {
-			return fmt.Errorf("failed to add team member: %w", err)
+			return err
+		}
+		if isMember {
+			return fmt.Errorf("user is already a team member of this project")
 		}
 
-		return nil
+		return tx.Teams().AddMember(ctx, team.ID, &model.TeamMember{
+			ID:       uuid.New().String(),
+			User:     &model.User{ID: userID},
+			Role:     role,
+			JoinedAt: time.Now().UTC().Format(time.RFC3339),
+		})
 	})
 }
 
 func (s *ProjectService) RemoveTeamMember(ctx context.Context, projectID, userID string) error {
-	return database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Get the team ID for the project
-		teamQuery := "SELECT id FROM teams WHERE project_id = $1"
-		var teamID string
-		err := tx.QueryRowContext(ctx, teamQuery, projectID).Scan(&teamID)
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
+		team, err := defaultTeam(ctx, tx, projectID)
 		if err != nil {
-			return fmt.Errorf("error fetching team for project: %w", err)
+			return err
 		}
 
-		// Check if the team member exists
-		checkMemberQuery := `
-			SELECT id FROM team_members 
-			WHERE team_id = $1 AND user_id = $2
-		`
-		var memberID string
-		err = tx.QueryRowContext(ctx, checkMemberQuery, teamID, userID).Scan(&memberID)
-		if err == sql.ErrNoRows {
+		err = tx.Teams().RemoveMember(ctx, team.ID, userID)
+		if errors.Is(err, repository.ErrNotFound) {
 			return fmt.Errorf("user is not a team member of this project")
-		} else if err != nil {
-			return fmt.Errorf("error checking team membership: %w", err)
-		}
-
-		// Remove the team member
-		deleteQuery := `
-			DELETE FROM team_members 
-			WHERE team_id = $1 AND user_id = $2
-		`
-		result, err := tx.ExecContext(ctx, deleteQuery, teamID, userID)
-		if err != nil {
-			return fmt.Errorf("failed to remove team member: %w", err)
-		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
 		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("no team member was removed")
-		}
-
-		return nil
+		return err
 	})
 }
 
 func (s *ProjectService) UpdateProjectStatus(ctx context.Context, projectID string, status model.ProjectStatus) error {
-	return database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Check if the project exists
-		checkProjectQuery := `SELECT id FROM projects WHERE id = $1`
-		var projectIDCheck string
-		err := tx.QueryRowContext(ctx, checkProjectQuery, projectID).Scan(&projectIDCheck)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("project with ID %s does not exist", projectID)
-		} else if err != nil {
-			return fmt.Errorf("error checking project existence: %w", err)
-		}
-
-		// Update the project status
-		updateQuery := `UPDATE projects SET status = $1, updated_at = $2 WHERE id = $3`
-		result, err := tx.ExecContext(ctx, updateQuery, status, time.Now().UTC(), projectID)
-		if err != nil {
-			return fmt.Errorf("failed to update project status: %w", err)
-		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
-		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("no project was updated")
-		}
-
-		return nil
-	})
+	err := s.Store.Projects().UpdateStatus(ctx, projectID, status)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("project with ID %s does not exist", projectID)
+	}
+	return err
 }
 
 func (s *ProjectService) GetProjectTeamMembers(ctx context.Context, projectID string) ([]*model.TeamMember, error) {
-	query := `
-		SELECT tm.id, tm.user_id, tm.role, tm.joined_at,
-			   u.username, u.email, u.first_name, u.last_name
-		FROM team_members tm
-		JOIN users u ON tm.user_id = u.id
-		JOIN teams t ON tm.team_id = t.id
-		WHERE t.project_id = $1
-	`
-
-	rows, err := database.Query(ctx, query, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query team members: %w", err)
-	}
-	defer rows.Close()
-
-	var teamMembers []*model.TeamMember
-	for rows.Next() {
-		tm := &model.TeamMember{
-			User: &model.User{},
-		}
-		err := rows.Scan(
-			&tm.ID, &tm.User.ID, &tm.Role, &tm.JoinedAt,
-			&tm.User.Username, &tm.User.Email, &tm.User.FirstName, &tm.User.LastName,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan team member row: %w", err)
-		}
-		teamMembers = append(teamMembers, tm)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating team member rows: %w", err)
-	}
+	return s.Store.Teams().ProjectMembers(ctx, projectID)
+}
 
-	return teamMembers, nil
+func (s *ProjectService) GetTeamMembersByProjects(ctx context.Context, projectIDs []string) (map[string][]*model.TeamMember, error) {
+	return s.Store.Teams().MembersByProjects(ctx, projectIDs)
 }
 
-func (s *ProjectService) SearchProjects(ctx context.Context, query string, limit, offset int) ([]*model.Project, error) {
-	searchQuery := `
-        SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, 
-               p.open_positions, p.time_commitment, p.learning_objectives, p.popularity, 
-               p.timeline, p.created_at, p.updated_at,
-               u.id AS owner_id, u.username AS owner_username, u.email AS owner_email
-        FROM projects p
-        JOIN project_owners po ON p.id = po.project_id
-        JOIN users u ON po.user_id = u.id
-        WHERE to_tsvector('english', p.title || ' ' || p.description || ' ' || p.category) @@ plainto_tsquery('english', $1)
-        ORDER BY ts_rank(to_tsvector('english', p.title || ' ' || p.description || ' ' || p.category), plainto_tsquery('english', $1)) DESC
-        LIMIT $2 OFFSET $3
-    `
-
-	rows, err := database.Query(ctx, searchQuery, query, limit, offset)
+func (s *ProjectService) SearchProjects(ctx context.Context, query string, limit, offset int) ([]*model.SearchHit, error) {
+	hits, err := s.Store.Projects().Search(ctx, strings.TrimSpace(query), limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute search query: %w", err)
 	}
-	defer rows.Close()
-
-	var projects []*model.Project
-	for rows.Next() {
-		project := &model.Project{
-			Owner: &model.User{},
-		}
-		var technologies, learningObjectives []string
-		var timeline sql.NullString
-
-		err := rows.Scan(
-			&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-			pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
-			pq.Array(&learningObjectives), &project.Popularity, &timeline,
-			&project.CreatedAt, &project.UpdatedAt,
-			&project.Owner.ID, &project.Owner.Username, &project.Owner.Email,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan project row: %w", err)
-		}
-
-		project.Technologies = technologies
-		project.LearningObjectives = learningObjectives
-
-		if timeline.Valid {
-			project.Timeline = &timeline.String
-		}
-
-		projects = append(projects, project)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating project rows: %w", err)
-	}
-
-	return projects, nil
+	return hits, nil
 }
 
 func (s *ProjectService) GetProjectsByOwner(ctx context.Context, ownerID string) ([]*model.Project, error) {
-	query := `
-        SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, 
-               p.open_positions, p.time_commitment, p.learning_objectives, p.popularity, 
-               p.timeline, p.created_at, p.updated_at
-        FROM projects p
-        JOIN project_owners po ON p.id = po.project_id
-        WHERE po.user_id = $1
-        ORDER BY p.created_at DESC
-    `
-
-	rows, err := database.Query(ctx, query, ownerID)
+	viewer, err := currentViewer(ctx, s.Store)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query projects: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
-
-	var projects []*model.Project
-	for rows.Next() {
-		project := &model.Project{}
-		var technologies, learningObjectives []string
-		var timeline sql.NullString
-
-		err := rows.Scan(
-			&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-			pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
-			pq.Array(&learningObjectives), &project.Popularity, &timeline,
-			&project.CreatedAt, &project.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan project row: %w", err)
-		}
-
-		project.Technologies = technologies
-		project.LearningObjectives = learningObjectives
-
-		// Set the owner (we already know the owner ID)
-		project.Owner = &model.User{ID: ownerID}
-
-		if timeline.Valid {
-			project.Timeline = &timeline.String
-		}
+	return s.Store.Projects().ListByOwner(ctx, ownerID, viewer)
+}
 
-		projects = append(projects, project)
+func (s *ProjectService) GetProjectsByOwners(ctx context.Context, ownerIDs []string) (map[string][]*model.Project, error) {
+	viewer, err := currentViewer(ctx, s.Store)
+	if err != nil {
+		return nil, err
 	}
+	return s.Store.Projects().ListByOwners(ctx, ownerIDs, viewer)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating project rows: %w", err)
+// currentViewer identifies the caller for listings that leave out hidden
+// projects. Anonymous callers get the zero Viewer.
+func currentViewer(ctx context.Context, store repository.Store) (repository.Viewer, error) {
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return repository.Viewer{}, nil
 	}
 
-	// If no projects found, return an empty slice instead of nil
-	if len(projects) == 0 {
-		return []*model.Project{}, nil
+	role, err := store.Users().Role(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.Viewer{UserID: userID}, nil
+	} else if err != nil {
+		return repository.Viewer{}, fmt.Errorf("failed to look up user role: %w", err)
 	}
-
-	return projects, nil
+	return repository.Viewer{UserID: userID, Admin: role == model.UserRoleAdmin}, nil
 }
 
 func (s *ProjectService) UpdateProjectPopularity(ctx context.Context, projectID string, popularityChange int) error {
-	return database.Transaction(ctx, func(tx *sql.Tx) error {
-		// First, check if the project exists
-		checkProjectQuery := `SELECT popularity FROM projects WHERE id = $1`
-		var currentPopularity int
-		err := tx.QueryRowContext(ctx, checkProjectQuery, projectID).Scan(&currentPopularity)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("project with ID %s does not exist", projectID)
-		} else if err != nil {
-			return fmt.Errorf("error checking project existence: %w", err)
-		}
-
-		// Calculate new popularity
-		newPopularity := currentPopularity + popularityChange
-
-		// Ensure popularity doesn't go below 0
-		if newPopularity < 0 {
-			newPopularity = 0
-		}
-
-		// Update the project popularity
-		updateQuery := `UPDATE projects SET popularity = $1, updated_at = $2 WHERE id = $3`
-		result, err := tx.ExecContext(ctx, updateQuery, newPopularity, time.Now().UTC(), projectID)
-		if err != nil {
-			return fmt.Errorf("failed to update project popularity: %w", err)
-		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
-		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("no project was updated")
-		}
-
-		return nil
-	})
+	err := s.Store.Projects().AdjustPopularity(ctx, projectID, popularityChange)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("project with ID %s does not exist", projectID)
+	}
+	return err
 }
 
 func (s *ProjectService) GetRelatedProjects(ctx context.Context, projectID string, limit int) ([]*model.Project, error) {
@@ -649,161 +412,39 @@ func (s *ProjectService) GetRelatedProjects(ctx context.Context, projectID strin
 		return nil, fmt.Errorf("failed to get current project: %w", err)
 	}
 
-	query := `
-		SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, 
-			   p.open_positions, p.time_commitment, p.learning_objectives, p.popularity, 
-			   p.timeline, p.created_at, p.updated_at,
-			   u.id AS owner_id, u.username AS owner_username, u.email AS owner_email,
-			   COUNT(DISTINCT t) AS matching_technologies
-		FROM projects p
-		JOIN project_owners po ON p.id = po.project_id
-		JOIN users u ON po.user_id = u.id
-		LEFT JOIN LATERAL unnest(p.technologies) t ON TRUE
-		WHERE p.id != $1
-		  AND (p.category = $2 OR t = ANY($3))
-		GROUP BY p.id, u.id
-		ORDER BY matching_technologies DESC, p.popularity DESC
-		LIMIT $4
-	`
-
-	rows, err := database.Query(ctx, query, projectID, currentProject.Category, pq.Array(currentProject.Technologies), limit)
+	related, err := s.Store.Projects().Related(ctx, currentProject, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query related projects: %w", err)
 	}
-	defer rows.Close()
-
-	var relatedProjects []*model.Project
-	for rows.Next() {
-		project := &model.Project{
-			Owner: &model.User{},
-		}
-		var technologies, learningObjectives []string
-		var timeline sql.NullString
-		var matchingTechnologies int
-
-		err := rows.Scan(
-			&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-			pq.Array(&technologies), &project.OpenPositions, &project.TimeCommitment,
-			pq.Array(&learningObjectives), &project.Popularity, &timeline,
-			&project.CreatedAt, &project.UpdatedAt,
-			&project.Owner.ID, &project.Owner.Username, &project.Owner.Email,
-			&matchingTechnologies,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan related project row: %w", err)
-		}
-
-		project.Technologies = technologies
-		project.LearningObjectives = learningObjectives
-
-		if timeline.Valid {
-			project.Timeline = &timeline.String
-		}
-
-		relatedProjects = append(relatedProjects, project)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating related project rows: %w", err)
-	}
-
-	return relatedProjects, nil
+	return related, nil
 }
 
 func (s *ProjectService) AddTechnology(ctx context.Context, projectID string, technology string) (*model.Project, error) {
-	err := database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Check if the project exists
-		checkProjectQuery := `SELECT id FROM projects WHERE id = $1`
-		var projectIDCheck string
-		err := tx.QueryRowContext(ctx, checkProjectQuery, projectID).Scan(&projectIDCheck)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("project with ID %s does not exist", projectID)
-		} else if err != nil {
-			return fmt.Errorf("error checking project existence: %w", err)
-		}
-
-		// Add the technology to the array
-		updateQuery := `
-			UPDATE projects 
-			SET technologies = array_append(technologies, $1),
-				updated_at = $2
-			WHERE id = $3 AND NOT ($1 = ANY(technologies))
-		`
-		result, err := tx.ExecContext(ctx, updateQuery, technology, time.Now().UTC(), projectID)
-		if err != nil {
-			return fmt.Errorf("failed to add technology: %w", err)
-		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
-		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("technology already exists or project not found")
-		}
-
-		return nil
-	})
-
+	added, err := s.Store.Projects().AddTechnology(ctx, projectID, technology)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("project with ID %s does not exist", projectID)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if !added {
+		return nil, fmt.Errorf("technology already exists or project not found")
+	}
 
 	// Fetch and return the updated project
 	return s.GetProjectByID(ctx, projectID)
 }
 
 func (s *ProjectService) RemoveTechnology(ctx context.Context, projectID string, technology string) (*model.Project, error) {
-	err := database.Transaction(ctx, func(tx *sql.Tx) error {
-		// Check if the project exists
-		checkProjectQuery := `SELECT id FROM projects WHERE id = $1`
-		var projectIDCheck string
-		err := tx.QueryRowContext(ctx, checkProjectQuery, projectID).Scan(&projectIDCheck)
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("project with ID %s does not exist", projectID)
-		} else if err != nil {
-			return fmt.Errorf("error checking project existence: %w", err)
-		}
-
-		// Remove the technology from the array
-		updateQuery := `
-			UPDATE projects 
-			SET technologies = array_remove(technologies, $1),
-				updated_at = $2
-			WHERE id = $3 AND $1 = ANY(technologies)
-		`
-		result, err := tx.ExecContext(ctx, updateQuery, technology, time.Now().UTC(), projectID)
-		if err != nil {
-			return fmt.Errorf("failed to remove technology: %w", err)
-		}
-
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			return fmt.Errorf("error checking rows affected: %w", err)
-		}
-
-		if rowsAffected == 0 {
-			return fmt.Errorf("technology not found in project or project not found")
-		}
-
-		return nil
-	})
-
+	removed, err := s.Store.Projects().RemoveTechnology(ctx, projectID, technology)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("project with ID %s does not exist", projectID)
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	// Fetch and return the updated project
-	return s.GetProjectByID(ctx, projectID)
-}
-
-// Add this method to the ProjectService struct
-
-func (s *ProjectService) JoinProject(ctx context.Context, projectID, userID string) (*model.Project, error) {
-	err := database.JoinProject(ctx, projectID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to join project: %w", err)
+	if !removed {
+		return nil, fmt.Errorf("technology not found in project or project not found")
 	}
 
 	// Fetch and return the updated project
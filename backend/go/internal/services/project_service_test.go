@@ -0,0 +1,586 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+func TestCreateProjectAddsOwnerToDefaultTeam(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+
+	project := env.createProject(t, owner.ID, "Compiler", "go")
+
+	if project.Status != model.ProjectStatusPlanning {
+		t.Errorf("status = %s, want %s", project.Status, model.ProjectStatusPlanning)
+	}
+	if project.Owner == nil || project.Owner.ID != owner.ID {
+		t.Fatalf("owner = %+v, want %s", project.Owner, owner.ID)
+	}
+
+	teams, err := env.teams.GetTeamsByProject(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 1 || teams[0].Name != "Compiler Team" {
+		t.Fatalf("teams = %+v, want one default team", teams)
+	}
+
+	members, err := env.projects.GetProjectTeamMembers(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].User.ID != owner.ID || members[0].Role != "Owner" {
+		t.Fatalf("members = %+v, want the owner", members)
+	}
+
+	got, err := env.projects.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Owner.Username != "owner" {
+		t.Errorf("owner username = %q, want owner", got.Owner.Username)
+	}
+}
+
+func TestCreateProjectWithUnknownOwnerLeavesNothingBehind(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.projects.CreateProject(ctx, model.CreateProjectInput{Title: "Orphan"}, "missing")
+	if err == nil {
+		t.Fatal("CreateProject succeeded for an unknown owner")
+	}
+
+	projects, err := env.projects.ListProjects(ctx, repository.ProjectFilter{}, model.ProjectSortNewest, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("projects = %d, want 0 after the failed create", len(projects))
+	}
+}
+
+func TestGetProjectByIDNotFound(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.projects.GetProjectByID(context.Background(), "missing")
+	if err == nil || err.Error() != "project not found" {
+		t.Fatalf("err = %v, want project not found", err)
+	}
+}
+
+func TestUpdateProjectRecordsAudit(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	title := "Optimizing Compiler"
+	status := model.ProjectStatusInProgress
+	updated, err := env.projects.UpdateProject(ctx, project.ID, model.UpdateProjectInput{
+		Title:  &title,
+		Status: &status,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != title || updated.Status != status {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	got, err := env.projects.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != title {
+		t.Errorf("stored title = %q, want %q", got.Title, title)
+	}
+
+	events := env.store.AuditEvents()
+	if len(events) != 1 || events[0].Action != audit.ActionProjectUpdate {
+		t.Fatalf("audit = %v, want one %s", env.auditActions(), audit.ActionProjectUpdate)
+	}
+	if events[0].Before.(map[string]interface{})["title"] != "Compiler" {
+		t.Errorf("before snapshot = %v", events[0].Before)
+	}
+}
+
+func TestDeleteProjectRemovesDependents(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	applicant := env.createUser(t, "applicant")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	if _, err := env.joinRequests.CreateJoinRequest(ctx, project.ID, applicant.ID); err != nil {
+		t.Fatal(err)
+	}
+	task, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{
+		Title:     "Parser",
+		Priority:  model.TaskPriorityHigh,
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.projects.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.projects.GetProjectByID(ctx, project.ID); err == nil {
+		t.Error("project still exists")
+	}
+	if teams, _ := env.teams.GetTeamsByProject(ctx, project.ID); len(teams) != 0 {
+		t.Errorf("teams = %d, want 0", len(teams))
+	}
+	if requests, _ := env.joinRequests.GetJoinRequestsByProject(ctx, project.ID); len(requests) != 0 {
+		t.Errorf("join requests = %d, want 0", len(requests))
+	}
+	if _, err := env.tasks.GetTaskByID(ctx, task.ID); err == nil {
+		t.Error("task still exists")
+	}
+	if !env.hasAudit(audit.ActionProjectDelete) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionProjectDelete)
+	}
+
+	if err := env.projects.DeleteProject(ctx, project.ID); err == nil || err.Error() != "project not found" {
+		t.Fatalf("second delete err = %v, want project not found", err)
+	}
+}
+
+func TestListProjectsFilters(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	other := env.createUser(t, "other")
+	env.createProject(t, owner.ID, "Compiler", "go")
+	website := env.createProject(t, owner.ID, "Website", "react")
+	game := env.createProject(t, other.ID, "Game", "go", "opengl")
+
+	category, objective := "Games", "LEARN opengl"
+	if _, err := env.projects.UpdateProject(ctx, game.ID, model.UpdateProjectInput{
+		Category:           &category,
+		OpenPositions:      intPtr(5),
+		LearningObjectives: []string{"learn OpenGL shaders"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.projects.UpdateProjectPopularity(ctx, website.ID, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.projects.UpdateProjectPopularity(ctx, game.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	future := "2999-01-01T00:00:00Z"
+	tests := []struct {
+		name   string
+		filter repository.ProjectFilter
+		sort   model.ProjectSort
+		want   string
+	}{
+		{"no filter", repository.ProjectFilter{}, model.ProjectSortNewest, "Game,Website,Compiler"},
+		{"any technology", repository.ProjectFilter{Technologies: []string{"react", "opengl"}}, model.ProjectSortNewest, "Game,Website"},
+		{"all technologies", repository.ProjectFilter{Technologies: []string{"go", "opengl"}, AllTechnologies: true}, model.ProjectSortNewest, "Game"},
+		{"categories", repository.ProjectFilter{Categories: []string{"Web"}}, model.ProjectSortNewest, "Website,Compiler"},
+		{"statuses", repository.ProjectFilter{Statuses: []model.ProjectStatus{model.ProjectStatusCompleted}}, model.ProjectSortNewest, ""},
+		{"open positions", repository.ProjectFilter{MinOpenPositions: intPtr(3), MaxOpenPositions: intPtr(5)}, model.ProjectSortNewest, "Game"},
+		{"owner", repository.ProjectFilter{OwnerID: &owner.ID}, model.ProjectSortNewest, "Website,Compiler"},
+		{"learning objective", repository.ProjectFilter{LearningObjective: &objective}, model.ProjectSortNewest, "Game"},
+		{"created after", repository.ProjectFilter{CreatedAfter: &future}, model.ProjectSortNewest, ""},
+		{"created before", repository.ProjectFilter{CreatedBefore: &future}, model.ProjectSortNewest, "Game,Website,Compiler"},
+		{"popularity", repository.ProjectFilter{}, model.ProjectSortPopularity, "Website,Game,Compiler"},
+		{"open positions sort", repository.ProjectFilter{}, model.ProjectSortOpenPositions, "Game,Website,Compiler"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := env.projects.ListProjects(ctx, tt.filter, tt.sort, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if titles := projectTitles(projects); titles != tt.want {
+				t.Errorf("titles = %s, want %s", titles, tt.want)
+			}
+		})
+	}
+
+	projects, err := env.projects.ListProjects(ctx, repository.ProjectFilter{}, model.ProjectSortNewest, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := projectTitles(projects); titles != "Website" {
+		t.Errorf("limit 1 offset 1 titles = %s, want Website", titles)
+	}
+}
+
+func TestListProjectsRejectsInvalidFilters(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	yesterday, owner := "yesterday", "x"
+
+	for name, filter := range map[string]repository.ProjectFilter{
+		"min above max": {MinOpenPositions: intPtr(3), MaxOpenPositions: intPtr(1)},
+		"bad date":      {CreatedAfter: &yesterday},
+		"bad owner":     {OwnerID: &owner},
+	} {
+		if _, err := env.projects.ListProjects(ctx, filter, model.ProjectSortNewest, 10, 0); err == nil {
+			t.Errorf("%s: filter was accepted", name)
+		}
+	}
+	if _, err := env.projects.ListProjects(ctx, repository.ProjectFilter{}, "RANDOM", 10, 0); err == nil {
+		t.Error("unknown sort was accepted")
+	}
+}
+
+func TestProjectFacets(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	env.createProject(t, owner.ID, "Compiler", "go", "llvm")
+	env.createProject(t, owner.ID, "Website", "react", "go")
+	game := env.createProject(t, owner.ID, "Game", "go", "go")
+
+	category := "Games"
+	if _, err := env.projects.UpdateProject(ctx, game.ID, model.UpdateProjectInput{Category: &category}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.projects.UpdateProjectStatus(ctx, game.ID, model.ProjectStatusInProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	facets, err := env.projects.ProjectFacets(ctx, repository.ProjectFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if facets.Total != 3 {
+		t.Errorf("total = %d, want 3", facets.Total)
+	}
+	for name, tt := range map[string]struct {
+		got  []*model.FacetCount
+		want string
+	}{
+		"categories":      {facets.Categories, "Web=2,Games=1"},
+		"technologies":    {facets.Technologies, "go=3,llvm=1,react=1"},
+		"statuses":        {facets.Statuses, "PLANNING=2,IN_PROGRESS=1"},
+		"timeCommitments": {facets.TimeCommitments, "5h/week=3"},
+	} {
+		if got := facetString(tt.got); got != tt.want {
+			t.Errorf("%s = %s, want %s", name, got, tt.want)
+		}
+	}
+
+	facets, err = env.projects.ProjectFacets(ctx, repository.ProjectFilter{Technologies: []string{"llvm", "react"}, Query: "compiler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if facets.Total != 1 || facetString(facets.Categories) != "Web=1" || facetString(facets.Technologies) != "go=1,llvm=1" {
+		t.Errorf("filtered facets = %d %s %s", facets.Total, facetString(facets.Categories), facetString(facets.Technologies))
+	}
+
+	if _, err := env.projects.ProjectFacets(ctx, repository.ProjectFilter{MinOpenPositions: intPtr(2), MaxOpenPositions: intPtr(1)}); err == nil {
+		t.Error("invalid filter was accepted")
+	}
+}
+
+func facetString(facets []*model.FacetCount) string {
+	var out []string
+	for _, f := range facets {
+		out = append(out, fmt.Sprintf("%s=%d", f.Value, f.Count))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestSearchAndRelatedProjects(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	compiler := env.createProject(t, owner.ID, "Compiler", "go", "llvm")
+	env.createProject(t, owner.ID, "Linker", "go", "llvm")
+	env.createProject(t, owner.ID, "Formatter", "go")
+
+	found, err := env.projects.SearchProjects(ctx, "linker", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := hitTitles(found); titles != "Linker" {
+		t.Errorf("search titles = %s, want Linker", titles)
+	}
+
+	related, err := env.projects.GetRelatedProjects(ctx, compiler.ID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := projectTitles(related); titles != "Linker,Formatter" {
+		t.Errorf("related titles = %s, want Linker,Formatter", titles)
+	}
+}
+
+func TestSearchProjects(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	env.createProject(t, owner.ID, "Compiler", "go", "llvm")
+	env.createProject(t, owner.ID, "LLVM <b>bindings</b>", "rust")
+	env.createProject(t, owner.ID, "Formatter", "go")
+
+	tests := []struct {
+		query, want string
+	}{
+		{"llvm", "LLVM <b>bindings</b>,Compiler"},
+		{"form", "Formatter"},
+		{"  GO,  compil ", "Compiler"},
+		{"?!", ""},
+	}
+	for _, tt := range tests {
+		hits, err := env.projects.SearchProjects(ctx, tt.query, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if titles := hitTitles(hits); titles != tt.want {
+			t.Errorf("search %q = %s, want %s", tt.query, titles, tt.want)
+		}
+	}
+
+	hits, err := env.projects.SearchProjects(ctx, "llvm", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hits[0].TitleHighlight; got != "<mark>LLVM</mark> &lt;b&gt;bindings&lt;/b&gt;" {
+		t.Errorf("title highlight = %s", got)
+	}
+	if hits[0].Score <= hits[1].Score {
+		t.Errorf("title match scored %v, technology match %v", hits[0].Score, hits[1].Score)
+	}
+
+	hits, err = env.projects.SearchProjects(ctx, "llvm", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if titles := hitTitles(hits); titles != "Compiler" {
+		t.Errorf("limit 1 offset 1 = %s, want Compiler", titles)
+	}
+}
+
+func hitTitles(hits []*model.SearchHit) string {
+	titles := make([]string, len(hits))
+	for i, hit := range hits {
+		titles[i] = hit.Project.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestProjectTechnologies(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	project := env.createProject(t, owner.ID, "Compiler", "go")
+
+	updated, err := env.projects.AddTechnology(ctx, project.ID, "llvm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(updated.Technologies, ",") != "go,llvm" {
+		t.Errorf("technologies = %v", updated.Technologies)
+	}
+
+	if _, err := env.projects.AddTechnology(ctx, project.ID, "llvm"); err == nil {
+		t.Error("duplicate technology was added")
+	}
+
+	updated, err = env.projects.RemoveTechnology(ctx, project.ID, "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(updated.Technologies, ",") != "llvm" {
+		t.Errorf("technologies = %v", updated.Technologies)
+	}
+
+	if _, err := env.projects.RemoveTechnology(ctx, project.ID, "go"); err == nil {
+		t.Error("removing a missing technology succeeded")
+	}
+	if _, err := env.projects.AddTechnology(ctx, "missing", "go"); err == nil {
+		t.Error("adding a technology to a missing project succeeded")
+	}
+}
+
+func TestUpdateProjectPopularityNeverNegative(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	if err := env.projects.UpdateProjectPopularity(ctx, project.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.projects.UpdateProjectPopularity(ctx, project.ID, -10); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := env.projects.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Popularity != 0 {
+		t.Errorf("popularity = %d, want 0", got.Popularity)
+	}
+
+	if err := env.projects.UpdateProjectPopularity(ctx, "missing", 1); err == nil {
+		t.Error("updating a missing project succeeded")
+	}
+}
+
+func TestProjectTeamMembership(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	member := env.createUser(t, "member")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	if err := env.projects.AddTeamMember(ctx, project.ID, member.ID, "Developer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.projects.AddTeamMember(ctx, project.ID, member.ID, "Developer"); err == nil {
+		t.Error("adding a member twice succeeded")
+	}
+	if err := env.projects.AddTeamMember(ctx, project.ID, "missing", "Developer"); err == nil {
+		t.Error("adding an unknown user succeeded")
+	}
+
+	if err := env.projects.RemoveTeamMember(ctx, project.ID, member.ID); err != nil {
+		t.Fatal(err)
+	}
+	err := env.projects.RemoveTeamMember(ctx, project.ID, member.ID)
+	if err == nil || err.Error() != "user is not a team member of this project" {
+		t.Fatalf("second remove err = %v", err)
+	}
+}
+
+func projectTitles(projects []*model.Project) string {
+	titles := make([]string, len(projects))
+	for i, p := range projects {
+		titles[i] = p.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestListProjectsConnectionPagesWithoutGapsOrRepeats(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	for _, title := range []string{"Compiler", "Debugger", "Linker", "Loader", "Profiler"} {
+		env.createProject(t, owner.ID, title)
+	}
+
+	seen := map[string]bool{}
+	args := pagination.Args{First: intPtr(2), Order: model.ConnectionOrderNewestFirst}
+	for page := 0; ; page++ {
+		conn, err := env.projects.ListProjectsConnection(ctx, repository.ProjectFilter{}, args, page == 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page == 0 && conn.TotalCount != 5 {
+			t.Errorf("totalCount = %d, want 5", conn.TotalCount)
+		}
+		for _, edge := range conn.Edges {
+			if seen[edge.Node.Title] {
+				t.Errorf("%s returned twice", edge.Node.Title)
+			}
+			seen[edge.Node.Title] = true
+		}
+
+		// A project created while browsing sorts before the cursor and must
+		// not shift the later pages
+		if page == 0 {
+			env.createProject(t, owner.ID, "Assembler")
+		}
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		args.After = conn.PageInfo.EndCursor
+	}
+	if len(seen) < 5 {
+		t.Errorf("saw %d projects, want 5: %v", len(seen), seen)
+	}
+
+	// Paging backward from the end returns the oldest projects
+	conn, err := env.projects.ListProjectsConnection(ctx, repository.ProjectFilter{},
+		pagination.Args{Last: intPtr(1), Order: model.ConnectionOrderOldestFirst}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.Edges) != 1 || !conn.PageInfo.HasPreviousPage || conn.PageInfo.HasNextPage {
+		t.Errorf("last page = %+v %+v", conn.Edges, conn.PageInfo)
+	}
+
+	if _, err := env.projects.ListProjectsConnection(ctx, repository.ProjectFilter{}, pagination.Args{First: intPtr(pagination.MaxPageSize + 1)}, false); err == nil {
+		t.Error("page larger than the maximum was accepted")
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestHiddenProjectsListedOnlyForOwnerAndAdmins(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	member := env.createUser(t, "member")
+	admin := env.createUser(t, "admin")
+	if err := env.store.Users().SetRole(ctx, admin.ID, model.UserRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	hidden := env.createProject(t, owner.ID, "Hidden")
+	env.createProject(t, owner.ID, "Visible")
+	if err := env.projects.AddTeamMember(ctx, hidden.ID, member.ID, "Developer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.store.Projects().Hide(ctx, hidden.ID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		viewer   string
+		owned    string
+		memberOf string
+	}{
+		{"anonymous", "", "Visible", ""},
+		{"member", member.ID, "Visible", ""},
+		{"owner", owner.ID, "Visible,Hidden", "Hidden"},
+		{"admin", admin.ID, "Visible,Hidden", "Hidden"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := ctx
+			if tc.viewer != "" {
+				ctx = auth.SetUserID(ctx, tc.viewer)
+			}
+
+			owned, err := env.projects.GetProjectsByOwners(ctx, []string{owner.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if titles := projectTitles(owned[owner.ID]); titles != tc.owned {
+				t.Errorf("owned projects = %q, want %q", titles, tc.owned)
+			}
+
+			memberOf, err := env.users.GetProjectsByMembers(ctx, []string{member.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if titles := projectTitles(memberOf[member.ID]); titles != tc.memberOf {
+				t.Errorf("member projects = %q, want %q", titles, tc.memberOf)
+			}
+		})
+	}
+}
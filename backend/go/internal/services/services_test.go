@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/memory"
+)
+
+func TestMain(m *testing.M) {
+	// Verification emails carry signed tokens
+	if err := auth.InitJWTKeys("", "", true); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// recordingMailer keeps sent messages instead of delivering them
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+	// err, if set, fails every send
+	err error
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *recordingMailer) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *recordingMailer) sent() []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mail.Message(nil), m.messages...)
+}
+
+// testEnv wires the services to a fresh in-memory store
+type testEnv struct {
+	store        *memory.Store
+	mailer       *recordingMailer
+	users        *UserService
+	projects     *ProjectService
+	teams        *TeamService
+	tasks        *TaskService
+	joinRequests *JoinRequestService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	store := memory.NewStore()
+	auth.SetSessionStore(store.Sessions())
+	mailer := &recordingMailer{}
+	users := NewUserService(store, mailer, "http://app.test/")
+	return &testEnv{
+		store:        store,
+		mailer:       mailer,
+		users:        users,
+		projects:     NewProjectService(store, users),
+		teams:        NewTeamService(store),
+		tasks:        NewTaskService(store),
+		joinRequests: NewJoinRequestService(store),
+	}
+}
+
+func (e *testEnv) createUser(t *testing.T, username string) *model.User {
+	t.Helper()
+
+	user, err := e.users.CreateUser(context.Background(), model.CreateUserInput{
+		Username:  username,
+		Email:     username + "@example.com",
+		Password:  "correct horse battery staple",
+		FirstName: "Test",
+		LastName:  username,
+		Skills:    []string{"go"},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", username, err)
+	}
+	return user
+}
+
+func (e *testEnv) createProject(t *testing.T, ownerID, title string, technologies ...string) *model.Project {
+	t.Helper()
+
+	project, err := e.projects.CreateProject(context.Background(), model.CreateProjectInput{
+		Title:              title,
+		Description:        "A project about " + title,
+		Category:           "Web",
+		Technologies:       technologies,
+		OpenPositions:      2,
+		TimeCommitment:     "5h/week",
+		LearningObjectives: []string{"ship it"},
+	}, ownerID)
+	if err != nil {
+		t.Fatalf("CreateProject(%s): %v", title, err)
+	}
+	return project
+}
+
+// auditActions lists the actions recorded so far, oldest first
+func (e *testEnv) auditActions() []string {
+	var actions []string
+	for _, event := range e.store.AuditEvents() {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+func (e *testEnv) hasAudit(action string) bool {
+	for _, a := range e.auditActions() {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -2,30 +2,35 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
 )
 
 type TaskService struct {
-	DB *sql.DB
+	Store repository.Store
 }
 
-func NewTaskService(db *sql.DB) *TaskService {
+func NewTaskService(store repository.Store) *TaskService {
 	return &TaskService{
-		DB: db,
+		Store: store,
 	}
 }
 
-func (s *TaskService) CreateTask(ctx context.Context, input model.CreateTaskInput) (*model.Task, error) {
-	query := `
-		INSERT INTO tasks (title, description, status, priority, due_date, project_id, assignee_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
-		RETURNING id, title, description, status, priority, due_date, project_id, assignee_id, created_at, updated_at`
+// taskNotFound turns a missing task into the error the API reports
+func taskNotFound(taskID string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("task not found: %v", taskID)
+	}
+	return err
+}
 
+func (s *TaskService) CreateTask(ctx context.Context, input model.CreateTaskInput) (*model.Task, error) {
 	task := &model.Task{
 		Title:       input.Title,
 		Description: input.Description,
@@ -45,452 +50,180 @@ func (s *TaskService) CreateTask(ctx context.Context, input model.CreateTaskInpu
 		task.Assignee = &model.User{ID: *input.AssigneeID}
 	}
 
-	err := s.DB.QueryRowContext(ctx, query,
-		task.Title,
-		task.Description,
-		task.Status,
-		task.Priority,
-		task.DueDate,
-		task.Project.ID,
-		input.AssigneeID,
-		task.CreatedAt,
-	).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.DueDate,
-		&task.Project.ID,
-		&task.Assignee.ID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
-
-	if err != nil {
+	if err := s.Store.Tasks().Create(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to create task: %v", err)
 	}
 
 	return task, nil
 }
 
+// GetTaskByID returns a task unless its project was hidden from the caller by
+// moderators, in which case the task looks like it does not exist
 func (s *TaskService) GetTaskByID(ctx context.Context, taskID string) (*model.Task, error) {
-	query := `
-		SELECT t.id, t.title, t.description, t.status, t.priority, t.due_date, 
-		       t.project_id, t.assignee_id, t.created_at, t.updated_at,
-		       p.title as project_title, u.username as assignee_username
-		FROM tasks t
-		LEFT JOIN projects p ON t.project_id = p.id
-		LEFT JOIN users u ON t.assignee_id = u.id
-		WHERE t.id = $1`
-
-	var task model.Task
-	var projectTitle, assigneeUsername sql.NullString
-
-	err := s.DB.QueryRowContext(ctx, query, taskID).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.DueDate,
-		&task.Project.ID,
-		&task.Assignee.ID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-		&projectTitle,
-		&assigneeUsername,
-	)
-
+	task, err := s.Store.Tasks().GetByID(ctx, taskID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task not found: %v", taskID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, taskNotFound(taskID, err)
 		}
 		return nil, fmt.Errorf("failed to get task: %v", err)
 	}
 
-	// Set Project and Assignee details if they exist
-	if projectTitle.Valid {
-		task.Project = &model.Project{
-			ID:    task.Project.ID,
-			Title: projectTitle.String,
-		}
+	viewer, err := currentViewer(ctx, s.Store)
+	if err != nil {
+		return nil, err
 	}
-
-	if assigneeUsername.Valid {
-		task.Assignee = &model.User{
-			ID:       task.Assignee.ID,
-			Username: assigneeUsername.String,
-		}
+	visible, err := s.Store.Projects().Visible(ctx, task.Project.ID, viewer)
+	if err != nil {
+		return nil, err
 	}
-
-	return &task, nil
+	if !visible {
+		return nil, taskNotFound(taskID, repository.ErrNotFound)
+	}
+	return task, nil
 }
 
 func (s *TaskService) UpdateTask(ctx context.Context, taskID string, input model.UpdateTaskInput) (*model.Task, error) {
-	// Start building the query
-	query := "UPDATE tasks SET "
-	var args []interface{}
-	argIndex := 1
-
-	// Helper function to add a field to the query
-	addField := func(field string, value interface{}) {
-		if argIndex > 1 {
-			query += ", "
-		}
-		query += field + " = $" + strconv.Itoa(argIndex)
-		args = append(args, value)
-		argIndex++
+	task, err := s.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add fields to update based on input
+	// Update fields if provided in input
 	if input.Title != nil {
-		addField("title", *input.Title)
+		task.Title = *input.Title
 	}
 	if input.Description != nil {
-		addField("description", *input.Description)
+		task.Description = input.Description
 	}
+	completed := false
 	if input.Status != nil {
-		addField("status", *input.Status)
+		completed = task.Status != model.TaskStatusDone && *input.Status == model.TaskStatusDone
+		task.Status = *input.Status
 	}
 	if input.Priority != nil {
-		addField("priority", *input.Priority)
+		task.Priority = *input.Priority
 	}
 	if input.DueDate != nil {
-		addField("due_date", *input.DueDate)
+		task.DueDate = input.DueDate
 	}
 	if input.AssigneeID != nil {
-		addField("assignee_id", *input.AssigneeID)
+		task.Assignee = &model.User{ID: *input.AssigneeID}
 	}
+	task.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 
-	// Add updated_at field
-	addField("updated_at", time.Now().UTC())
-
-	// Finish the query
-	query += " WHERE id = $" + strconv.Itoa(argIndex) + " RETURNING id, title, description, status, priority, due_date, project_id, assignee_id, created_at, updated_at"
-	args = append(args, taskID)
-
-	// Execute the query
-	var task model.Task
-	err := s.DB.QueryRowContext(ctx, query, args...).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.DueDate,
-		&task.Project.ID,
-		&task.Assignee.ID,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task not found: %v", taskID)
+	if err := s.Store.Tasks().Update(ctx, task); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, taskNotFound(taskID, err)
 		}
 		return nil, fmt.Errorf("failed to update task: %v", err)
 	}
-
-	// Fetch project and assignee details
-	if task.Project.ID != "" {
-		err = s.DB.QueryRowContext(ctx, "SELECT title FROM projects WHERE id = $1", task.Project.ID).Scan(&task.Project.Title)
-		if err != nil && err != sql.ErrNoRows {
-			return nil, fmt.Errorf("failed to fetch project details: %v", err)
-		}
-	}
-
-	if task.Assignee.ID != "" {
-		err = s.DB.QueryRowContext(ctx, "SELECT username FROM users WHERE id = $1", task.Assignee.ID).Scan(&task.Assignee.Username)
-		if err != nil && err != sql.ErrNoRows {
-			return nil, fmt.Errorf("failed to fetch assignee details: %v", err)
-		}
+	if completed {
+		metrics.TasksCompleted.Inc()
 	}
 
-	return &task, nil
+	// Re-read to pick up the project title and assignee username
+	return s.GetTaskByID(ctx, taskID)
 }
 
 func (s *TaskService) DeleteTask(ctx context.Context, taskID string) error {
-	query := "DELETE FROM tasks WHERE id = $1"
-
-	result, err := s.DB.ExecContext(ctx, query, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to delete task: %v", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("task not found: %v", taskID)
-	}
-
-	return nil
+	return taskNotFound(taskID, s.Store.Tasks().Delete(ctx, taskID))
 }
 
-func (s *TaskService) ListTasksByProject(ctx context.Context, projectID string) ([]*model.Task, error) {
-	query := `
-		SELECT t.id, t.title, t.description, t.status, t.priority, t.due_date, 
-		       t.project_id, t.assignee_id, t.created_at, t.updated_at,
-		       u.username as assignee_username
-		FROM tasks t
-		LEFT JOIN users u ON t.assignee_id = u.id
-		WHERE t.project_id = $1
-		ORDER BY t.created_at DESC`
-
-	rows, err := s.DB.QueryContext(ctx, query, projectID)
+// ListTasksByProject returns a project's tasks, newest first, optionally
+// only those with the given status
+func (s *TaskService) ListTasksByProject(ctx context.Context, projectID string, status *model.TaskStatus, limit, offset int) ([]*model.Task, error) {
+	viewer, err := currentViewer(ctx, s.Store)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %v", err)
+		return nil, err
 	}
-	defer rows.Close()
-
-	var tasks []*model.Task
-	for rows.Next() {
-		var task model.Task
-		var assigneeUsername sql.NullString
-
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.Project.ID,
-			&task.Assignee.ID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&assigneeUsername,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task row: %v", err)
-		}
-
-		task.Project = &model.Project{ID: projectID}
-
-		if assigneeUsername.Valid {
-			task.Assignee = &model.User{
-				ID:       task.Assignee.ID,
-				Username: assigneeUsername.String,
-			}
-		}
-
-		tasks = append(tasks, &task)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task rows: %v", err)
-	}
-
-	return tasks, nil
+	return s.Store.Tasks().ByProject(ctx, projectID, status, viewer, limit, offset)
 }
 
 func (s *TaskService) AssignTask(ctx context.Context, taskID string, userID string) error {
-	query := `
-		UPDATE tasks
-		SET assignee_id = $1, updated_at = $2
-		WHERE id = $3`
-
-	result, err := s.DB.ExecContext(ctx, query, userID, time.Now().UTC(), taskID)
-	if err != nil {
-		return fmt.Errorf("failed to assign task: %v", err)
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
-	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("task not found: %v", taskID)
-	}
-
-	return nil
+	return taskNotFound(taskID, s.Store.Tasks().SetAssignee(ctx, taskID, &userID))
 }
 
 func (s *TaskService) UnassignTask(ctx context.Context, taskID string) error {
 	// First, check if the task is currently assigned
-	var currentAssigneeID sql.NullString
-	checkQuery := `SELECT assignee_id FROM tasks WHERE id = $1`
-	err := s.DB.QueryRowContext(ctx, checkQuery, taskID).Scan(&currentAssigneeID)
+	task, err := s.GetTaskByID(ctx, taskID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("task not found: %v", taskID)
-		}
-		return fmt.Errorf("failed to check current assignee: %v", err)
+		return err
 	}
 
-	if !currentAssigneeID.Valid {
+	if task.Assignee == nil {
 		return nil // Task is already unassigned, no action needed
 	}
 
-	// If assigned, proceed with the unassignment
-	updateQuery := `
-		UPDATE tasks
-		SET assignee_id = NULL, updated_at = $1
-		WHERE id = $2`
+	return taskNotFound(taskID, s.Store.Tasks().SetAssignee(ctx, taskID, nil))
+}
 
-	result, err := s.DB.ExecContext(ctx, updateQuery, time.Now().UTC(), taskID)
+func (s *TaskService) UpdateTaskStatus(ctx context.Context, taskID string, status model.TaskStatus) error {
+	task, err := s.GetTaskByID(ctx, taskID)
 	if err != nil {
-		return fmt.Errorf("failed to unassign task: %v", err)
+		return err
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
+	if err := s.Store.Tasks().UpdateStatus(ctx, taskID, status); err != nil {
+		return taskNotFound(taskID, err)
 	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("task not found: %v", taskID)
+	if task.Status != model.TaskStatusDone && status == model.TaskStatusDone {
+		metrics.TasksCompleted.Inc()
 	}
-
 	return nil
 }
 
-func (s *TaskService) UpdateTaskStatus(ctx context.Context, taskID string, status model.TaskStatus) error {
-	query := `
-		UPDATE tasks
-		SET status = $1, updated_at = $2
-		WHERE id = $3`
-
-	result, err := s.DB.ExecContext(ctx, query, status, time.Now().UTC(), taskID)
+// GetTasksByUser returns the tasks assigned to a user by due date,
+// optionally only those with the given status
+func (s *TaskService) GetTasksByUser(ctx context.Context, userID string, status *model.TaskStatus, limit, offset int) ([]*model.Task, error) {
+	viewer, err := currentViewer(ctx, s.Store)
 	if err != nil {
-		return fmt.Errorf("failed to update task status: %v", err)
+		return nil, err
 	}
+	return s.Store.Tasks().ByAssignee(ctx, userID, status, viewer, limit, offset)
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting rows affected: %v", err)
+// ListTasksConnection returns a page of the tasks matching the filter, which
+// must name a project or an assignee. The total is only counted when
+// withTotal is set.
+func (s *TaskService) ListTasksConnection(ctx context.Context, filter repository.TaskFilter, args pagination.Args, withTotal bool) (*model.TaskConnection, error) {
+	if filter.ProjectID == nil && filter.AssigneeID == nil {
+		return nil, errors.New("a project or an assignee is required")
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task not found: %v", taskID)
+	viewer, err := currentViewer(ctx, s.Store)
+	if err != nil {
+		return nil, err
 	}
+	filter.Viewer = &viewer
 
-	return nil
-}
-
-func (s *TaskService) GetTasksByUser(ctx context.Context, userID string) ([]*model.Task, error) {
-	query := `
-		SELECT t.id, t.title, t.description, t.status, t.priority, t.due_date, 
-		       t.project_id, t.assignee_id, t.created_at, t.updated_at,
-		       p.title as project_title
-		FROM tasks t
-		LEFT JOIN projects p ON t.project_id = p.id
-		WHERE t.assignee_id = $1
-		ORDER BY t.due_date ASC, t.priority DESC`
-
-	rows, err := s.DB.QueryContext(ctx, query, userID)
+	page, err := pagination.Fetch(args, func(p repository.Page) ([]*model.Task, error) {
+		return s.Store.Tasks().ListPage(ctx, filter, p)
+	}, func(t *model.Task) repository.Cursor {
+		return repository.Cursor{CreatedAt: t.CreatedAt, ID: t.ID}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tasks: %v", err)
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
 	}
-	defer rows.Close()
-
-	var tasks []*model.Task
-	for rows.Next() {
-		var task model.Task
-		var projectTitle sql.NullString
-
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.Project.ID,
-			&task.Assignee.ID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&projectTitle,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task row: %v", err)
-		}
-
-		task.Assignee = &model.User{ID: userID}
-
-		if projectTitle.Valid {
-			task.Project = &model.Project{
-				ID:    task.Project.ID,
-				Title: projectTitle.String,
-			}
-		}
 
-		tasks = append(tasks, &task)
+	conn := &model.TaskConnection{Edges: []*model.TaskEdge{}, PageInfo: page.PageInfo}
+	for i, task := range page.Items {
+		conn.Edges = append(conn.Edges, &model.TaskEdge{Cursor: page.Cursors[i], Node: task})
 	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task rows: %v", err)
+	if withTotal {
+		if conn.TotalCount, err = s.Store.Tasks().Count(ctx, filter); err != nil {
+			return nil, err
+		}
 	}
-
-	return tasks, nil
+	return conn, nil
 }
 
 func (s *TaskService) SearchTasks(ctx context.Context, query string, limit, offset int) ([]*model.Task, error) {
-	sqlQuery := `
-		SELECT t.id, t.title, t.description, t.status, t.priority, t.due_date, 
-		       t.project_id, t.assignee_id, t.created_at, t.updated_at,
-		       p.title as project_title, u.username as assignee_username
-		FROM tasks t
-		LEFT JOIN projects p ON t.project_id = p.id
-		LEFT JOIN users u ON t.assignee_id = u.id
-		WHERE t.title ILIKE $1 OR t.description ILIKE $1
-		ORDER BY t.created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := s.DB.QueryContext(ctx, sqlQuery, "%"+query+"%", limit, offset)
+	viewer, err := currentViewer(ctx, s.Store)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search tasks: %v", err)
+		return nil, err
 	}
-	defer rows.Close()
-
-	var tasks []*model.Task
-	for rows.Next() {
-		var task model.Task
-		var projectTitle, assigneeUsername sql.NullString
-
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.Project.ID,
-			&task.Assignee.ID,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&projectTitle,
-			&assigneeUsername,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task row: %v", err)
-		}
-
-		if projectTitle.Valid {
-			task.Project = &model.Project{
-				ID:    task.Project.ID,
-				Title: projectTitle.String,
-			}
-		}
-
-		if assigneeUsername.Valid {
-			task.Assignee = &model.User{
-				ID:       task.Assignee.ID,
-				Username: assigneeUsername.String,
-			}
-		}
-
-		tasks = append(tasks, &task)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task rows: %v", err)
+	tasks, err := s.Store.Tasks().Search(ctx, query, viewer, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %v", err)
 	}
-
 	return tasks, nil
 }
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+func TestCreateAndUpdateTask(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	dev := env.createUser(t, "dev")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	task, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{
+		Title:     "Parser",
+		Priority:  model.TaskPriorityMedium,
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ID == "" || task.Status != model.TaskStatusTodo || task.Assignee != nil {
+		t.Fatalf("task = %+v", task)
+	}
+
+	title := "Recursive descent parser"
+	status := model.TaskStatusInProgress
+	updated, err := env.tasks.UpdateTask(ctx, task.ID, model.UpdateTaskInput{
+		Title:      &title,
+		Status:     &status,
+		AssigneeID: &dev.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != title || updated.Status != status {
+		t.Errorf("updated = %+v", updated)
+	}
+	if updated.Assignee == nil || updated.Assignee.Username != "dev" {
+		t.Errorf("assignee = %+v, want dev", updated.Assignee)
+	}
+	if updated.Project.Title != "Compiler" {
+		t.Errorf("project title = %q, want Compiler", updated.Project.Title)
+	}
+}
+
+func TestCreateTaskForUnknownProject(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.tasks.CreateTask(context.Background(), model.CreateTaskInput{
+		Title:     "Parser",
+		Priority:  model.TaskPriorityLow,
+		ProjectID: "missing",
+	})
+	if err == nil {
+		t.Fatal("CreateTask succeeded for a missing project")
+	}
+}
+
+func TestAssignAndUnassignTask(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	dev := env.createUser(t, "dev")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	later, soon := "2030-01-02T00:00:00Z", "2030-01-01T00:00:00Z"
+	first, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{Title: "Later", Priority: model.TaskPriorityLow, DueDate: &later, ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{Title: "Soon", Priority: model.TaskPriorityLow, DueDate: &soon, ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, task := range []*model.Task{first, second} {
+		if err := env.tasks.AssignTask(ctx, task.ID, dev.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tasks, err := env.tasks.GetTasksByUser(ctx, dev.ID, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || tasks[0].Title != "Soon" {
+		t.Fatalf("tasks = %+v, want both ordered by due date", tasks)
+	}
+
+	if err := env.tasks.UnassignTask(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Unassigning again is a no-op
+	if err := env.tasks.UnassignTask(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err = env.tasks.GetTasksByUser(ctx, dev.ID, nil, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != second.ID {
+		t.Errorf("tasks = %+v, want only the still assigned task", tasks)
+	}
+
+	if err := env.tasks.AssignTask(ctx, "missing", dev.ID); err == nil || err.Error() != "task not found: missing" {
+		t.Errorf("AssignTask(missing) err = %v", err)
+	}
+}
+
+func TestTaskStatusListingAndDelete(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	task, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{Title: "Lexer", Priority: model.TaskPriorityHigh, ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.tasks.UpdateTaskStatus(ctx, task.ID, model.TaskStatusDone); err != nil {
+		t.Fatal(err)
+	}
+
+	parser, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{Title: "Parser", Priority: model.TaskPriorityLow, ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := model.TaskStatusDone
+	tasks, err := env.tasks.ListTasksByProject(ctx, project.ID, &done, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("done tasks = %+v, want only the lexer", tasks)
+	}
+
+	// Newest first, so the second page of one holds the older task
+	tasks, err = env.tasks.ListTasksByProject(ctx, project.ID, nil, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != parser.ID {
+		t.Fatalf("first page = %+v, want the parser", tasks)
+	}
+	tasks, err = env.tasks.ListTasksByProject(ctx, project.ID, nil, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("second page = %+v, want the lexer", tasks)
+	}
+
+	found, err := env.tasks.SearchTasks(ctx, "lex", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 {
+		t.Errorf("search found %d tasks, want 1", len(found))
+	}
+
+	if err := env.tasks.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.tasks.DeleteTask(ctx, task.ID); err == nil {
+		t.Error("deleting a task twice succeeded")
+	}
+}
+
+func TestListTasksConnectionFilters(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	for _, title := range []string{"Lexer", "Parser", "Codegen"} {
+		input := model.CreateTaskInput{Title: title, Priority: model.TaskPriorityHigh, ProjectID: project.ID}
+		if title != "Codegen" {
+			input.AssigneeID = &owner.ID
+		}
+		if _, err := env.tasks.CreateTask(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	todo := model.TaskStatusTodo
+	tests := []struct {
+		name   string
+		filter repository.TaskFilter
+		want   int
+	}{
+		{"by project", repository.TaskFilter{ProjectID: &project.ID}, 3},
+		{"by assignee", repository.TaskFilter{AssigneeID: &owner.ID}, 2},
+		{"by project and status", repository.TaskFilter{ProjectID: &project.ID, Status: &todo}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := env.tasks.ListTasksConnection(ctx, tt.filter, pagination.Args{First: intPtr(2)}, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conn.TotalCount != tt.want {
+				t.Errorf("totalCount = %d, want %d", conn.TotalCount, tt.want)
+			}
+			if len(conn.Edges) != min(2, tt.want) || conn.PageInfo.HasNextPage != (tt.want > 2) {
+				t.Errorf("got %d edges, page info %+v", len(conn.Edges), conn.PageInfo)
+			}
+		})
+	}
+
+	if _, err := env.tasks.ListTasksConnection(ctx, repository.TaskFilter{}, pagination.Args{}, false); err == nil {
+		t.Error("listing every task without a project or assignee was accepted")
+	}
+}
+
+func TestTasksOfHiddenProjects(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	assignee := env.createUser(t, "assignee")
+	admin := env.createUser(t, "admin")
+	if err := env.store.Users().SetRole(ctx, admin.ID, model.UserRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	project := env.createProject(t, owner.ID, "Hidden")
+	task, err := env.tasks.CreateTask(ctx, model.CreateTaskInput{
+		Title: "Secret", Priority: model.TaskPriorityLow, ProjectID: project.ID, AssigneeID: &assignee.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.store.Projects().Hide(ctx, project.ID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		viewer  string
+		visible bool
+	}{
+		{"anonymous", "", false},
+		{"assignee", assignee.ID, false},
+		{"owner", owner.ID, true},
+		{"admin", admin.ID, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := ctx
+			if tc.viewer != "" {
+				ctx = auth.SetUserID(ctx, tc.viewer)
+			}
+			want := 0
+			if tc.visible {
+				want = 1
+			}
+
+			if _, err := env.tasks.GetTaskByID(ctx, task.ID); (err == nil) != tc.visible {
+				t.Errorf("GetTaskByID: err = %v, want visible %v", err, tc.visible)
+			}
+			byProject, err := env.tasks.ListTasksByProject(ctx, project.ID, nil, 10, 0)
+			if err != nil || len(byProject) != want {
+				t.Errorf("ListTasksByProject = %d tasks, %v, want %d", len(byProject), err, want)
+			}
+			byUser, err := env.tasks.GetTasksByUser(ctx, assignee.ID, nil, 10, 0)
+			if err != nil || len(byUser) != want {
+				t.Errorf("GetTasksByUser = %d tasks, %v, want %d", len(byUser), err, want)
+			}
+			conn, err := env.tasks.ListTasksConnection(ctx, repository.TaskFilter{AssigneeID: &assignee.ID}, pagination.Args{}, true)
+			if err != nil || conn.TotalCount != want || len(conn.Edges) != want {
+				t.Errorf("ListTasksConnection = %+v, %v, want %d tasks", conn, err, want)
+			}
+			found, err := env.tasks.SearchTasks(ctx, "Secret", 10, 0)
+			if err != nil || len(found) != want {
+				t.Errorf("SearchTasks = %d tasks, %v, want %d", len(found), err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+)
+
+func TestTeamLifecycle(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	owner := env.createUser(t, "owner")
+	dev := env.createUser(t, "dev")
+	project := env.createProject(t, owner.ID, "Compiler")
+
+	team, err := env.teams.CreateTeam(ctx, model.CreateTeamInput{Name: "Backend", ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := env.teams.GetTeamByID(ctx, team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Project == nil || got.Project.Title != "Compiler" {
+		t.Errorf("project = %+v, want Compiler", got.Project)
+	}
+
+	name := "Platform"
+	updated, err := env.teams.UpdateTeam(ctx, team.ID, model.UpdateTeamInput{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name {
+		t.Errorf("name = %q, want %q", updated.Name, name)
+	}
+
+	// The default team created with the project stays first
+	teams, err := env.teams.GetTeamsByProject(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 2 || teams[0].Name != "Compiler Team" {
+		t.Fatalf("teams = %+v", teams)
+	}
+
+	if _, err := env.teams.AddTeamMember(ctx, team.ID, dev.ID, "Supreme Leader"); err == nil {
+		t.Error("AddTeamMember accepted a role outside the allow-list")
+	}
+	member, err := env.teams.AddTeamMember(ctx, team.ID, dev.ID, "Developer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.ID == "" {
+		t.Error("member has no ID")
+	}
+	if _, err := env.teams.AddTeamMember(ctx, team.ID, dev.ID, "Developer"); err == nil {
+		t.Error("adding a member twice succeeded")
+	}
+
+	if err := env.teams.UpdateTeamMemberRole(ctx, team.ID, dev.ID, "Lead"); err != nil {
+		t.Fatal(err)
+	}
+	members, err := env.teams.GetTeamMembers(ctx, team.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0].Role != "Lead" || members[0].User.Username != "dev" {
+		t.Fatalf("members = %+v", members)
+	}
+
+	if err := env.teams.RemoveTeamMember(ctx, team.ID, dev.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.teams.DeleteTeam(ctx, team.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.teams.GetTeamByID(ctx, team.ID); err == nil || err.Error() != "team not found" {
+		t.Errorf("GetTeamByID after delete err = %v, want team not found", err)
+	}
+}
+
+func TestCreateTeamForUnknownProject(t *testing.T) {
+	env := newTestEnv(t)
+
+	_, err := env.teams.CreateTeam(context.Background(), model.CreateTeamInput{Name: "Backend", ProjectID: "missing"})
+	if err == nil {
+		t.Fatal("CreateTeam succeeded for a missing project")
+	}
+}
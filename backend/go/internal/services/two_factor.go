@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+// totpIssuer is the account label shown in authenticator apps
+const totpIssuer = "Projectivity"
+
+// recoveryCodeCount is how many single-use recovery codes are issued when
+// two-factor authentication is turned on
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication has not been set up")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// LoginResult is the outcome of a correct password. Accounts with two-factor
+// authentication get a challenge instead of tokens.
+type LoginResult struct {
+	Tokens             *auth.TokenPair
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
+
+// EnrollTwoFactor generates a new TOTP secret for the user. Two-factor
+// authentication only takes effect once a code from it is confirmed.
+func (s *UserService) EnrollTwoFactor(ctx context.Context, userID string) (*model.TwoFactorEnrollment, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.TwoFactorEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	if err := s.Store.TwoFactor().SetSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor turns on two-factor authentication once the user proves
+// their authenticator works, and returns a fresh set of recovery codes. The
+// codes are only shown here; just their hashes are stored.
+func (s *UserService) ConfirmTwoFactor(ctx context.Context, userID, code string) ([]string, error) {
+	var codes []string
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		secret, err := tx.TwoFactor().Secret(ctx, userID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTwoFactorNotEnrolled
+		} else if err != nil {
+			return fmt.Errorf("failed to look up TOTP secret: %w", err)
+		}
+		if secret.Confirmed {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		step, ok := auth.ValidateTOTPCode(secret.Secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		if err := tx.TwoFactor().Confirm(ctx, userID, step); err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionTwoFactorEnable,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns off two-factor authentication. A current TOTP or
+// recovery code is required so a hijacked session alone cannot remove it.
+func (s *UserService) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	return s.Store.WithTx(ctx, func(tx repository.Store) error {
+		if err := verifySecondFactor(ctx, tx, userID, code); err != nil {
+			return err
+		}
+
+		if err := tx.TwoFactor().Delete(ctx, userID); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionTwoFactorDisable,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+		})
+	})
+}
+
+// TwoFactorEnabled reports whether the user has confirmed a TOTP secret
+func (s *UserService) TwoFactorEnabled(ctx context.Context, userID string) (bool, error) {
+	secret, err := s.Store.TwoFactor().Secret(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check two-factor status: %w", err)
+	}
+	return secret.Confirmed, nil
+}
+
+// VerifyTwoFactorLogin completes a login that was answered with a challenge.
+// Wrong codes count as failed logins for the account and client IP.
+func (s *UserService) VerifyTwoFactorLogin(ctx context.Context, challengeToken, code string) (*auth.TokenPair, error) {
+	userID, err := auth.ParseTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, auth.ErrInvalidTwoFactorChallenge
+	}
+
+	clientIP := auth.GetClientIPFromContext(ctx)
+	if err := s.Throttle.Check(ctx, user.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	err = s.Store.WithTx(ctx, func(tx repository.Store) error {
+		return verifySecondFactor(ctx, tx, userID, code)
+	})
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		s.recordLoginFailure(ctx, userID, user.Email, clientIP)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.Throttle.RecordSuccess(ctx, user.Email); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset login throttle", "error", err)
+	}
+
+	if err := s.checkUserActive(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, userID, "two_factor")
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+// A TOTP code is only accepted once; a recovery code is used up.
+func verifySecondFactor(ctx context.Context, tx repository.Store, userID, code string) error {
+	secret, err := tx.TwoFactor().Secret(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !secret.Confirmed) {
+		return ErrTwoFactorNotEnrolled
+	} else if err != nil {
+		return fmt.Errorf("failed to look up TOTP secret: %w", err)
+	}
+
+	if step, ok := auth.ValidateTOTPCode(secret.Secret, code, time.Now()); ok {
+		if secret.LastUsedStep != nil && step <= *secret.LastUsedStep {
+			return ErrInvalidTwoFactorCode
+		}
+		return tx.TwoFactor().SetLastUsedStep(ctx, userID, step)
+	}
+
+	err = tx.TwoFactor().UseRecoveryCode(ctx, userID, auth.HashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidTwoFactorCode
+	} else if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Two-factor recovery code used", "target_user_id", userID)
+	return nil
+}
+
+// replaceRecoveryCodes discards any existing recovery codes and stores a new
+// set, returning the plain codes
+func replaceRecoveryCodes(ctx context.Context, tx repository.Store, userID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, auth.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := tx.TwoFactor().ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code like "k3h7q-4xw2m"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes with or without the
+// dash, in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
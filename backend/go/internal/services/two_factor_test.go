@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
+)
+
+func TestTwoFactorLogin(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	enrollment, err := env.users.EnrollTwoFactor(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Enrolling alone does not turn two-factor authentication on
+	if enabled, err := env.users.TwoFactorEnabled(ctx, user.ID); err != nil || enabled {
+		t.Fatalf("TwoFactorEnabled = %v, %v before confirming", enabled, err)
+	}
+
+	if _, err := env.users.ConfirmTwoFactor(ctx, user.ID, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("ConfirmTwoFactor with a wrong code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	code := totpCode(t, enrollment.Secret, time.Now())
+	recoveryCodes, err := env.users.ConfirmTwoFactor(ctx, user.ID, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recoveryCodes), recoveryCodeCount)
+	}
+
+	result, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tokens != nil || result.ChallengeToken == "" {
+		t.Fatalf("LoginUser = %+v, want a challenge instead of tokens", result)
+	}
+
+	// The code used to confirm cannot be replayed
+	_, err = env.users.VerifyTwoFactorLogin(ctx, result.ChallengeToken, code)
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("replayed TOTP code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	tokens, err := env.users.VerifyTwoFactorLogin(ctx, result.ChallengeToken, recoveryCodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.UserID != user.ID || tokens.AccessToken == "" {
+		t.Errorf("VerifyTwoFactorLogin = %+v, want tokens for %s", tokens, user.ID)
+	}
+
+	_, err = env.users.VerifyTwoFactorLogin(ctx, result.ChallengeToken, recoveryCodes[0])
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("reused recovery code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	if _, err := env.users.VerifyTwoFactorLogin(ctx, "not a challenge", recoveryCodes[1]); err == nil {
+		t.Error("VerifyTwoFactorLogin accepted an invalid challenge")
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	enrollment, err := env.users.EnrollTwoFactor(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes, err := env.users.ConfirmTwoFactor(ctx, user.ID, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.users.EnrollTwoFactor(ctx, user.ID); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Errorf("EnrollTwoFactor again: err = %v, want %v", err, ErrTwoFactorAlreadyEnabled)
+	}
+
+	if err := env.users.DisableTwoFactor(ctx, user.ID, "wrong-code"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("DisableTwoFactor with a wrong code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+	if err := env.users.DisableTwoFactor(ctx, user.ID, recoveryCodes[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tokens == nil {
+		t.Error("LoginUser still asks for a second factor after disabling it")
+	}
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	code, err := auth.TOTPCode(secret, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	enrollment, err := env.users.EnrollTwoFactor(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes, err := env.users.ConfirmTwoFactor(ctx, user.ID, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range recoveryCodes {
+		if seen[code] {
+			t.Errorf("recovery code %s issued twice", code)
+		}
+		seen[code] = true
+	}
+
+	// Codes may be typed without the dash and in any case
+	typed := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	err = env.store.WithTx(ctx, func(tx repository.Store) error {
+		return verifySecondFactor(ctx, tx, user.ID, typed)
+	})
+	if err != nil {
+		t.Fatalf("recovery code %q: %v", typed, err)
+	}
+
+	err = env.store.WithTx(ctx, func(tx repository.Store) error {
+		return verifySecondFactor(ctx, tx, user.ID, recoveryCodes[0])
+	})
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("used recovery code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	// The others are still good
+	err = env.store.WithTx(ctx, func(tx repository.Store) error {
+		return verifySecondFactor(ctx, tx, user.ID, recoveryCodes[1])
+	})
+	if err != nil {
+		t.Errorf("unused recovery code: %v", err)
+	}
+}
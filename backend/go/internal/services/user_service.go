@@ -2,25 +2,40 @@ package services
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
 	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
-	"github.com/evan3v4n/Projectivity/backend/go/internal/database"
-	"github.com/lib/pq"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/pagination"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
-	DB *sql.DB
+	Store    repository.Store
+	Mailer   mail.Mailer
+	Throttle *LoginThrottle
+	AppURL   string
+
+	// background tracks work that outlives the request, such as reset emails
+	background sync.WaitGroup
 }
 
-func NewUserService(db *sql.DB) *UserService {
-	return &UserService{DB: db}
+func NewUserService(store repository.Store, mailer mail.Mailer, appURL string) *UserService {
+	return &UserService{
+		Store:    store,
+		Mailer:   mailer,
+		Throttle: NewLoginThrottle(store.LoginThrottle()),
+		AppURL:   strings.TrimRight(appURL, "/"),
+	}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, input model.CreateUserInput) (*model.User, error) {
@@ -59,66 +74,38 @@ func (s *UserService) CreateUser(ctx context.Context, input model.CreateUserInpu
 	user.Languages = input.Languages
 	user.ProjectPreferences = input.ProjectPreferences
 
-	err = s.DB.QueryRowContext(ctx, `
-		INSERT INTO users (
-			username, email, password_hash, first_name, last_name, 
-			bio, skills, education_level, years_experience, preferred_role, 
-			github_url, linkedin_url, portfolio_url, email_verified, 
-			time_zone, available_hours, certifications, languages, 
-			project_preferences, last_active, joined_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
-		RETURNING id`,
-		user.Username, user.Email, hashedPassword, user.FirstName, user.LastName,
-		user.Bio, pq.Array(user.Skills), user.EducationLevel, user.YearsExperience, user.PreferredRole,
-		user.GithubURL, user.LinkedInURL, user.PortfolioURL, user.EmailVerified,
-		user.TimeZone, user.AvailableHours, pq.Array(user.Certifications), pq.Array(user.Languages),
-		pq.Array(user.ProjectPreferences), user.LastActive, user.JoinedAt, user.CreatedAt, user.UpdatedAt,
-	).Scan(&user.ID)
-
-	if err != nil {
+	if err := s.Store.Users().Create(ctx, user, hashedPassword); err != nil {
 		return nil, err
 	}
 
+	// A failed email should not fail sign-up; the user can ask for a resend
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Failed to send verification email", "target_user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, first_name, last_name, bio, profile_image_url, skills, education_level, years_experience, preferred_role, github_url, linkedin_url, portfolio_url, email_verified, last_active, time_zone, available_hours, certifications, languages, project_preferences, created_at, updated_at
-		FROM users
-		WHERE id = $1
-	`
-	user := &model.User{}
-	var skills, certifications, languages, projectPreferences []string
-	err := database.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
-		pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
-		&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
-		&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
-	}
+	return lookupUser(s.Store.Users().GetByID(ctx, id))
+}
 
-	user.Skills = skills
-	user.Certifications = certifications
-	user.Languages = languages
-	user.ProjectPreferences = projectPreferences
+// GetUsersByIDs returns the users that exist among ids, in no particular order
+func (s *UserService) GetUsersByIDs(ctx context.Context, ids []string) ([]*model.User, error) {
+	return s.Store.Users().ListByIDs(ctx, ids)
+}
 
-	return user, nil
+// lookupUser turns a missing user into the error the API reports
+func lookupUser(user *model.User, err error) (*model.User, error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errors.New("user not found")
+	}
+	return user, err
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, id string, input model.UpdateUserInput) (*model.User, error) {
-	log.Printf("UserService: Updating user with ID: %s", id)
-
 	user, err := s.GetUserByID(ctx, id)
 	if err != nil {
-		log.Printf("UserService: Error getting user by ID: %v", err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -126,8 +113,11 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, input model.Upd
 	if input.Username != nil {
 		user.Username = *input.Username
 	}
-	if input.Email != nil {
+	emailChanged := false
+	if input.Email != nil && *input.Email != user.Email {
 		user.Email = *input.Email
+		user.EmailVerified = false
+		emailChanged = true
 	}
 	if input.FirstName != nil {
 		user.FirstName = *input.FirstName
@@ -178,202 +168,160 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, input model.Upd
 		user.ProjectPreferences = input.ProjectPreferences
 	}
 
-	log.Printf("UserService: User after applying updates: %+v", user)
-
 	// Perform validation
 	if err := validateUser(user); err != nil {
-		log.Printf("UserService: User validation failed: %v", err)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Update in database
-	query := `
-		UPDATE users 
-		SET username = $1, email = $2, first_name = $3, last_name = $4, 
-			bio = $5, profile_image_url = $6, skills = $7, education_level = $8, 
-			years_experience = $9, preferred_role = $10, github_url = $11, 
-			linkedin_url = $12, portfolio_url = $13, time_zone = $14, 
-			available_hours = $15, certifications = $16, languages = $17, 
-			project_preferences = $18, updated_at = $19
-		WHERE id = $20
-	`
-	_, err = s.DB.ExecContext(ctx, query,
-		user.Username, user.Email, user.FirstName, user.LastName,
-		user.Bio, user.ProfileImageURL, pq.Array(user.Skills), user.EducationLevel,
-		user.YearsExperience, user.PreferredRole, user.GithubURL,
-		user.LinkedInURL, user.PortfolioURL, user.TimeZone,
-		user.AvailableHours, pq.Array(user.Certifications), pq.Array(user.Languages),
-		pq.Array(user.ProjectPreferences), time.Now(), id)
-
-	if err != nil {
-		log.Printf("UserService: Error updating user in database: %v", err)
+	user.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := s.Store.Users().Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to update user in database: %w", err)
 	}
 
-	log.Printf("UserService: User updated successfully")
-	return user, nil
-}
-
-func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, first_name, last_name, bio, profile_image_url, skills, education_level, years_experience, preferred_role, github_url, linkedin_url, portfolio_url, email_verified, last_active, time_zone, available_hours, certifications, languages, project_preferences, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
-	user := &model.User{}
-	var skills, certifications, languages, projectPreferences []string
-	err := database.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
-		pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
-		&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
-		&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+	if emailChanged {
+		if err := s.SendVerificationEmail(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "Failed to send verification email", "target_user_id", user.ID, "error", err)
 		}
-		return nil, err
 	}
 
-	user.Skills = skills
-	user.Certifications = certifications
-	user.Languages = languages
-	user.ProjectPreferences = projectPreferences
-
+	slog.InfoContext(ctx, "User updated", "target_user_id", user.ID, "email_changed", emailChanged)
 	return user, nil
 }
 
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return lookupUser(s.Store.Users().GetByEmail(ctx, email))
+}
+
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	err := database.ExecuteQuery(ctx, query, id)
-	return err
+	return s.Store.Users().Delete(ctx, id)
 }
 
 func (s *UserService) VerifyUserEmail(ctx context.Context, id string) error {
-	query := `UPDATE users SET email_verified = true WHERE id = $1`
-	err := database.ExecuteQuery(ctx, query, id)
-	return err
+	return s.Store.Users().SetEmailVerified(ctx, id)
 }
 
-func (s *UserService) UpdateLastActive(ctx context.Context, id string) error {
-	query := `UPDATE users SET last_active = $1 WHERE id = $2`
-	err := database.ExecuteQuery(ctx, query, time.Now().Format(time.RFC3339), id)
-	return err
+// SendVerificationEmail mails the user a signed link that verifies their
+// current email address
+func (s *UserService) SendVerificationEmail(ctx context.Context, user *model.User) error {
+	token, err := auth.GenerateEmailVerificationToken(user.ID, user.Email)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	link := s.AppURL + "/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\n"+
+		"Please confirm your email address for Projectivity by opening the link below:\n\n"+
+		"%s\n\n"+
+		"The link expires in %d hours. If you did not create an account, you can ignore this email.\n",
+		user.FirstName, link, int(auth.EmailVerificationTTL.Hours()))
+
+	return s.Mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Projectivity email address",
+		Body:    body,
+	})
 }
 
-func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*model.User, error) {
-	// Ensure limit is positive
-	if limit <= 0 {
-		limit = 10 // Default to 10 if limit is 0 or negative
+// VerifyEmail marks the user's email as verified using a token from a
+// verification email
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	userID, email, err := auth.ParseEmailVerificationToken(token)
+	if err != nil {
+		return nil, err
 	}
 
-	// Ensure offset is non-negative
-	if offset < 0 {
-		offset = 0
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, auth.ErrInvalidVerificationToken
 	}
 
-	query := `
-		SELECT id, username, email, first_name, last_name, bio, profile_image_url, skills, education_level, years_experience, preferred_role, github_url, linkedin_url, portfolio_url, email_verified, last_active, time_zone, available_hours, certifications, languages, project_preferences, created_at, updated_at
-		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := database.Query(ctx, query, limit, offset)
-	if err != nil {
-		return nil, err
+	// The token was issued for an address the user no longer uses
+	if !strings.EqualFold(user.Email, email) {
+		return nil, auth.ErrInvalidVerificationToken
 	}
-	defer rows.Close()
-
-	var users []*model.User
-	for rows.Next() {
-		user := &model.User{}
-		var skills, certifications, languages, projectPreferences []string
-		err := rows.Scan(
-			&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
-			pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
-			&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
-			&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
-			&user.CreatedAt, &user.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning user: %w", err)
+
+	if !user.EmailVerified {
+		if err := s.VerifyUserEmail(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to verify email: %w", err)
 		}
-		user.Skills = skills
-		user.Certifications = certifications
-		user.Languages = languages
-		user.ProjectPreferences = projectPreferences
-		users = append(users, user)
+		user.EmailVerified = true
 	}
 
-	return users, nil
+	return user, nil
 }
 
-func (s *UserService) SearchUsers(ctx context.Context, query string) ([]*model.User, error) {
-	sqlQuery := `
-		SELECT id, username, email, first_name, last_name, bio, profile_image_url, skills, education_level, years_experience, preferred_role, github_url, linkedin_url, portfolio_url, email_verified, last_active, time_zone, available_hours, certifications, languages, project_preferences, created_at, updated_at
-		FROM users
-		WHERE username ILIKE $1 OR email ILIKE $1 OR first_name ILIKE $1 OR last_name ILIKE $1 OR skills @> ARRAY[$1] OR project_preferences @> ARRAY[$1]
-	`
-	rows, err := database.Query(ctx, sqlQuery, "%"+query+"%")
+// ResendVerification sends a fresh verification email to an unverified user
+func (s *UserService) ResendVerification(ctx context.Context, userID string) error {
+	user, err := s.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-
-	var users []*model.User
-	for rows.Next() {
-		user := &model.User{}
-		var skills, certifications, languages, projectPreferences []string
-		err := rows.Scan(
-			&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
-			pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
-			&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
-			&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
-			&user.CreatedAt, &user.UpdatedAt,
-		)
-		if err != nil {
+	if user.EmailVerified {
+		return errors.New("email is already verified")
+	}
+
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+func (s *UserService) UpdateLastActive(ctx context.Context, id string) error {
+	return s.Store.Users().UpdateLastActive(ctx, id)
+}
+
+// ListUsers returns a page of users. The limit and offset are expected to
+// have been checked with pagination.Offset.
+func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*model.User, error) {
+	return s.Store.Users().List(ctx, limit, offset)
+}
+
+// ListUsersConnection returns a page of users. The total is only counted
+// when withTotal is set.
+func (s *UserService) ListUsersConnection(ctx context.Context, args pagination.Args, withTotal bool) (*model.UserConnection, error) {
+	page, err := pagination.Fetch(args, func(p repository.Page) ([]*model.User, error) {
+		return s.Store.Users().ListPage(ctx, p)
+	}, func(u *model.User) repository.Cursor {
+		return repository.Cursor{CreatedAt: u.CreatedAt, ID: u.ID}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+
+	conn := &model.UserConnection{Edges: []*model.UserEdge{}, PageInfo: page.PageInfo}
+	for i, user := range page.Items {
+		conn.Edges = append(conn.Edges, &model.UserEdge{Cursor: page.Cursors[i], Node: user})
+	}
+	if withTotal {
+		if conn.TotalCount, err = s.Store.Users().Count(ctx); err != nil {
 			return nil, err
 		}
-		user.Skills = skills
-		user.Certifications = certifications
-		user.Languages = languages
-		user.ProjectPreferences = projectPreferences
-		users = append(users, user)
 	}
+	return conn, nil
+}
 
-	return users, nil
+func (s *UserService) SearchUsers(ctx context.Context, query string) ([]*model.User, error) {
+	return s.Store.Users().Search(ctx, query)
 }
 
+// GetUserProjects returns the projects the user is a team member of
 func (s *UserService) GetUserProjects(ctx context.Context, userID string) ([]*model.Project, error) {
-	query := `
-		SELECT p.id, p.title, p.description, p.category, p.status, p.technologies, p.open_positions, p.time_commitment, p.popularity, p.timeline, p.learning_objectives, p.created_at, p.updated_at
-		FROM projects p
-		JOIN team_members tm ON p.id = tm.project_id
-		WHERE tm.user_id = $1
-	`
-	rows, err := database.Query(ctx, query, userID)
+	viewer, err := currentViewer(ctx, s.Store)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return s.Store.Projects().ListByMember(ctx, userID, viewer)
+}
 
-	var projects []*model.Project
-	for rows.Next() {
-		project := &model.Project{}
-		err := rows.Scan(
-			&project.ID, &project.Title, &project.Description, &project.Category, &project.Status,
-			&project.Technologies, &project.OpenPositions, &project.TimeCommitment, &project.Popularity,
-			&project.Timeline, &project.LearningObjectives, &project.CreatedAt, &project.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		projects = append(projects, project)
+// GetProjectsByMembers returns, for each user, the projects they are a team
+// member of
+func (s *UserService) GetProjectsByMembers(ctx context.Context, userIDs []string) (map[string][]*model.Project, error) {
+	viewer, err := currentViewer(ctx, s.Store)
+	if err != nil {
+		return nil, err
 	}
-
-	return projects, nil
+	return s.Store.Projects().ListByMembers(ctx, userIDs, viewer)
 }
 
 func (s *UserService) UpdateUserPassword(ctx context.Context, userID, newPassword string) error {
@@ -382,69 +330,37 @@ func (s *UserService) UpdateUserPassword(ctx context.Context, userID, newPasswor
 		return err
 	}
 
-	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
-	err = database.ExecuteQuery(ctx, query, hashedPassword, userID)
-	return err
+	return s.Store.Users().SetPasswordHash(ctx, userID, hashedPassword)
 }
 
 func (s *UserService) AddUserSkill(ctx context.Context, userID, skill string) error {
-	query := `UPDATE users SET skills = array_append(skills, $1) WHERE id = $2`
-	err := database.ExecuteQuery(ctx, query, skill, userID)
-	return err
+	return s.Store.Users().AddSkill(ctx, userID, skill)
 }
 
 func (s *UserService) RemoveUserSkill(ctx context.Context, userID, skill string) error {
-	query := `UPDATE users SET skills = array_remove(skills, $1) WHERE id = $2`
-	err := database.ExecuteQuery(ctx, query, skill, userID)
-	return err
+	return s.Store.Users().RemoveSkill(ctx, userID, skill)
 }
 
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
-	query := `
-		SELECT id, username, email, first_name, last_name, bio, profile_image_url, skills, education_level, years_experience, preferred_role, github_url, linkedin_url, portfolio_url, email_verified, last_active, time_zone, available_hours, certifications, languages, project_preferences, created_at, updated_at
-		FROM users
-		WHERE username = $1
-	`
-	user := &model.User{}
-	var skills, certifications, languages, projectPreferences []string
-	err := database.QueryRow(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.FirstName, &user.LastName, &user.Bio, &user.ProfileImageURL,
-		pq.Array(&skills), &user.EducationLevel, &user.YearsExperience, &user.PreferredRole, &user.GithubURL,
-		&user.LinkedInURL, &user.PortfolioURL, &user.EmailVerified, &user.LastActive, &user.TimeZone,
-		&user.AvailableHours, pq.Array(&certifications), pq.Array(&languages), pq.Array(&projectPreferences),
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
-	}
-
-	user.Skills = skills
-	user.Certifications = certifications
-	user.Languages = languages
-	user.ProjectPreferences = projectPreferences
-
-	return user, nil
+	return lookupUser(s.Store.Users().GetByUsername(ctx, username))
 }
 
 func (s *UserService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) (*model.User, error) {
-	var storedPasswordHash string
+	if err := validatePassword(newPassword); err != nil {
+		return nil, err
+	}
 
 	// First, retrieve the current password hash
-	query := `SELECT password_hash FROM users WHERE id = $1`
-	err := s.DB.QueryRowContext(ctx, query, userID).Scan(&storedPasswordHash)
+	storedPasswordHash, err := s.Store.Users().PasswordHash(ctx, userID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, repository.ErrNotFound) {
 			return nil, errors.New("user not found")
 		}
 		return nil, fmt.Errorf("failed to retrieve user: %w", err)
 	}
 
 	// Verify the old password
-	err = bcrypt.CompareHashAndPassword([]byte(storedPasswordHash), []byte(oldPassword))
+	err = bcrypt.CompareHashAndPassword(storedPasswordHash, []byte(oldPassword))
 	if err != nil {
 		return nil, errors.New("incorrect old password")
 	}
@@ -456,42 +372,177 @@ func (s *UserService) ChangePassword(ctx context.Context, userID, oldPassword, n
 	}
 
 	// Update the password in the database
-	updateQuery := `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`
-	_, err = s.DB.ExecContext(ctx, updateQuery, newPasswordHash, time.Now().UTC(), userID)
+	err = s.Store.WithTx(ctx, func(tx repository.Store) error {
+		if err := tx.Users().SetPasswordHash(ctx, userID, newPasswordHash); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		// Sign out everywhere else but keep the session that made the change
+		if err := tx.Sessions().RevokeAll(ctx, userID, auth.GetSessionIDFromContext(ctx)); err != nil {
+			return fmt.Errorf("failed to revoke other sessions: %w", err)
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionPasswordChange,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update password: %w", err)
+		return nil, err
 	}
 
 	// Retrieve and return the updated user
 	return s.GetUserByID(ctx, userID)
 }
 
-func (s *UserService) LoginUser(ctx context.Context, email, password string) (string, error) {
+func (s *UserService) LoginUser(ctx context.Context, email, password string) (*LoginResult, error) {
+	clientIP := auth.GetClientIPFromContext(ctx)
+
+	// Refuse early if this account or client has failed too often
+	if err := s.Throttle.Check(ctx, email, clientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.GetUserByEmail(ctx, email)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		s.recordLoginFailure(ctx, "", email, clientIP)
+		return nil, errors.New("invalid email or password")
 	}
 
 	// Fetch the password hash from the database
-	var storedPasswordHash string
-	err = s.DB.QueryRowContext(ctx, "SELECT password_hash FROM users WHERE id = $1", user.ID).Scan(&storedPasswordHash)
+	storedPasswordHash, err := s.Store.Users().PasswordHash(ctx, user.ID)
 	if err != nil {
-		return "", fmt.Errorf("failed to retrieve password hash: %w", err)
+		return nil, fmt.Errorf("failed to retrieve password hash: %w", err)
 	}
 
 	// Verify the password
-	err = bcrypt.CompareHashAndPassword([]byte(storedPasswordHash), []byte(password))
+	err = bcrypt.CompareHashAndPassword(storedPasswordHash, []byte(password))
+	if err != nil {
+		s.recordLoginFailure(ctx, user.ID, email, clientIP)
+		return nil, errors.New("invalid email or password")
+	}
+
+	// Suspension is only revealed to someone who knows the password
+	if err := s.checkUserActive(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	// With two-factor authentication the password only earns a challenge;
+	// the throttle is reset once the second factor is verified too
+	twoFactor, err := s.TwoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor {
+		challenge, expiresAt, err := auth.GenerateTwoFactorChallenge(user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create two-factor challenge: %w", err)
+		}
+		return &LoginResult{ChallengeToken: challenge, ChallengeExpiresAt: expiresAt}, nil
+	}
+
+	if err := s.Throttle.RecordSuccess(ctx, email); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset login throttle", "error", err)
+	}
+
+	// Start a session and issue the access/refresh token pair
+	tokens, err := s.startSession(ctx, user.ID, "password")
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// UnlockAccount clears failed login attempts and any lockout for an email.
+// Unlocking an existing account is written to the audit log.
+func (s *UserService) UnlockAccount(ctx context.Context, email string) error {
+	user, err := s.Store.Users().GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("failed to look up account: %w", err)
+	}
+
+	if err := s.Throttle.Unlock(ctx, email); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	err = s.Store.RecordAudit(ctx, audit.Event{
+		Action:     audit.ActionUserUnlock,
+		TargetType: audit.TargetUser,
+		TargetID:   user.ID,
+	})
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		return fmt.Errorf("failed to record account unlock: %w", err)
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt towards the login throttle and,
+// for existing accounts, writes it to the audit log. userID is empty when
+// the email does not belong to any account.
+func (s *UserService) recordLoginFailure(ctx context.Context, userID, email, clientIP string) {
+	if err := s.Throttle.RecordFailure(ctx, email, clientIP); err != nil {
+		slog.ErrorContext(ctx, "Failed to record login failure", "error", err)
+	}
+
+	if userID == "" {
+		return
+	}
+	err := s.Store.RecordAudit(ctx, audit.Event{
+		Action:     audit.ActionLoginFailed,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record failed login in the audit log", "error", err)
+	}
+}
+
+// checkUserActive returns auth.ErrUserSuspended when the user has been
+// suspended by a moderator or no longer exists
+func (s *UserService) checkUserActive(ctx context.Context, userID string) error {
+	suspendedAt, err := s.Store.Users().SuspendedAt(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return auth.ErrUserSuspended
+	} else if err != nil {
+		return fmt.Errorf("failed to check account status: %w", err)
 	}
 
-	// Generate a JWT token
-	token, err := auth.GenerateToken(user.ID)
+	if suspendedAt != nil {
+		return auth.ErrUserSuspended
+	}
+	return nil
+}
+
+// startSession logs a user in and records the login in the same transaction
+func (s *UserService) startSession(ctx context.Context, userID, method string) (*auth.TokenPair, error) {
+	var tokens *auth.TokenPair
+
+	err := s.Store.WithTx(ctx, func(tx repository.Store) error {
+		var err error
+		tokens, err = auth.CreateSessionIn(ctx, tx.Sessions(), userID)
+		if err != nil {
+			return err
+		}
+
+		return tx.RecordAudit(ctx, audit.Event{
+			Action:     audit.ActionLogin,
+			ActorID:    userID,
+			TargetType: audit.TargetUser,
+			TargetID:   userID,
+			After:      map[string]interface{}{"sessionId": tokens.SessionID, "method": method},
+		})
+	})
+
 	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	return token, nil
+	return tokens, nil
 }
 
 func (s *UserService) LogoutUser(ctx context.Context, userID string) error {
@@ -501,9 +552,14 @@ func (s *UserService) LogoutUser(ctx context.Context, userID string) error {
 		return fmt.Errorf("failed to update last active time: %w", err)
 	}
 
-	// Invalidate the token
+	// Invalidate the token the caller authenticated with
 	token := auth.GetTokenFromContext(ctx)
-	auth.InvalidateToken(token)
+	if token == "" {
+		return errors.New("no token found in request context")
+	}
+	if err := auth.InvalidateToken(ctx, token); err != nil {
+		return fmt.Errorf("failed to invalidate token: %w", err)
+	}
 
 	return nil
 }
@@ -593,3 +649,21 @@ func validateUser(user *model.User) error {
 	// Add more validation rules as needed
 	return nil
 }
+
+// GetUserRole returns the site-wide role of a user
+func (s *UserService) GetUserRole(ctx context.Context, userID string) (model.UserRole, error) {
+	role, err := s.Store.Users().Role(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user role: %w", err)
+	}
+	return role, nil
+}
+
+// GetUserSuspendedAt returns when a user was suspended, or nil
+func (s *UserService) GetUserSuspendedAt(ctx context.Context, userID string) (*string, error) {
+	suspendedAt, err := s.Store.Users().SuspendedAt(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user suspension: %w", err)
+	}
+	return suspendedAt, nil
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evan3v4n/Projectivity/backend/go/graph/model"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/audit"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+)
+
+func TestCreateUserSendsVerificationEmail(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	user := env.createUser(t, "ada")
+	if user.ID == "" {
+		t.Fatal("user has no ID")
+	}
+	if user.EmailVerified {
+		t.Error("new user is already verified")
+	}
+
+	sent := env.mailer.sent()
+	if len(sent) != 1 || sent[0].To != "ada@example.com" {
+		t.Fatalf("sent = %+v, want one verification email", sent)
+	}
+
+	token := verificationToken(t, sent[0].Body)
+	verified, err := env.users.VerifyEmail(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.EmailVerified {
+		t.Error("VerifyEmail did not mark the user verified")
+	}
+
+	stored, err := env.users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.EmailVerified {
+		t.Error("verification was not saved")
+	}
+}
+
+func TestCreateUserRejectsDuplicateEmail(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ada")
+
+	_, err := env.users.CreateUser(context.Background(), model.CreateUserInput{
+		Username:  "other",
+		Email:     "ada@example.com",
+		Password:  "another password",
+		FirstName: "Other",
+		LastName:  "User",
+	})
+	if err == nil {
+		t.Fatal("CreateUser accepted a duplicate email")
+	}
+}
+
+func TestGetUserLookups(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	byEmail, err := env.users.GetUserByEmail(ctx, "ada@example.com")
+	if err != nil || byEmail.ID != user.ID {
+		t.Errorf("GetUserByEmail = %+v, %v", byEmail, err)
+	}
+	byName, err := env.users.GetUserByUsername(ctx, "ada")
+	if err != nil || byName.ID != user.ID {
+		t.Errorf("GetUserByUsername = %+v, %v", byName, err)
+	}
+
+	if _, err := env.users.GetUserByID(ctx, "missing"); err == nil || err.Error() != "user not found" {
+		t.Errorf("GetUserByID(missing) err = %v, want user not found", err)
+	}
+}
+
+func TestUpdateUserEmailResetsVerification(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+	if err := env.users.VerifyUserEmail(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	email := "lovelace@example.com"
+	bio := "Analyst"
+	updated, err := env.users.UpdateUser(ctx, user.ID, model.UpdateUserInput{Email: &email, Bio: &bio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.EmailVerified {
+		t.Error("changing the email kept it verified")
+	}
+
+	stored, err := env.users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Email != email || stored.Bio == nil || *stored.Bio != bio {
+		t.Errorf("stored = %+v", stored)
+	}
+
+	sent := env.mailer.sent()
+	if len(sent) != 2 || sent[1].To != email {
+		t.Errorf("sent = %+v, want a second email to the new address", sent)
+	}
+
+	empty := ""
+	if _, err := env.users.UpdateUser(ctx, user.ID, model.UpdateUserInput{FirstName: &empty}); err == nil {
+		t.Error("UpdateUser accepted an empty first name")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	current, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = auth.SetSessionID(ctx, current.Tokens.SessionID)
+
+	if _, err := env.users.ChangePassword(ctx, user.ID, "correct horse battery staple", "short"); err == nil {
+		t.Fatal("ChangePassword accepted a short password")
+	}
+	if _, err := env.users.ChangePassword(ctx, user.ID, "wrong", "new password"); err == nil {
+		t.Fatal("ChangePassword accepted the wrong old password")
+	}
+	if env.hasAudit(audit.ActionPasswordChange) {
+		t.Fatal("failed password change was audited")
+	}
+
+	if _, err := env.users.ChangePassword(ctx, user.ID, "correct horse battery staple", "new password"); err != nil {
+		t.Fatal(err)
+	}
+	if !env.hasAudit(audit.ActionPasswordChange) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionPasswordChange)
+	}
+
+	// Only the session that changed the password stays signed in
+	for _, session := range []struct {
+		id   string
+		want bool
+	}{{current.Tokens.SessionID, true}, {other.Tokens.SessionID, false}} {
+		active, err := auth.IsSessionActive(ctx, session.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active != session.want {
+			t.Errorf("session %s active = %v, want %v", session.id, active, session.want)
+		}
+	}
+
+	if _, err := env.users.ChangePassword(ctx, user.ID, "new password", "newer password"); err != nil {
+		t.Errorf("new password was not saved: %v", err)
+	}
+}
+
+func TestUserSkills(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	if err := env.users.AddUserSkill(ctx, user.ID, "rust"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.users.RemoveUserSkill(ctx, user.ID, "go"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := env.users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(stored.Skills, ",") != "rust" {
+		t.Errorf("skills = %v, want [rust]", stored.Skills)
+	}
+}
+
+func TestListAndSearchUsers(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.createUser(t, "ada")
+	env.createUser(t, "grace")
+	env.createUser(t, "alan")
+
+	users, err := env.users.ListUsers(ctx, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Username != "alan" {
+		t.Errorf("ListUsers = %v, want the two newest users", usernames(users))
+	}
+
+	found, err := env.users.SearchUsers(ctx, "GRA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Username != "grace" {
+		t.Errorf("SearchUsers = %v, want [grace]", usernames(found))
+	}
+}
+
+func TestUserRoleDefaults(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	role, err := env.users.GetUserRole(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != model.UserRoleUser {
+		t.Errorf("role = %s, want %s", role, model.UserRoleUser)
+	}
+
+	suspendedAt, err := env.users.GetUserSuspendedAt(ctx, user.ID)
+	if err != nil || suspendedAt != nil {
+		t.Errorf("suspendedAt = %v, %v, want nil", suspendedAt, err)
+	}
+}
+
+// verificationToken extracts the token from the link in a verification email
+func verificationToken(t *testing.T, body string) string {
+	t.Helper()
+
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(line, "http") {
+			continue
+		}
+		link, err := url.Parse(strings.TrimSpace(line))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return link.Query().Get("token")
+	}
+	t.Fatalf("no link in email body %q", body)
+	return ""
+}
+
+func usernames(users []*model.User) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Username
+	}
+	return names
+}
+
+func TestLoginUser(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	result, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tokens == nil || result.Tokens.AccessToken == "" || result.Tokens.RefreshToken == "" {
+		t.Fatalf("LoginUser = %+v, want a token pair", result)
+	}
+
+	sessions, err := auth.ListSessions(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != result.Tokens.SessionID {
+		t.Errorf("sessions = %+v, want the new session", sessions)
+	}
+
+	if _, err := env.users.LoginUser(ctx, "ada@example.com", "wrong password"); err == nil {
+		t.Error("LoginUser accepted a wrong password")
+	}
+	if _, err := env.users.LoginUser(ctx, "nobody@example.com", "correct horse battery staple"); err == nil {
+		t.Error("LoginUser accepted an unknown email")
+	}
+}
+
+func TestLoginUserThrottlesRepeatedFailures(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	env.createUser(t, "ada")
+
+	for i := 0; i < env.users.Throttle.Account.FreeAttempts+1; i++ {
+		if _, err := env.users.LoginUser(ctx, "ada@example.com", "wrong password"); err == nil {
+			t.Fatal("LoginUser accepted a wrong password")
+		}
+	}
+
+	// Even the right password has to wait for the backoff
+	_, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("err = %v, want a LoginLockedError", err)
+	}
+
+	if err := env.users.UnlockAccount(ctx, "ada@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !env.hasAudit(audit.ActionUserUnlock) {
+		t.Errorf("audit = %v, want %s", env.auditActions(), audit.ActionUserUnlock)
+	}
+	if _, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple"); err != nil {
+		t.Errorf("LoginUser after unlock: %v", err)
+	}
+}
+
+func TestLoginUserRefusesSuspendedUsers(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	user := env.createUser(t, "ada")
+
+	if err := env.store.Users().Suspend(ctx, user.ID, "spam"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := env.users.LoginUser(ctx, "ada@example.com", "correct horse battery staple")
+	if !errors.Is(err, auth.ErrUserSuspended) {
+		t.Errorf("err = %v, want %v", err, auth.ErrUserSuspended)
+	}
+
+	// Suspension is not revealed without the password
+	_, err = env.users.LoginUser(ctx, "ada@example.com", "wrong password")
+	if err == nil || errors.Is(err, auth.ErrUserSuspended) {
+		t.Errorf("err = %v, want invalid credentials", err)
+	}
+}
@@ -1,84 +1,147 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/evan3v4n/Projectivity/backend/go/graph"
 	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/config"
 	"github.com/evan3v4n/Projectivity/backend/go/internal/database"
-	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
-	"github.com/go-chi/chi"
-	"github.com/rs/cors"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/logging"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/tracing"
 )
 
-const defaultPort = "8080"
-
 func main() {
-	// Initialize database
-	dbConfig := database.Config{
-		Host:     "localhost",
-		Port:     5432,
-		User:     "projectivity_user",
-		Password: PASSWORD,
-		DBName:   "projectivity",
-		SSLMode:  "disable",
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	// Output from the standard log package goes through this logger as well
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format))
 
-	db, err := database.Initialize(dbConfig)
+	// Set up tracing before opening the pool, whose queries are traced
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.OTLPEndpoint, cfg.Tracing.SampleRatio)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	// Initialize database
+	db, err := openDatabase(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	if err := auth.InitJWTSecretKey(); err != nil {
-		log.Fatalf("Failed to initialize JWT secret key: %v", err)
+	if cfg.Database.AutoMigrate {
+		if err := migrateUp(context.Background(), db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
 	}
 
-	// Initialize services
-	userService := services.NewUserService(db)
-	taskService := services.NewTaskService(db)
-	teamService := services.NewTeamService(db)
-	projectService := services.NewProjectService(db, userService)
-	joinRequestService := services.NewJoinRequestService(db)
-
-	// Create resolver with services
-	resolver := &graph.Resolver{
-		ProjectService:     projectService,
-		UserService:        userService,
-		TaskService:        taskService,
-		TeamService:        teamService,
-		JoinRequestService: joinRequestService,
+	if err := auth.InitJWTKeys(cfg.JWT.SigningKeyFile, cfg.JWT.VerificationKeysDir, cfg.JWT.DevEphemeralKey); err != nil {
+		log.Fatalf("Failed to initialize JWT keys: %v", err)
 	}
 
-	// Create a new router
-	router := chi.NewRouter()
+	// Initialize the mailer; without an SMTP relay, mail is written to a local outbox
+	var mailer mail.Mailer
+	if cfg.Mail.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	} else {
+		slog.Info("No SMTP host configured, writing outgoing mail to the outbox", "outbox_dir", cfg.Mail.OutboxDir)
+		mailer = mail.NewOutboxMailer(cfg.Mail.OutboxDir, cfg.Mail.From)
+	}
 
-	// Setup CORS
-	corsMiddleware := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"}, // Add your frontend URL here
-		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		AllowCredentials: true,
-	})
-	router.Use(corsMiddleware.Handler)
+	router, err := newRouter(cfg, db, mailer)
+	if err != nil {
+		log.Fatalf("Failed to build router: %v", err)
+	}
 
-	// Create GraphQL handler
-	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
 
-	// Setup routes
-	router.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	router.Handle("/query", auth.AuthMiddleware(srv))
+	// Stop accepting connections on SIGINT or SIGTERM and let in-flight
+	// requests finish before closing the pool
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Get port from environment variable or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", server.Addr, err)
 	}
 
-	log.Printf("Connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	slog.Info(fmt.Sprintf("Connect to http://localhost:%d/ for GraphQL playground", cfg.Server.Port))
+	err = serve(ctx, server, listener, cfg.Server.ShutdownTimeout)
+	db.Close()
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	cancel()
+	if err != nil {
+		log.Fatalf("Server stopped: %v", err)
+	}
+}
+
+// serve runs server on listener until it fails or ctx is cancelled, then
+// shuts it down, giving open requests up to timeout to complete
+func serve(ctx context.Context, server *http.Server, listener net.Listener, timeout time.Duration) error {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down, waiting for open requests", "timeout", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+	if err := <-serverErr; err != http.ErrServerClosed {
+		return err
+	}
+	slog.Info("Server shut down cleanly")
+	return nil
+}
+
+// openDatabase connects to Postgres with the configured pool settings
+func openDatabase(cfg config.DatabaseConfig) (*sql.DB, error) {
+	return database.Initialize(database.Config{
+		URL:             cfg.URL,
+		Host:            cfg.Host,
+		Port:            cfg.Port,
+		User:            cfg.User,
+		Password:        cfg.Password,
+		DBName:          cfg.Name,
+		SSLMode:         cfg.SSLMode,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+		ConnectTimeout:  cfg.ConnectTimeout,
+	})
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/evan3v4n/Projectivity/backend/go/internal/config"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/migrate"
+)
+
+const migrateUsage = `Usage: %s migrate <command> [flags]
+
+Commands:
+  up                 apply all pending migrations
+  down [-steps N]    revert the last N applied migrations (default 1)
+  status             list migrations and whether they have been applied
+  create [-dir DIR] NAME
+                     write a new empty up/down migration pair
+`
+
+// runMigrate implements the migrate subcommand and returns the exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return 2
+	}
+
+	command, args := args[0], args[1:]
+	if command == "create" {
+		return runMigrateCreate(args)
+	}
+
+	fs := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to revert")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("Failed to load configuration: %v", err)
+		return 1
+	}
+
+	db, err := openDatabase(cfg.Database)
+	if err != nil {
+		log.Printf("Failed to initialize database: %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = migrateUp(ctx, db)
+	case "down":
+		err = migrateDown(ctx, db, *steps)
+	case "status":
+		err = migrateStatus(ctx, db)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown migrate command %q\n\n", command)
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return 2
+	}
+	if err != nil {
+		log.Printf("Migration failed: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func runMigrateCreate(args []string) int {
+	fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := fs.String("dir", "internal/migrate/migrations", "directory holding the migration files")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		return 2
+	}
+
+	up, down, err := migrate.Create(*dir, fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to create migration: %v", err)
+		return 1
+	}
+	fmt.Printf("Created %s\nCreated %s\n", up, down)
+	return 0
+}
+
+func migrateUp(ctx context.Context, db *sql.DB) error {
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	applied, err := m.Up(ctx)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Printf("Database schema is up to date")
+	}
+	return nil
+}
+
+func migrateDown(ctx context.Context, db *sql.DB, steps int) error {
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	reverted, err := m.Down(ctx, steps)
+	if err != nil {
+		return err
+	}
+	if len(reverted) == 0 {
+		log.Printf("No migrations to revert")
+	}
+	return nil
+}
+
+func migrateStatus(ctx context.Context, db *sql.DB) error {
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = "applied " + s.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, applied)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/evan3v4n/Projectivity/backend/go/graph"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/auth"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/config"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/loaders"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/logging"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/mail"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/metrics"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/repository/postgres"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/services"
+	"github.com/evan3v4n/Projectivity/backend/go/internal/tracing"
+	"github.com/go-chi/chi"
+	"github.com/rs/cors"
+)
+
+// newRouter wires the services into the GraphQL handler and mounts it with
+// the rest of the HTTP routes. The end-to-end tests serve the same router.
+func newRouter(cfg *config.Config, db *sql.DB, mailer mail.Mailer) (http.Handler, error) {
+	health, err := newHealthHandler(db)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies, err := auth.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize services
+	store := postgres.NewStore(db)
+	userService := services.NewUserService(store, mailer, cfg.AppBaseURL)
+	taskService := services.NewTaskService(store)
+	teamService := services.NewTeamService(store)
+	projectService := services.NewProjectService(store, userService)
+	joinRequestService := services.NewJoinRequestService(store)
+	accessTokenService := services.NewAccessTokenService(store)
+	moderationService := services.NewModerationService(store, userService, projectService)
+	auth.SetSessionStore(store.Sessions())
+
+	// Create resolver with services
+	resolver := &graph.Resolver{
+		Store:              store,
+		ProjectService:     projectService,
+		UserService:        userService,
+		TaskService:        taskService,
+		TeamService:        teamService,
+		JoinRequestService: joinRequestService,
+		AccessTokenService: accessTokenService,
+		ModerationService:  moderationService,
+	}
+
+	// Create a new router
+	router := chi.NewRouter()
+
+	// Trace first so the span covers the other middleware
+	router.Use(tracing.Middleware)
+	// Take the client IP from X-Forwarded-For / X-Real-IP, but only when the
+	// load balancer set them
+	router.Use(proxies.RealIP)
+	router.Use(logging.RequestID)
+	router.Use(metrics.Middleware)
+
+	// Setup CORS
+	corsMiddleware := cors.New(cors.Options{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", logging.RequestIDHeader},
+		ExposedHeaders:   []string{logging.RequestIDHeader},
+		AllowCredentials: true,
+	})
+	router.Use(corsMiddleware.Handler)
+
+	// Create GraphQL handler
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  resolver,
+		Directives: graph.NewDirectiveRoot(store),
+	}))
+	srv.AroundOperations(graph.LogOperation)
+	srv.Use(graph.Metrics{})
+	srv.Use(graph.Tracing{})
+	metrics.RegisterDB(db)
+
+	// Setup routes
+	router.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	// Nested fields are batched through loaders created fresh for every request
+	withLoaders := loaders.Middleware(userService, projectService, teamService)
+	router.Handle("/query", auth.AuthMiddleware(withLoaders(srv)))
+	router.Method(http.MethodGet, "/.well-known/jwks.json", auth.JWKSHandler())
+	router.Get("/healthz", health.Live)
+	router.Get("/readyz", health.Ready)
+	router.Method(http.MethodGet, "/metrics", metrics.Handler())
+
+	return router, nil
+}
@@ -46,7 +46,10 @@ export default function AuthPage() {
   const [isSkillsOpen, setIsSkillsOpen] = useState(false)
   const skillsRef = useRef(null)
   const router = useRouter();
-  const { login } = useAuth();
+  const { login, verifyTwoFactor } = useAuth();
+  const [twoFactorChallenge, setTwoFactorChallenge] = useState<string | null>(null)
+  const [twoFactorCode, setTwoFactorCode] = useState('')
+  const [twoFactorError, setTwoFactorError] = useState<string | null>(null)
   const [createUser, { loading: createUserLoading, error: createUserError }] = useMutation(CREATE_USER);
   const [loginUser, { loading: loginUserLoading, error: loginUserError }] = useMutation(LOGIN_USER);
 
@@ -77,10 +80,19 @@ export default function AuthPage() {
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault()
-    if (isLogin) {
+    if (isLogin && twoFactorChallenge) {
       try {
-        await login(loginData.email, loginData.password);
-        // The router.push is now handled in the login function
+        setTwoFactorError(null)
+        await verifyTwoFactor(twoFactorChallenge, twoFactorCode);
+      } catch (error) {
+        setTwoFactorError(error.message)
+      }
+    } else if (isLogin) {
+      try {
+        const challenge = await login(loginData.email, loginData.password);
+        // The router.push is handled in the login function unless a
+        // two-factor code is still needed
+        setTwoFactorChallenge(challenge)
       } catch (error) {
         console.error('Error logging in:', error);
       }
@@ -155,7 +167,25 @@ export default function AuthPage() {
           </p>
         </div>
         <form onSubmit={handleSubmit} className="mt-8 space-y-6">
-          {isLogin ? (
+          {isLogin && twoFactorChallenge ? (
+            <div>
+              <Label htmlFor="two-factor-code">Authentication code</Label>
+              <Input
+                id="two-factor-code"
+                name="twoFactorCode"
+                type="text"
+                inputMode="numeric"
+                autoComplete="one-time-code"
+                required
+                className="mt-1 block w-full"
+                value={twoFactorCode}
+                onChange={(e) => setTwoFactorCode(e.target.value)}
+              />
+              <p className="mt-2 text-sm text-gray-600">
+                Enter the 6-digit code from your authenticator app, or one of your recovery codes.
+              </p>
+            </div>
+          ) : isLogin ? (
             <>
               <div className="rounded-md space-y-4">
                 <div>
@@ -200,7 +230,7 @@ export default function AuthPage() {
                 </div>
 
                 <div className="text-sm">
-                  <Link href="/forgot-password" className="font-medium text-purple-600 hover:text-purple-500">
+                  <Link href="/reset-password" className="font-medium text-purple-600 hover:text-purple-500">
                     Forgot your password?
                   </Link>
                 </div>
@@ -375,6 +405,9 @@ export default function AuthPage() {
         {loginUserError && (
           <p className="text-red-500 text-sm mt-2">Error: {loginUserError.message}</p>
         )}
+        {twoFactorError && (
+          <p className="text-red-500 text-sm mt-2">Error: {twoFactorError}</p>
+        )}
         {createUserError && (
           <p className="text-red-500 text-sm mt-2">Error: {createUserError.message}</p>
         )}
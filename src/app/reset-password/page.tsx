@@ -0,0 +1,168 @@
+'use client'
+
+import { Suspense, useState } from 'react'
+import Link from 'next/link'
+import { useSearchParams } from 'next/navigation'
+import { useMutation } from '@apollo/client'
+import { CheckCircle, Loader2 } from 'lucide-react'
+import { Button } from "@/components/ui/button"
+import { Input } from "@/components/ui/input"
+import { Label } from "@/components/ui/label"
+import { REQUEST_PASSWORD_RESET, RESET_PASSWORD } from '@/graphql/queries'
+
+// RequestReset asks for a reset link. The server answers the same way whether
+// or not the email belongs to an account.
+function RequestReset() {
+  const [email, setEmail] = useState('')
+  const [sent, setSent] = useState(false)
+  const [requestPasswordReset, { loading, error }] = useMutation(REQUEST_PASSWORD_RESET)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    try {
+      await requestPasswordReset({ variables: { email } })
+      setSent(true)
+    } catch (err) {
+      console.error('Error requesting password reset:', err)
+    }
+  }
+
+  if (sent) {
+    return (
+      <div className="text-center space-y-4">
+        <CheckCircle className="mx-auto h-12 w-12 text-green-600" />
+        <h2 className="text-2xl font-bold text-gray-900">Check your email</h2>
+        <p className="text-sm text-gray-600">
+          If an account uses {email}, we've sent it a link to choose a new password.
+        </p>
+      </div>
+    )
+  }
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-6">
+      <div>
+        <h2 className="text-center text-3xl font-bold text-gray-900">Forgot your password?</h2>
+        <p className="mt-2 text-center text-sm text-gray-600">
+          Enter your email address and we'll send you a link to reset it.
+        </p>
+      </div>
+      <div>
+        <Label htmlFor="email">Email address</Label>
+        <Input
+          id="email"
+          name="email"
+          type="email"
+          autoComplete="email"
+          required
+          className="mt-1 block w-full"
+          value={email}
+          onChange={(e) => setEmail(e.target.value)}
+        />
+      </div>
+      {error && <p className="text-sm text-red-600">{error.message}</p>}
+      <Button type="submit" className="w-full" disabled={loading}>
+        {loading ? 'Sending...' : 'Send reset link'}
+      </Button>
+    </form>
+  )
+}
+
+// ChooseNewPassword completes a reset with the token from the emailed link
+function ChooseNewPassword({ token }: { token: string }) {
+  const [password, setPassword] = useState('')
+  const [confirmation, setConfirmation] = useState('')
+  const [mismatch, setMismatch] = useState(false)
+  const [done, setDone] = useState(false)
+  const [resetPassword, { loading, error }] = useMutation(RESET_PASSWORD)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    if (password !== confirmation) {
+      setMismatch(true)
+      return
+    }
+    setMismatch(false)
+    try {
+      await resetPassword({ variables: { token, newPassword: password } })
+      setDone(true)
+    } catch (err) {
+      console.error('Error resetting password:', err)
+    }
+  }
+
+  if (done) {
+    return (
+      <div className="text-center space-y-4">
+        <CheckCircle className="mx-auto h-12 w-12 text-green-600" />
+        <h2 className="text-2xl font-bold text-gray-900">Password changed</h2>
+        <p className="text-sm text-gray-600">You've been signed out everywhere. Sign in with your new password.</p>
+        <Button asChild>
+          <Link href="/auth">Sign in</Link>
+        </Button>
+      </div>
+    )
+  }
+
+  return (
+    <form onSubmit={handleSubmit} className="space-y-6">
+      <h2 className="text-center text-3xl font-bold text-gray-900">Choose a new password</h2>
+      <div className="space-y-4">
+        <div>
+          <Label htmlFor="new-password">New password</Label>
+          <Input
+            id="new-password"
+            name="newPassword"
+            type="password"
+            autoComplete="new-password"
+            minLength={8}
+            required
+            className="mt-1 block w-full"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+          />
+        </div>
+        <div>
+          <Label htmlFor="confirm-password">Confirm new password</Label>
+          <Input
+            id="confirm-password"
+            name="confirmPassword"
+            type="password"
+            autoComplete="new-password"
+            required
+            className="mt-1 block w-full"
+            value={confirmation}
+            onChange={(e) => setConfirmation(e.target.value)}
+          />
+        </div>
+      </div>
+      {mismatch && <p className="text-sm text-red-600">The passwords don't match.</p>}
+      {error && <p className="text-sm text-red-600">{error.message}</p>}
+      <Button type="submit" className="w-full" disabled={loading}>
+        {loading ? 'Saving...' : 'Reset password'}
+      </Button>
+    </form>
+  )
+}
+
+function ResetPassword() {
+  const token = useSearchParams().get('token')
+  return token ? <ChooseNewPassword token={token} /> : <RequestReset />
+}
+
+export default function ResetPasswordPage() {
+  return (
+    <div className="min-h-screen bg-gray-100 flex items-center justify-center py-12 px-4 sm:px-6 lg:px-8">
+      <div className="max-w-md w-full bg-white p-10 rounded-xl shadow-md">
+        <Suspense fallback={<Loader2 className="mx-auto h-12 w-12 text-purple-600 animate-spin" />}>
+          <ResetPassword />
+        </Suspense>
+        <p className="mt-6 text-center text-sm text-gray-600">
+          <Link href="/auth" className="font-medium text-purple-600 hover:text-purple-500">
+            Back to sign in
+          </Link>
+        </p>
+      </div>
+    </div>
+  )
+}
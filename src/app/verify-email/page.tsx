@@ -0,0 +1,78 @@
+'use client'
+
+import { Suspense, useEffect, useRef, useState } from 'react'
+import Link from 'next/link'
+import { useSearchParams } from 'next/navigation'
+import { useMutation } from '@apollo/client'
+import { CheckCircle, Loader2, XCircle } from 'lucide-react'
+import { Button } from "@/components/ui/button"
+import { VERIFY_EMAIL } from '@/graphql/queries'
+
+type Status = 'verifying' | 'verified' | 'failed'
+
+function VerifyEmail() {
+  const searchParams = useSearchParams()
+  const token = searchParams.get('token')
+  const [verifyEmail] = useMutation(VERIFY_EMAIL)
+  const [status, setStatus] = useState<Status>(token ? 'verifying' : 'failed')
+  const [error, setError] = useState<string | null>(token ? null : 'This verification link is missing its token.')
+  // Verification links are single use, so only send the token once even when
+  // the effect runs twice in development
+  const sent = useRef(false)
+
+  useEffect(() => {
+    if (!token || sent.current) return
+    sent.current = true
+
+    verifyEmail({ variables: { token } })
+      .then(() => setStatus('verified'))
+      .catch((err) => {
+        setError(err.message)
+        setStatus('failed')
+      })
+  }, [token, verifyEmail])
+
+  return (
+    <div className="text-center space-y-6">
+      {status === 'verifying' && (
+        <>
+          <Loader2 className="mx-auto h-12 w-12 text-purple-600 animate-spin" />
+          <h2 className="text-2xl font-bold text-gray-900">Verifying your email...</h2>
+        </>
+      )}
+      {status === 'verified' && (
+        <>
+          <CheckCircle className="mx-auto h-12 w-12 text-green-600" />
+          <h2 className="text-2xl font-bold text-gray-900">Email verified</h2>
+          <p className="text-sm text-gray-600">Thanks! Your email address has been confirmed.</p>
+          <Button asChild>
+            <Link href="/dashboard">Go to dashboard</Link>
+          </Button>
+        </>
+      )}
+      {status === 'failed' && (
+        <>
+          <XCircle className="mx-auto h-12 w-12 text-red-600" />
+          <h2 className="text-2xl font-bold text-gray-900">We couldn't verify your email</h2>
+          <p className="text-sm text-gray-600">{error}</p>
+          <p className="text-sm text-gray-600">The link may have expired or already been used.</p>
+          <Button asChild variant="outline">
+            <Link href="/auth">Back to sign in</Link>
+          </Button>
+        </>
+      )}
+    </div>
+  )
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <div className="min-h-screen bg-gray-100 flex items-center justify-center py-12 px-4 sm:px-6 lg:px-8">
+      <div className="max-w-md w-full bg-white p-10 rounded-xl shadow-md">
+        <Suspense fallback={<Loader2 className="mx-auto h-12 w-12 text-purple-600 animate-spin" />}>
+          <VerifyEmail />
+        </Suspense>
+      </div>
+    </div>
+  )
+}
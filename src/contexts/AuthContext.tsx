@@ -1,7 +1,7 @@
 import React, { createContext, useContext, useState, useEffect } from 'react';
 import { ApolloClient, ApolloError } from '@apollo/client';
 import { useRouter } from 'next/navigation';
-import { LOGIN_USER, LOGOUT_USER } from '@/graphql/queries';
+import { LOGIN_USER, LOGOUT_USER, VERIFY_TWO_FACTOR_LOGIN } from '@/graphql/queries';
 
 interface User {
   id: string;
@@ -11,7 +11,9 @@ interface User {
 
 interface AuthContextType {
   user: User | null;
-  login: (email: string, password: string) => Promise<void>;
+  // Resolves to a challenge token when the account needs a two-factor code
+  login: (email: string, password: string) => Promise<string | null>;
+  verifyTwoFactor: (challengeToken: string, code: string) => Promise<void>;
   logout: () => Promise<void>;
   isAuthenticated: boolean;
 }
@@ -37,6 +39,15 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children, apolloClie
     }
   }, []);
 
+  const completeLogin = ({ token, refreshToken, user }) => {
+    setUser(user);
+    setIsAuthenticated(true);
+    localStorage.setItem('user', JSON.stringify(user));
+    localStorage.setItem('token', token);
+    localStorage.setItem('refreshToken', refreshToken);
+    router.push('/dashboard');
+  };
+
   const login = async (email: string, password: string) => {
     try {
       const { data } = await apolloClient.mutate({
@@ -45,19 +56,34 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children, apolloClie
       });
 
       if (data && data.loginUser) {
-        const { token, user } = data.loginUser;
-        setUser(user);
-        setIsAuthenticated(true);
-        localStorage.setItem('user', JSON.stringify(user));
-        localStorage.setItem('token', token);
-        router.push('/dashboard');
+        if (data.loginUser.__typename === 'TwoFactorChallenge') {
+          return data.loginUser.challengeToken as string;
+        }
+        completeLogin(data.loginUser);
       }
+      return null;
     } catch (error) {
       console.error('Login error:', error);
       throw error;
     }
   };
 
+  const verifyTwoFactor = async (challengeToken: string, code: string) => {
+    try {
+      const { data } = await apolloClient.mutate({
+        mutation: VERIFY_TWO_FACTOR_LOGIN,
+        variables: { challengeToken, code },
+      });
+
+      if (data && data.verifyTwoFactorLogin) {
+        completeLogin(data.verifyTwoFactorLogin);
+      }
+    } catch (error) {
+      console.error('Two-factor verification error:', error);
+      throw error;
+    }
+  };
+
   const logout = async () => {
     try {
       await apolloClient.mutate({
@@ -67,6 +93,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children, apolloClie
       setIsAuthenticated(false);
       localStorage.removeItem('user');
       localStorage.removeItem('token');
+      localStorage.removeItem('refreshToken');
       await apolloClient.resetStore();
       router.push('/auth');
     } catch (error) {
@@ -76,7 +103,7 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children, apolloClie
   };
 
   return (
-    <AuthContext.Provider value={{ user, login, logout, isAuthenticated }}>
+    <AuthContext.Provider value={{ user, login, verifyTwoFactor, logout, isAuthenticated }}>
       {children}
     </AuthContext.Provider>
   );
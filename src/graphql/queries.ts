@@ -51,17 +51,22 @@ export const GET_PROJECTS = gql`
 `;
 
 export const SEARCH_PROJECTS = gql`
-  query SearchProjects($query: String!) {
-    searchProjects(query: $query) {
-      id
-      title
-      description
-      category
-      status
-      technologies
-      openPositions
-      timeCommitment
-      popularity
+  query SearchProjects($query: String!, $limit: Int, $offset: Int) {
+    searchProjects(query: $query, limit: $limit, offset: $offset) {
+      project {
+        id
+        title
+        description
+        category
+        status
+        technologies
+        openPositions
+        timeCommitment
+        popularity
+      }
+      score
+      titleHighlight
+      snippet
     }
   }
 `;
@@ -311,6 +316,15 @@ export const CREATE_USER = gql`
   }
 `;
 
+export const VERIFY_EMAIL = gql`
+  mutation VerifyEmail($token: String!) {
+    verifyEmail(token: $token) {
+      id
+      emailVerified
+    }
+  }
+`;
+
 export const CHANGE_PASSWORD = gql`
   mutation ChangePassword($id: ID!, $oldPassword: String!, $newPassword: String!) {
     changePassword(id: $id, oldPassword: $oldPassword, newPassword: $newPassword) {
@@ -320,6 +334,18 @@ export const CHANGE_PASSWORD = gql`
   }
 `;
 
+export const REQUEST_PASSWORD_RESET = gql`
+  mutation RequestPasswordReset($email: String!) {
+    requestPasswordReset(email: $email)
+  }
+`;
+
+export const RESET_PASSWORD = gql`
+  mutation ResetPassword($token: String!, $newPassword: String!) {
+    resetPassword(token: $token, newPassword: $newPassword)
+  }
+`;
+
 export const CREATE_TASK = gql`
   mutation CreateTask($input: CreateTaskInput!) {
     createTask(input: $input) {
@@ -430,7 +456,29 @@ export const DELETE_TEAM = gql`
 export const LOGIN_USER = gql`
   mutation LoginUser($email: String!, $password: String!) {
     loginUser(email: $email, password: $password) {
+      __typename
+      ... on AuthPayload {
+        token
+        refreshToken
+        user {
+          id
+          username
+          email
+        }
+      }
+      ... on TwoFactorChallenge {
+        challengeToken
+        expiresAt
+      }
+    }
+  }
+`;
+
+export const VERIFY_TWO_FACTOR_LOGIN = gql`
+  mutation VerifyTwoFactorLogin($challengeToken: String!, $code: String!) {
+    verifyTwoFactorLogin(challengeToken: $challengeToken, code: $code) {
       token
+      refreshToken
       user {
         id
         username
@@ -440,6 +488,15 @@ export const LOGIN_USER = gql`
   }
 `;
 
+export const REFRESH_TOKEN = gql`
+  mutation RefreshToken($refreshToken: String!) {
+    refreshToken(refreshToken: $refreshToken) {
+      token
+      refreshToken
+    }
+  }
+`;
+
 export const LOGOUT_USER = gql`
   mutation LogoutUser {
     logoutUser
@@ -1,9 +1,57 @@
-import { ApolloClient, InMemoryCache, createHttpLink, NormalizedCacheObject } from '@apollo/client';
+import { ApolloClient, InMemoryCache, createHttpLink, NormalizedCacheObject, fromPromise } from '@apollo/client';
 import { setContext } from '@apollo/client/link/context';
+import { onError } from '@apollo/client/link/error';
+import { print } from 'graphql';
+import { REFRESH_TOKEN } from '@/graphql/queries';
+
+const GRAPHQL_URI = 'http://localhost:8080/query'; // Your GraphQL endpoint
+
+// Exchanges the stored refresh token for a new token pair. Refresh tokens are
+// single use, so concurrent callers share one in-flight request.
+let refreshPromise: Promise<string | null> | null = null;
+
+function refreshAccessToken(): Promise<string | null> {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (!refreshToken) return Promise.resolve(null);
+
+  if (!refreshPromise) {
+    refreshPromise = fetch(GRAPHQL_URI, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ query: print(REFRESH_TOKEN), variables: { refreshToken } }),
+    })
+      .then((res) => res.json())
+      .then(({ data }) => {
+        if (!data?.refreshToken) {
+          localStorage.removeItem('token');
+          localStorage.removeItem('refreshToken');
+          return null;
+        }
+        localStorage.setItem('token', data.refreshToken.token);
+        localStorage.setItem('refreshToken', data.refreshToken.refreshToken);
+        return data.refreshToken.token as string;
+      })
+      .catch(() => null)
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+}
 
 export function createApolloClient(initialState: NormalizedCacheObject = {}): ApolloClient<NormalizedCacheObject> {
   const httpLink = createHttpLink({
-    uri: 'http://localhost:8080/query', // Your GraphQL endpoint
+    uri: GRAPHQL_URI,
+  });
+
+  // Access tokens are short-lived; on a 401 refresh once and replay the operation
+  const refreshLink = onError(({ networkError, operation, forward }) => {
+    if (typeof window === 'undefined') return;
+    if (networkError && 'statusCode' in networkError && networkError.statusCode === 401) {
+      return fromPromise(refreshAccessToken())
+        .filter((token) => Boolean(token))
+        .flatMap(() => forward(operation));
+    }
   });
 
   const authLink = setContext((_, { headers }) => {
@@ -20,8 +68,12 @@ export function createApolloClient(initialState: NormalizedCacheObject = {}): Ap
 
   return new ApolloClient({
     ssrMode: typeof window === 'undefined', // True if running on the server
-    link: authLink.concat(httpLink),
-    cache: new InMemoryCache().restore(initialState)
+    link: refreshLink.concat(authLink).concat(httpLink),
+    cache: new InMemoryCache({
+      possibleTypes: {
+        LoginResult: ['AuthPayload', 'TwoFactorChallenge'],
+      },
+    }).restore(initialState)
   });
 }
 